@@ -11,6 +11,9 @@ const (
 	HorizontalAlignLeft   HorizontalAlign = 0
 	HorizontalAlignCenter HorizontalAlign = 1
 	HorizontalAlignRight  HorizontalAlign = 2
+
+	// HorizontalAlignJustify stretches the spaces of lines to fill the width of a box, see also TextAlignLast
+	HorizontalAlignJustify HorizontalAlign = 3
 )
 
 type VerticalAlign int
@@ -27,10 +27,16 @@ type Builder struct {
 	// number of worker routines used to generate content streams of pages
 	WorkerRoutines int
 
+	// draw all text as glyph outlines, so that the document contains no fonts, see TextChunk.Outlines. Fonts only
+	// drawn as outlines are not written to the document. Text in standard fonts, Type 1, Type 3 and color fonts cannot
+	// be drawn as outlines and causes an error.
+	TextOutlines bool
+
 	// internals
-	file     *pdf.File
-	pages    []*Page
-	currPage *Page
+	file         *pdf.File
+	pages        []*Page
+	currPage     *Page
+	fontRegistry *FontRegistry
 }
 
 // New creates a new Builder object
@@ -69,7 +75,14 @@ func (q *Builder) WriteTo(w io.Writer) (int64, error) {
 	// create pages
 	pdfPages := make([]*pdf.Page, 0, len(q.pages))
 	for _, p := range q.pages {
-		pdfPages = append(pdfPages, q.file.NewPage(p.Width.Pt(), p.Height.Pt()))
+		if p.imported != nil {
+			q.file.AddPage(p.imported)
+			pdfPages = append(pdfPages, p.imported)
+			continue
+		}
+		pdfPage := q.file.NewPage(p.Width.Pt(), p.Height.Pt())
+		pdfPage.TextOutlines = q.TextOutlines
+		pdfPages = append(pdfPages, pdfPage)
 	}
 
 	// determine number of workers
@@ -152,9 +165,11 @@ func (q *Builder) NewPageBefore(size PageSize, beforePageNo int) *Page {
 	return q.currPage
 }
 
-// NewFormFromPage creates a new form object from the give page
+// NewFormFromPage creates a new form object from the give page. Forms cannot contain links, elements with a link cause
+// an error.
 func (q *Builder) NewFormFromPage(page *Page) (*Form, error) {
 	p := pdf.NewPage(page.Width.Pt(), page.Height.Pt())
+	p.TextOutlines = q.TextOutlines
 	for _, item := range page.elements {
 		if _, err := item.Build(p); err != nil {
 			return nil, err
@@ -213,31 +228,122 @@ func (q *Builder) NewCapturedPage(sourcePage types.Page, sourceFile *pdffile.Fil
 	}, nil
 }
 
+// ImportPages adds pages of another pdf (first page = 1) including annotations, links, form fields and outline
+// entries. Destinations between the imported pages keep working. If no page numbers are given, all pages are imported.
+func (q *Builder) ImportPages(sourceFile *pdffile.File, pageNos ...int) ([]*Page, error) {
+	pp, err := q.file.ImportPages(sourceFile, pageNos...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Page, 0, len(pp))
+	for _, p := range pp {
+		box := p.Data.MediaBox
+		q.currPage = &Page{
+			Width:    Pt(float64(box.URX - box.LLX)),
+			Height:   Pt(float64(box.URY - box.LLY)),
+			Rotate:   int(p.Data.Rotate),
+			imported: p,
+		}
+		q.pages = append(q.pages, q.currPage)
+		res = append(res, q.currPage)
+	}
+	return res, nil
+}
+
 // NewStandardFont adds a new standard font (expected to be available in all PDF consuming systems) to the pdf
 func (q *Builder) NewStandardFont(name types.StandardFontName, encoding types.Encoding) (*pdf.StandardFont, error) {
 	return q.file.NewStandardFont(name, encoding)
 }
 
-// NewTrueTypeFont adds a new TrueType font to the pdf
+// NewStandardFontWithEncoding adds a new standard font with a predefined or custom encoding, e.g. to use glyphs of
+// Symbol and ZapfDingbats by Unicode characters
+func (q *Builder) NewStandardFontWithEncoding(name types.StandardFontName, encoding types.SimpleEncoding) (*pdf.StandardFont, error) {
+	return q.file.NewStandardFontWithEncoding(name, encoding)
+}
+
+// NewType1Font adds a new PostScript Type 1 font given as font program (pfb or pfa) and font metrics (afm) to the pdf.
+// If encoding is nil, the built-in encoding of the font is used.
+func (q *Builder) NewType1Font(program, afm []byte, encoding types.SimpleEncoding) (*pdf.Type1Font, error) {
+	return q.file.NewType1Font(program, afm, encoding)
+}
+
+// NewTrueTypeFont adds a new TrueType font (ttf, ttc or woff) to the pdf. If embed is true, a subset of the font with
+// the characters used is embedded. Of font collections (ttc), the first font is used, see
+// NewTrueTypeFontFromCollection to select another one.
 func (q *Builder) NewTrueTypeFont(ttf []byte, encoding types.Encoding, embed bool) (*pdf.TrueTypeFont, error) {
 	return q.file.NewTrueTypeFont(ttf, encoding, embed)
 }
 
-// NewCompositeFont adds a font as composite font to the pdf, i.e. with Unicode support
+// NewTrueTypeFontWithEncoding adds a new TrueType font with a predefined or custom encoding to the pdf
+func (q *Builder) NewTrueTypeFontWithEncoding(ttf []byte, encoding types.SimpleEncoding, embed bool) (*pdf.TrueTypeFont, error) {
+	return q.file.NewTrueTypeFontWithEncoding(ttf, encoding, embed)
+}
+
+// NewTrueTypeFontFromCollection adds the font with the given index of a font collection (ttc) as TrueType font to
+// the pdf, see unitype.ParseFaces and unitype.CollectionFontIndex to find the index of a font
+func (q *Builder) NewTrueTypeFontFromCollection(ttc []byte, index int, encoding types.SimpleEncoding, embed bool) (*pdf.TrueTypeFont, error) {
+	return q.file.NewTrueTypeFontFromCollection(ttc, index, encoding, embed)
+}
+
+// NewCompositeFont adds a font (ttf, ttc or woff) as composite font to the pdf, i.e. with Unicode support. Of font
+// collections (ttc), the first font is used, see NewCompositeFontFromCollection to select another one.
 func (q *Builder) NewCompositeFont(ttf []byte) (*pdf.CompositeFont, error) {
 	return q.file.NewCompositeFontFromTTF(ttf, nil)
 }
 
+// NewCompositeFontFromCollection adds the font with the given index of a font collection (ttc) as composite font to
+// the pdf, see unitype.ParseFaces and unitype.CollectionFontIndex to find the index of a font
+func (q *Builder) NewCompositeFontFromCollection(ttc []byte, index int) (*pdf.CompositeFont, error) {
+	return q.file.NewCompositeFontFromCollection(ttc, index, nil)
+}
+
 // NewCompositeFontWithFallback adds a font as composite font to the pdf, i.e. with Unicode support
 func (q *Builder) NewCompositeFontWithFallback(ttf []byte, fallback pdf.FontHandler) (*pdf.CompositeFont, error) {
 	return q.file.NewCompositeFontFromTTF(ttf, fallback)
 }
 
-// NewCompositeFontFromOTF adds an otf font as composite font to the pdf, i.e. with Unicode support
+// NewCompositeFontWithFallbacks adds a font as composite font to the pdf with an ordered chain of fallback fonts: for
+// every character the first font of the chain providing a glyph is used
+func (q *Builder) NewCompositeFontWithFallbacks(ttf []byte, fallbacks ...pdf.FontHandler) (*pdf.CompositeFont, error) {
+	f, err := q.file.NewCompositeFontFromTTF(ttf, nil)
+	if err != nil {
+		return nil, err
+	}
+	f.SetFallbackFonts(fallbacks...)
+	return f, nil
+}
+
+// NewCompositeFontFromVariable adds an instance of a variable font as composite font to the pdf. coords contains the
+// values of the design axes by tag, e.g. {"wght": 700, "wdth": 75}, axes not given keep their default value.
+func (q *Builder) NewCompositeFontFromVariable(ttf []byte, coords map[string]float64) (*pdf.CompositeFont, error) {
+	return q.file.NewCompositeFontFromVariableTTF(ttf, coords, nil)
+}
+
+// NewCompositeFontFromOTF adds an otf font as composite font to the pdf, i.e. with Unicode support. Of font
+// collections (otc), the first font is used, see NewCompositeFontFromOTFCollection to select another one.
 func (q *Builder) NewCompositeFontFromOTF(otf []byte) (*pdf.CompositeFontOTF, error) {
 	return q.file.NewCompositeFontFromOTF(otf)
 }
 
+// NewCompositeFontFromOTFCollection adds the font with the given index of a font collection (otc) as composite font
+// to the pdf, see unitype.ParseFaces and unitype.CollectionFontIndex to find the index of a font
+func (q *Builder) NewCompositeFontFromOTFCollection(otc []byte, index int) (*pdf.CompositeFontOTF, error) {
+	return q.file.NewCompositeFontFromOTFCollection(otc, index)
+}
+
+// NewColorFont adds a font with color glyphs, e.g. an emoji font, as Type 3 font to the pdf. Use it as fallback font
+// of a composite font, see NewCompositeFontWithFallbacks.
+func (q *Builder) NewColorFont(ttf []byte) (*pdf.ColorFont, error) {
+	return q.file.NewColorFont(ttf)
+}
+
+// NewType3Font adds a Type 3 font to the pdf whose glyphs are drawn by path and image operators or given as SVG path
+// data, e.g. for icons or pictograms. ascent and descent (negative) are given in 1000 units per em.
+func (q *Builder) NewType3Font(ascent, descent float64) *pdf.Type3Font {
+	return q.file.NewType3Font(ascent, descent)
+}
+
 // AddAssociatedFile adds an associated file to the document catalog
 func (q *Builder) AddAssociatedFile(data []byte, relationship types.Name, desc, uf, f, mimeType string) error {
 	_, err := q.file.AddAssociatedFile(data, relationship, desc, uf, f, mimeType)
@@ -0,0 +1,55 @@
+package gopdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/raceresult/gopdf/parser"
+	"github.com/raceresult/gopdf/types"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestBuilderTextOutlines(t *testing.T) {
+	build := func(outlines bool) []byte {
+		b := New()
+		b.TextOutlines = outlines
+		font, err := b.NewCompositeFont(goregular.TTF)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.NewPage(GetStandardPageSize(PageSizeA4, false))
+		b.AddElement(&TextElement{TextChunk: TextChunk{Text: "Outlines", Font: font, FontSize: 12}})
+		bts, err := b.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parser.New(bts); err != nil {
+			t.Fatal(err)
+		}
+		return bts
+	}
+
+	for _, s := range []string{"/FontDescriptor", "/FontFile2", "/Type0", "/CIDSystemInfo"} {
+		if !bytes.Contains(build(false), []byte(s)) {
+			t.Errorf("text: %s missing", s)
+		}
+		if bytes.Contains(build(true), []byte(s)) {
+			t.Errorf("outlines: %s written although no text is shown by the font", s)
+		}
+	}
+}
+
+func TestBuilderTextOutlinesStandardFont(t *testing.T) {
+	b := New()
+	b.TextOutlines = true
+	font, err := b.NewStandardFont(types.StandardFont_Helvetica, types.EncodingWinAnsi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.NewPage(GetStandardPageSize(PageSizeA4, false))
+	b.AddElement(&TextElement{TextChunk: TextChunk{Text: "Outlines", Font: font, FontSize: 12}})
+	if _, err := b.Build(); err == nil || !strings.Contains(err.Error(), "glyph outlines") {
+		t.Errorf("got error %v, want error about glyph outlines", err)
+	}
+}
@@ -6,3 +6,11 @@ import "github.com/raceresult/gopdf/pdf"
 type Element interface {
 	Build(page *pdf.Page) (string, error)
 }
+
+// joinWarnings appends a warning to the warnings of an element, one per line
+func joinWarnings(warning, warning2 string) string {
+	if warning == "" || warning2 == "" {
+		return warning + warning2
+	}
+	return warning + "\n" + warning2
+}
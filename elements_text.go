@@ -71,9 +71,7 @@ func (q *TextElement) Build(page *pdf.Page) (string, error) {
 		if err != nil {
 			return "", err
 		}
-		if warning2 != "" {
-			warning += warning2
-		}
+		warning = joinWarnings(warning, warning2)
 
 		top -= lineHeight
 	}
@@ -89,7 +87,7 @@ func (q *TextElement) TextHeight() Length {
 
 // FontHeight returns the height of the font (bounding box y min to max)
 func (q *TextElement) FontHeight() Length {
-	return Pt(q.Font.GetHeight(q.FontSize))
+	return Pt(q.Font.GetHeight(q.fontSize()))
 }
 
 // getLineWidth returns the width of the given text line consider font, fontsize, text-scaling, char spacing
@@ -97,7 +95,7 @@ func (q *TextElement) getLineWidth(line string) float64 {
 	if line == "" {
 		return 0
 	}
-	v := q.Font.GetWidth(line, q.FontSize)
+	v := q.Font.GetWidth(line, q.fontSize())
 	if q.CharSpacing.Value != 0 {
 		v += float64(len([]rune(line))-1) * q.CharSpacing.Pt()
 	}
@@ -10,6 +10,12 @@ type TextBoxElement struct {
 	Width, Height   Length
 	VerticalAlign   VerticalAlign
 	HeightBufferRel float64
+
+	// alignment of the last line of paragraphs if TextAlign is HorizontalAlignJustify
+	TextAlignLast HorizontalAlign
+
+	// vertical writing, see TextChunkBoxElement.Vertical
+	Vertical bool
 }
 
 // Build adds the element to the content stream
@@ -20,21 +26,7 @@ func (q *TextBoxElement) Build(page *pdf.Page) (string, error) {
 // toChunkBox converts the element to a TextChunkBoxElement
 func (q *TextBoxElement) toChunkBox() *TextChunkBoxElement {
 	return &TextChunkBoxElement{
-		Chunks: []TextChunk{{
-			Text:          q.Text,
-			Font:          q.Font,
-			FontSize:      q.FontSize,
-			Color:         q.Color,
-			OutlineColor:  q.OutlineColor,
-			OutlineWidth:  q.OutlineWidth,
-			DashPattern:   q.DashPattern,
-			Bold:          q.Bold,
-			Italic:        q.Italic,
-			Underline:     q.Underline,
-			StrikeThrough: q.StrikeThrough,
-			CharSpacing:   q.CharSpacing,
-			TextScaling:   q.TextScaling,
-		}},
+		Chunks:          []TextChunk{q.TextChunk},
 		Transparency:    q.Transparency,
 		LineHeight:      q.LineHeight,
 		Left:            q.Left,
@@ -45,6 +37,8 @@ func (q *TextBoxElement) toChunkBox() *TextChunkBoxElement {
 		TextAlign:       q.TextAlign,
 		VerticalAlign:   q.VerticalAlign,
 		HeightBufferRel: q.HeightBufferRel,
+		TextAlignLast:   q.TextAlignLast,
+		Vertical:        q.Vertical,
 	}
 }
 
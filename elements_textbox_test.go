@@ -0,0 +1,98 @@
+package gopdf
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/raceresult/gopdf/types"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// buildUncompressed adds the elements to a new page and returns the document with uncompressed content streams
+func buildUncompressed(t *testing.T, b *Builder, elements ...Element) string {
+	t.Helper()
+	b.CompressStreamsThreshold = math.MaxInt32
+	b.NewPage(GetStandardPageSize(PageSizeA4, false))
+	b.AddElement(elements...)
+	bts, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(bts)
+}
+
+// newTextBox returns a text box with the text in Helvetica
+func newTextBox(t *testing.T, b *Builder, text string) *TextBoxElement {
+	t.Helper()
+	font, err := b.NewStandardFont(types.StandardFont_Helvetica, types.EncodingWinAnsi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &TextBoxElement{
+		TextElement: TextElement{
+			TextChunk: TextChunk{Text: text, Font: font, FontSize: 12},
+			Left:      Pt(30),
+			Top:       Pt(30),
+		},
+		Width:  Pt(300),
+		Height: Pt(60),
+	}
+}
+
+func TestTextBoxScript(t *testing.T) {
+	rise := regexp.MustCompile(`(^|\n)[1-9][0-9.]* Ts\n`)
+
+	b := New()
+	box := newTextBox(t, b, "x2")
+	if rise.MatchString(buildUncompressed(t, b, box)) {
+		t.Error("text rise set for normal text")
+	}
+
+	b = New()
+	box = newTextBox(t, b, "x2")
+	box.Script = TextScriptSuperscript
+	if !rise.MatchString(buildUncompressed(t, b, box)) {
+		t.Error("no text rise for superscript")
+	}
+
+	b = New()
+	box = newTextBox(t, b, "x2")
+	box.BaselineShift = Pt(3)
+	if !regexp.MustCompile(`\n3 Ts\n`).MatchString(buildUncompressed(t, b, box)) {
+		t.Error("no text rise for baseline shift")
+	}
+}
+
+func TestTextBoxOutlines(t *testing.T) {
+	b := New()
+	font, err := b.NewCompositeFont(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box := newTextBox(t, b, "Outlines")
+	box.Font = font
+	box.Outlines = true
+	s := buildUncompressed(t, b, box)
+	for _, op := range []string{" Tf\n", " Tj\n", " TJ\n"} {
+		if strings.Contains(s, op) {
+			t.Errorf("text shown by font: %q found", op)
+		}
+	}
+	for _, op := range []string{" m\n", " c\n", "\nf\n"} {
+		if !strings.Contains(s, op) {
+			t.Errorf("no glyph outlines: %q missing", op)
+		}
+	}
+}
+
+func TestTextBoxLink(t *testing.T) {
+	b := New()
+	box := newTextBox(t, b, "race result")
+	box.Link = "https://www.raceresult.com"
+	s := buildUncompressed(t, b, box)
+	if !strings.Contains(s, "/Link") || !strings.Contains(s, "(https://www.raceresult.com)") {
+		t.Error("no link annotation")
+	}
+}
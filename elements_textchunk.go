@@ -2,8 +2,12 @@ package gopdf
 
 import (
 	"errors"
+	"strings"
+	"unicode"
+
 	"github.com/raceresult/gopdf/pdf"
 	"github.com/raceresult/gopdf/pdftext"
+	"github.com/raceresult/gopdf/pdftext/hyphenation"
 	"github.com/raceresult/gopdf/types"
 )
 
@@ -21,6 +25,69 @@ type TextChunk struct {
 	StrikeThrough bool
 	CharSpacing   Length
 	TextScaling   float64
+
+	// offset of the baseline, positive values raise the text (move it to the right in vertical writing)
+	BaselineShift Length
+
+	// superscript or subscript: the font size is reduced and the baseline shifted in addition to BaselineShift
+	Script TextScript
+
+	// language of the text, e.g. "de" or "en-US": enables hyphenation when wrapping lines if patterns for the
+	// language are available (en, de, nl, fr, es)
+	Language string
+
+	// minimum number of characters before and after a hyphen, 0 for the default of the language
+	HyphenMinLeft, HyphenMinRight int
+
+	// draw the glyphs as filled paths instead of text, so that no font is needed, e.g. for print-ready logos. The
+	// font must provide glyph outlines, see pdf.HasOutlines: standard fonts, Type 1, Type 3 and color fonts cause an
+	// error when the text is drawn.
+	Outlines bool
+
+	// URI opened when the text is clicked, e.g. "https://www.raceresult.com"
+	Link string
+
+	// text is already shaped and in visual order
+	visual bool
+
+	// additional width of spaces in justified text
+	wordSpacing float64
+}
+
+// TextScript is the position of text relative to the baseline
+type TextScript int
+
+const (
+	TextScriptNormal      TextScript = 0
+	TextScriptSuperscript TextScript = 1
+	TextScriptSubscript   TextScript = 2
+)
+
+// relative font size and baseline shift of superscript and subscript text
+const (
+	scriptScale      = 0.58
+	superscriptShift = 0.33
+	subscriptShift   = -0.14
+)
+
+// fontSize returns the font size of the text, reduced for superscript and subscript
+func (q *TextChunk) fontSize() float64 {
+	if q.Script == TextScriptSuperscript || q.Script == TextScriptSubscript {
+		return q.FontSize * scriptScale
+	}
+	return q.FontSize
+}
+
+// rise returns the shift of the baseline by BaselineShift and Script
+func (q *TextChunk) rise() float64 {
+	v := q.BaselineShift.Pt()
+	switch q.Script {
+	case TextScriptSuperscript:
+		v += q.FontSize * superscriptShift
+	case TextScriptSubscript:
+		v += q.FontSize * subscriptShift
+	}
+	return v
 }
 
 // getLineWidth returns the width of the given text line consider font, fontsize, text-scaling, char spacing
@@ -30,30 +97,13 @@ func (q *TextChunk) getLineWidth(line string) float64 {
 	}
 
 	var v float64
-	if fb := q.Font.FallbackFont(); fb != nil {
-		rr := []rune(line)
-		hasGlyph := q.Font.HasGylph(rr)
-		curr := hasGlyph[0]
-		start := 0
-		for i := range hasGlyph {
-			if i < len(hasGlyph)-1 && hasGlyph[i+1] == curr {
-				continue
-			}
-
-			var w float64
-			part := string(rr[start : i+1])
-			if !curr {
-				w = fb.GetWidth(part, q.FontSize)
-			} else {
-				w = q.Font.GetWidth(part, q.FontSize)
-			}
-			v += w
-			curr = !curr
-			start = i + 1
+	if chain := q.fonts(); len(chain) > 1 {
+		runs, _ := chain.runs(line)
+		for _, run := range runs {
+			v += run.font.GetWidth(run.text, q.fontSize())
 		}
-
 	} else {
-		v = q.Font.GetWidth(line, q.FontSize)
+		v = q.Font.GetWidth(line, q.fontSize())
 	}
 
 	if q.CharSpacing.Value != 0 {
@@ -62,12 +112,34 @@ func (q *TextChunk) getLineWidth(line string) float64 {
 	if q.TextScaling != 0 {
 		v *= q.TextScaling / 100
 	}
+	if q.wordSpacing != 0 {
+		v += float64(strings.Count(line, " ")) * q.wordSpacing
+	}
 	return v
 }
 
+// hyphenator returns the hyphenator for the language of the chunk or nil if there is none
+func (q *TextChunk) hyphenator() *hyphenation.Hyphenator {
+	if q.Language == "" {
+		return nil
+	}
+	h, err := hyphenation.Get(q.Language)
+	if err != nil {
+		return nil
+	}
+	hc := *h
+	if q.HyphenMinLeft > 0 {
+		hc.LeftMin = q.HyphenMinLeft
+	}
+	if q.HyphenMinRight > 0 {
+		hc.RightMin = q.HyphenMinRight
+	}
+	return &hc
+}
+
 // FontHeight returns the height of the font (bounding box y min to max)
 func (q *TextChunk) FontHeight() Length {
-	return Pt(q.Font.GetHeight(q.FontSize))
+	return Pt(q.Font.GetHeight(q.fontSize()))
 }
 
 func (q *TextChunk) setFontAndColor(page *pdf.Page) (string, error) {
@@ -79,7 +151,7 @@ func (q *TextChunk) setFontAndColor(page *pdf.Page) (string, error) {
 	if q.Bold && q.OutlineWidth.Value == 0 && color != nil && q.OutlineColor == nil {
 		color.Build(page, false)
 		color.Build(page, true)
-		page.GraphicsState_w(q.FontSize * 0.05)
+		page.GraphicsState_w(q.fontSize() * 0.05)
 		page.TextState_Tr(types.RenderingModeFillAndStroke)
 	} else {
 		page.GraphicsState_w(q.OutlineWidth.Pt())
@@ -101,19 +173,33 @@ func (q *TextChunk) setFontAndColor(page *pdf.Page) (string, error) {
 		return warning, err
 	}
 
-	// text scaling / char spacing
+	// text scaling / char spacing / word spacing
 	page.TextState_Tc(q.CharSpacing.Pt())
 	if q.TextScaling == 0 {
 		page.TextState_Tz(100)
+		page.TextState_Tw(q.wordSpacing)
 	} else {
 		page.TextState_Tz(q.TextScaling)
+		page.TextState_Tw(q.wordSpacing * 100 / q.TextScaling)
 	}
 
-	// set font
-	page.TextState_Tf(q.Font, q.FontSize)
+	// set font, not needed for text drawn as outlines
+	if !q.outlines(page) {
+		page.TextState_Tf(q.Font, q.fontSize())
+	}
 	return warning, nil
 }
 
+// errNoOutlines is returned if text is drawn as glyph outlines in a font not providing outlines, e.g. a standard font
+func errNoOutlines(text string) error {
+	return errors.New("text \"" + text + "\" cannot be drawn as glyph outlines, the font does not provide outlines")
+}
+
+// outlines checks if the text is drawn as glyph outlines, for the chunk or the entire document
+func (q *TextChunk) outlines(page *pdf.Page) bool {
+	return q.Outlines || page.TextOutlines
+}
+
 func (q *TextChunk) draw(page *pdf.Page, left, top float64) (string, error) {
 	// if no font or text given, ignore chunk
 	if q.Font == nil {
@@ -132,58 +218,350 @@ func (q *TextChunk) draw(page *pdf.Page, left, top float64) (string, error) {
 		c = 0.333
 	}
 
-	// draw text
+	// prepare text for display
+	chain := q.fonts()
+	text := q.Text
+	if !q.visual {
+		text = pdftext.StringModificationsWith(text, chain)
+	}
+
+	// draw text, runs of fallback fonts are drawn on the same baseline
+	page.TextState_Ts(q.rise())
+	runs, missing := chain.runs(text)
+	if missing != "" {
+		warning = joinWarnings(warning, "No glyph for \""+missing+"\" in any font of text \""+q.Text+"\"")
+	}
+	x := left
+	font := q.Font
+	for _, run := range runs {
+		if q.outlines(page) {
+			if !pdf.HasOutlines(run.font) {
+				return warning, errNoOutlines(q.Text)
+			}
+			if err := page.TextOutline(run.font, q.fontSize(), run.text, 1, 0, c, 1, x, top); err != nil {
+				return warning, err
+			}
+			x += q.runWidth(run)
+			continue
+		}
+		if run.font != font {
+			page.TextState_Tf(run.font, q.fontSize())
+			font = run.font
+		}
+		page.TextObjects_BT()
+		page.TextPosition_Tm(1, 0, c, 1, x, top)
+		page.TextShowing_Tj(run.text)
+		page.TextObjects_ET()
+		x += q.runWidth(run)
+	}
+
+	// underline/strike-through text: the underline of superscript and subscript text stays at the position of
+	// regular text, so that it is continuous, but follows text shifted downwards by BaselineShift
+	rise := q.rise()
+	if q.Underline {
+		th := q.Font.GetUnderlineThickness(q.FontSize)
+		pos := q.Font.GetUnderlinePosition(q.FontSize)
+		if shift := q.BaselineShift.Pt(); shift < 0 {
+			pos += shift
+		}
+		page.Path_re(left, top+pos-th, q.getLineWidth(q.Text), th)
+		page.Path_f()
+	}
+	if q.StrikeThrough {
+		page.Path_re(
+			left, top+rise+q.Font.GetTop(q.fontSize())/3,
+			q.getLineWidth(q.Text), q.Font.GetUnderlineThickness(q.fontSize()),
+		)
+		page.Path_f()
+	}
+
+	// link
+	if q.Link != "" {
+		page.AddLink(left, top+rise+q.Font.GetBottom(q.fontSize()), q.getLineWidth(q.Text), q.Font.GetHeight(q.fontSize()), q.Link)
+	}
+
+	return warning, nil
+}
+
+// fallbackFonts is implemented by fonts with a chain of fallback fonts, see pdf.CompositeFont.SetFallbackFonts
+type fallbackFonts interface {
+	FallbackFonts() []pdf.FontHandler
+}
+
+// fontChain is a font followed by its fallback fonts
+type fontChain []pdf.FontHandler
+
+// fontRun is a part of a text drawn with one font
+type fontRun struct {
+	text string
+	font pdf.FontHandler
+}
+
+// fonts returns the font of the chunk followed by its fallback fonts
+func (q *TextChunk) fonts() fontChain {
+	res := fontChain{q.Font}
+	if ff, ok := q.Font.(fallbackFonts); ok {
+		return append(res, ff.FallbackFonts()...)
+	}
 	if fb := q.Font.FallbackFont(); fb != nil {
-		rr := []rune(q.Text)
-		hasGlyph := q.Font.HasGylph(rr)
-		curr := hasGlyph[0]
-		start := 0
-		for i := range hasGlyph {
-			if i < len(hasGlyph)-1 && hasGlyph[i+1] == curr {
-				continue
+		res = append(res, fb)
+	}
+	return res
+}
+
+// runWidth returns the horizontal advance of a run considering char spacing, text scaling and word spacing
+func (q *TextChunk) runWidth(run fontRun) float64 {
+	w := run.font.GetWidth(run.text, q.fontSize()) + float64(len([]rune(run.text)))*q.CharSpacing.Pt()
+	if q.TextScaling != 0 {
+		w *= q.TextScaling / 100
+	}
+	return w + float64(strings.Count(run.text, " "))*q.wordSpacing
+}
+
+// HasGylph checks for every rune if any of the fonts has a glyph for it
+func (q fontChain) HasGylph(runes []rune) []bool {
+	res := make([]bool, len(runes))
+	for _, font := range q {
+		for i, has := range font.HasGylph(runes) {
+			res[i] = res[i] || has
+		}
+	}
+	return res
+}
+
+// clusters splits the text into grapheme clusters, each with the first font having glyphs for all of its characters.
+// Characters common to all scripts like spaces, digits and punctuation stay in the font of the preceding text if it
+// has glyphs for them, so a run of a script is not split at every space. Characters none of the fonts has a glyph
+// for are drawn with the first font and returned as missing.
+func (q fontChain) clusters(text string) ([]fontRun, string) {
+	rr := []rune(text)
+	if len(rr) == 0 {
+		return nil, ""
+	}
+	hasGlyph := make([][]bool, len(q))
+	for i, font := range q {
+		hasGlyph[i] = font.HasGylph(rr)
+	}
+	covered := func(font, start, end int) bool {
+		for i := start; i < end; i++ {
+			if !hasGlyph[font][i] && !isIgnorable(rr[i]) {
+				return false
 			}
+		}
+		return true
+	}
+
+	graphemes := pdftext.GraphemeBoundaries(rr)
+	var res []fontRun
+	var missing []rune
+	current := -1
+	for start := 0; start < len(rr); {
+		end := start + 1
+		for end < len(rr) && !graphemes[end] {
+			end++
+		}
 
-			var w float64
-			part := pdftext.StringModifications(string(rr[start:i+1]), q.Font)
-			if !curr {
-				page.TextState_Tf(fb, q.FontSize)
-				w = fb.GetWidth(part, q.FontSize)
-			} else {
-				if start != 0 {
-					page.TextState_Tf(q.Font, q.FontSize)
+		font := -1
+		if current >= 0 && unicode.In(rr[start], unicode.Common, unicode.Inherited) && covered(current, start, end) {
+			font = current
+		}
+		for i := range q {
+			if font < 0 && covered(i, start, end) {
+				font = i
+			}
+		}
+		if font < 0 {
+			font = 0
+			for i := range q {
+				if hasGlyph[i][start] {
+					font = i
+					break
+				}
+			}
+			for _, r := range missingGlyphs(rr[start:end], hasGlyph[font][start:end]) {
+				if !containsRune(missing, r) {
+					missing = append(missing, r)
 				}
-				w = q.Font.GetWidth(part, q.FontSize)
 			}
-			page.TextObjects_BT()
-			page.TextPosition_Tm(1, 0, c, 1, left, top)
-			page.TextShowing_Tj(part)
-			page.TextObjects_ET()
+		}
+
+		res = append(res, fontRun{text: string(rr[start:end]), font: q[font]})
+		current = font
+		start = end
+	}
+	return res, string(missing)
+}
 
-			left += w
-			curr = !curr
-			start = i + 1
+// runs splits the text into runs of the same font, see clusters
+func (q fontChain) runs(text string) ([]fontRun, string) {
+	if len(q) == 1 {
+		rr := []rune(text)
+		return []fontRun{{text: text, font: q[0]}}, missingGlyphs(rr, q[0].HasGylph(rr))
+	}
+	clusters, missing := q.clusters(text)
+	var res []fontRun
+	for _, cluster := range clusters {
+		if n := len(res) - 1; n >= 0 && res[n].font == cluster.font {
+			res[n].text += cluster.text
+			continue
 		}
+		res = append(res, cluster)
+	}
+	return res, missing
+}
 
-	} else {
+// missingGlyphs returns the characters without glyph, each once
+func missingGlyphs(rr []rune, hasGlyph []bool) string {
+	var missing []rune
+	for i, r := range rr {
+		if !hasGlyph[i] && !isIgnorable(r) && !containsRune(missing, r) {
+			missing = append(missing, r)
+		}
+	}
+	return string(missing)
+}
+
+// isIgnorable checks if fonts usually have no glyph for the rune as it is not displayed by itself, like spaces,
+// control characters, joiners and variation selectors
+func isIgnorable(r rune) bool {
+	return unicode.IsSpace(r) || unicode.In(r, unicode.Cc, unicode.Cf, unicode.Variation_Selector)
+}
+
+func containsRune(rr []rune, r rune) bool {
+	for _, v := range rr {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
+
+// verticalFont is implemented by fonts supporting vertical writing, see pdf.CompositeFont.SetVertical
+type verticalFont interface {
+	Vertical() bool
+	GetVerticalAdvance(text string, fontSize float64) float64
+}
+
+// verticalRun is a part of a text in vertical writing
+type verticalRun struct {
+	text     string
+	font     pdf.FontHandler
+	vertical bool // drawn with the vertical metrics of the font, otherwise a single upright grapheme cluster
+}
+
+// verticalRuns splits the text into runs drawn with the vertical font and grapheme clusters stacked upright. Characters
+// none of the fonts has a glyph for are returned as missing.
+func (q *TextChunk) verticalRuns(text string) ([]verticalRun, string) {
+	var res []verticalRun
+	clusters, missing := q.fonts().clusters(text)
+	for _, cluster := range clusters {
+		vf, ok := cluster.font.(verticalFont)
+		vertical := ok && vf.Vertical()
+		if n := len(res) - 1; n >= 0 && vertical && res[n].vertical && res[n].font == cluster.font {
+			res[n].text += cluster.text
+			continue
+		}
+		res = append(res, verticalRun{text: cluster.text, font: cluster.font, vertical: vertical})
+	}
+	return res, missing
+}
+
+// advance returns the vertical advance of the run
+func (q *verticalRun) advance(fontSize float64) float64 {
+	if q.vertical {
+		return q.font.(verticalFont).GetVerticalAdvance(q.text, fontSize)
+	}
+	return fontSize
+}
+
+// getColumnHeight returns the height of the given text in vertical writing considering font, fontsize and char
+// spacing
+func (q *TextChunk) getColumnHeight(text string) float64 {
+	if text == "" {
+		return 0
+	}
+
+	var v float64
+	runs, _ := q.verticalRuns(text)
+	for _, run := range runs {
+		v += run.advance(q.fontSize())
+	}
+	if q.CharSpacing.Value != 0 {
+		v += float64(len([]rune(text))-1) * q.CharSpacing.Pt()
+	}
+	return v
+}
+
+// drawVertical draws the text in vertical writing, centered on x, starting at top
+func (q *TextChunk) drawVertical(page *pdf.Page, x, top float64) (string, error) {
+	// if no font or text given, ignore chunk
+	if q.Font == nil {
+		return "", errors.New("no font set")
+	}
+
+	// set format
+	warning, err := q.setFontAndColor(page)
+	if err != nil {
+		return warning, err
+	}
+
+	// draw runs: vertical fonts position glyphs at their top center, upright glyphs are centered on the default
+	// vertical origin of PDF, 880/1000 of the font size above the baseline
+	runs, missing := q.verticalRuns(q.Text)
+	if missing != "" {
+		warning = joinWarnings(warning, "No glyph for \""+missing+"\" in any font of text \""+q.Text+"\"")
+	}
+	page.TextState_Ts(0)
+	x += q.rise()
+	y := top
+	font := q.Font
+	for _, run := range runs {
+		if q.outlines(page) {
+			if run.vertical {
+				return warning, errors.New("text in vertical writing cannot be drawn as outlines")
+			}
+			if !pdf.HasOutlines(run.font) {
+				return warning, errNoOutlines(q.Text)
+			}
+			err := page.TextOutline(run.font, q.fontSize(), run.text, 1, 0, 0, 1,
+				x-run.font.GetWidth(run.text, q.fontSize())/2, y-q.fontSize()*0.88)
+			if err != nil {
+				return warning, err
+			}
+			y -= run.advance(q.fontSize()) + float64(len([]rune(run.text)))*q.CharSpacing.Pt()
+			continue
+		}
+		if run.font != font {
+			page.TextState_Tf(run.font, q.fontSize())
+			font = run.font
+		}
 		page.TextObjects_BT()
-		page.TextPosition_Tm(1, 0, c, 1, left, top)
-		page.TextShowing_Tj(pdftext.StringModifications(q.Text, q.Font))
+		if run.vertical {
+			page.TextPosition_Tm(1, 0, 0, 1, x, y)
+		} else {
+			page.TextPosition_Tm(1, 0, 0, 1, x-run.font.GetWidth(run.text, q.fontSize())/2, y-q.fontSize()*0.88)
+		}
+		page.TextShowing_Tj(run.text)
 		page.TextObjects_ET()
+		y -= run.advance(q.fontSize()) + float64(len([]rune(run.text)))*q.CharSpacing.Pt()
 	}
 
-	// underline/strike-through text
+	// side line/strike-through text
+	height := q.getColumnHeight(q.Text)
+	th := q.Font.GetUnderlineThickness(q.fontSize())
 	if q.Underline {
-		th := q.Font.GetUnderlineThickness(q.FontSize)
-		page.Path_re(left, top+q.Font.GetUnderlinePosition(q.FontSize)-th, q.getLineWidth(q.Text), th)
+		page.Path_re(x+q.fontSize()/2-q.Font.GetUnderlinePosition(q.fontSize()), top-height, th, height)
 		page.Path_f()
 	}
 	if q.StrikeThrough {
-		page.Path_re(
-			left, top+q.Font.GetTop(q.FontSize)/3,
-			q.getLineWidth(q.Text), q.Font.GetUnderlineThickness(q.FontSize),
-		)
+		page.Path_re(x-th/2, top-height, th, height)
 		page.Path_f()
 	}
 
+	// link
+	if q.Link != "" {
+		page.AddLink(x-q.fontSize()/2, top-height, q.fontSize(), height, q.Link)
+	}
+
 	return warning, nil
 }
@@ -3,8 +3,11 @@ package gopdf
 import (
 	"math"
 	"strings"
+	"unicode"
 
 	"github.com/raceresult/gopdf/pdf"
+	"github.com/raceresult/gopdf/pdftext"
+	"github.com/raceresult/gopdf/pdftext/arabic"
 	"github.com/raceresult/gopdf/types"
 )
 
@@ -20,6 +23,15 @@ type TextChunkBoxElement struct {
 	TextAlign       HorizontalAlign
 	VerticalAlign   VerticalAlign
 	HeightBufferRel float64
+
+	// alignment of the last line of paragraphs if TextAlign is HorizontalAlignJustify
+	TextAlignLast HorizontalAlign
+
+	// vertical writing: lines are columns from top to bottom, progressing from right to left. TextAlign positions
+	// the text within the columns (left = top), VerticalAlign the columns within the box (top = right). Characters
+	// of fonts set to vertical writing (pdf.CompositeFont.SetVertical) use the vertical metrics and glyphs of the
+	// font, other characters are stacked upright.
+	Vertical bool
 }
 
 // Build adds the element to the content stream
@@ -34,6 +46,9 @@ func (q *TextChunkBoxElement) Build(page *pdf.Page) (string, error) {
 	if len(wrapped) == 0 {
 		return warning, nil
 	}
+	if !q.Vertical {
+		reorderLines(wrapped)
+	}
 
 	// set format of first font before saving graphics state
 	if len(wrapped[0].ChunkWidths) != 0 {
@@ -41,7 +56,7 @@ func (q *TextChunkBoxElement) Build(page *pdf.Page) (string, error) {
 		if err != nil {
 			return warning, err
 		}
-		warning += warning2
+		warning = joinWarnings(warning, warning2)
 	}
 
 	// graphics state
@@ -66,6 +81,11 @@ func (q *TextChunkBoxElement) Build(page *pdf.Page) (string, error) {
 		page.GraphicsState_gs(n)
 	}
 
+	// vertical writing
+	if q.Vertical {
+		return q.drawColumns(page, wrapped, warning)
+	}
+
 	// calculate total height
 	var totalHeight float64
 	for _, l := range wrapped {
@@ -84,7 +104,7 @@ func (q *TextChunkBoxElement) Build(page *pdf.Page) (string, error) {
 	}
 
 	// iterate over lines
-	for _, line := range wrapped {
+	for i, line := range wrapped {
 		top -= line.Height
 
 		// shortcut for empty lines
@@ -94,11 +114,17 @@ func (q *TextChunkBoxElement) Build(page *pdf.Page) (string, error) {
 
 		// set position
 		left := 0.0
-		switch q.TextAlign {
+		align := q.TextAlign
+		if align == HorizontalAlignJustify && (i == len(wrapped)-1 || wrapped[i+1].NewParagraph) {
+			align = q.TextAlignLast
+		}
+		switch align {
 		case HorizontalAlignCenter:
 			left += (q.Width.Pt() - line.Width) / 2
 		case HorizontalAlignRight:
 			left += q.Width.Pt() - line.Width
+		case HorizontalAlignJustify:
+			line.justify(q.Width.Pt())
 		}
 
 		// iterate over text chunks in this line
@@ -107,7 +133,7 @@ func (q *TextChunkBoxElement) Build(page *pdf.Page) (string, error) {
 			if err != nil {
 				return "", err
 			}
-			warning += warning2
+			warning = joinWarnings(warning, warning2)
 			left += line.ChunkWidths[j]
 		}
 	}
@@ -115,152 +141,98 @@ func (q *TextChunkBoxElement) Build(page *pdf.Page) (string, error) {
 }
 
 type chunkLine struct {
-	MaxTop      float64
-	Height      float64
-	Width       float64
-	Chunks      []TextChunk
-	ChunkWidths []float64
+	MaxTop       float64
+	Height       float64
+	Width        float64
+	Chunks       []TextChunk
+	ChunkWidths  []float64
+	NewParagraph bool
 }
 
-// wrapLines returns the wrapped text considering line break, max width and max height
-func (q *TextChunkBoxElement) wrapLines() ([]chunkLine, string) {
-	var chunkLines []chunkLine
-	var warning string
-	var currLine *chunkLine
-	boxWidth := q.Width.Pt()
-
-	// iterate over chunks
-	for i, chunk := range q.Chunks {
-		// calc spaceWidth in current chunk
-		charSpacing := chunk.CharSpacing.Pt()
-		if chunk.TextScaling != 0 {
-			charSpacing *= chunk.TextScaling / 100
-		}
-		spaceWidth := chunk.getLineWidth(" ") + charSpacing
-
-		// split chunk texts by line break
-		textLines := strings.Split(strings.ReplaceAll(chunk.Text, "\r\n", "\n"), "\n")
-
-		// iterate over lines within chunk
-		for j, line := range textLines {
-			// create new line
-			if len(chunkLines) == 0 || j > 0 {
-				chunkLines = append(chunkLines, chunkLine{})
-				currLine = &chunkLines[len(chunkLines)-1]
-			}
+// justify distributes the remaining width of the line to its spaces
+func (q *chunkLine) justify(width float64) {
+	var spaces int
+	for _, c := range q.Chunks {
+		spaces += strings.Count(c.Text, " ")
+	}
+	if spaces == 0 || width <= q.Width {
+		return
+	}
 
-			// get width and height
-			w := chunk.getLineWidth(line)
-			h := q.LineHeight
-			if h == 0 {
-				h = chunk.FontHeight().Pt()
-			}
-			fontTop := chunk.Font.GetTop(chunk.FontSize)
+	wordSpacing := (width - q.Width) / float64(spaces)
+	for j := range q.Chunks {
+		q.Chunks[j].wordSpacing = wordSpacing
+		q.ChunkWidths[j] += float64(strings.Count(q.Chunks[j].Text, " ")) * wordSpacing
+	}
+	q.Width = width
+}
 
-			// does the entire line fit in the current chunk line?
-			if boxWidth == 0 || currLine.Width+w <= boxWidth {
-				currLine.Width += w
-				if currLine.Height < h {
-					currLine.Height = h
-				}
-				if currLine.MaxTop < fontTop {
-					currLine.MaxTop = fontTop
-				}
+// drawColumns draws the wrapped lines as vertical columns from right to left
+func (q *TextChunkBoxElement) drawColumns(page *pdf.Page, columns []chunkLine, warning string) (string, error) {
+	// calculate total width
+	var totalWidth float64
+	for _, c := range columns {
+		totalWidth += c.Height
+	}
 
-				if line != "" {
-					c := q.Chunks[i]
-					c.Text = line
-					currLine.Chunks = append(currLine.Chunks, c)
-					currLine.ChunkWidths = append(currLine.ChunkWidths, w)
-				}
-				continue
-			}
+	// set x starting position
+	right := q.Width.Pt()
+	if q.Width.Value <= 0 {
+		right = totalWidth
+	}
+	switch q.VerticalAlign {
+	case VerticalAlignMiddle:
+		right -= (right - totalWidth) / 2
+	case VerticalAlignBottom:
+		right = totalWidth
+	}
 
-			// check how many words fit in the current line
-			words := strings.Split(line, " ")
-			for _, word := range words {
-				w := chunk.getLineWidth(word)
-
-				// case 1: current line is not empty
-				if len(currLine.Chunks) != 0 {
-					// create new line?
-					if currLine.Width+w+spaceWidth+charSpacing > boxWidth {
-						chunkLines = append(chunkLines, chunkLine{})
-						currLine = &chunkLines[len(chunkLines)-1]
-
-					} else {
-						chunkWidth := w + spaceWidth + charSpacing
-						currLine.Width += chunkWidth
-						if currLine.Height < h {
-							currLine.Height = h
-						}
-						if currLine.MaxTop < fontTop {
-							currLine.MaxTop = fontTop
-						}
-
-						c := q.Chunks[i]
-						c.Text = " " + word
-						currLine.Chunks = append(currLine.Chunks, c)
-						currLine.ChunkWidths = append(currLine.ChunkWidths, chunkWidth)
-						continue
-					}
-				}
+	// iterate over columns
+	for _, column := range columns {
+		right -= column.Height
 
-				// case 2: current line is empty and current word fits into line
-				if w <= boxWidth {
-					if word == "" {
-						continue
-					}
-
-					currLine.Width += w
-					if currLine.Height < h {
-						currLine.Height = h
-					}
-					if currLine.MaxTop < fontTop {
-						currLine.MaxTop = fontTop
-					}
-
-					c := q.Chunks[i]
-					c.Text = word
-					currLine.Chunks = append(currLine.Chunks, c)
-					currLine.ChunkWidths = append(currLine.ChunkWidths, w)
-					continue
-				}
+		// shortcut for empty columns
+		if len(column.Chunks) == 0 {
+			continue
+		}
 
-				// case 3: break word
-				currLine.Height = h
-				currLine.MaxTop = fontTop
-				c := q.Chunks[i]
-				c.Text = ""
-				currLine.Chunks = append(currLine.Chunks, c)
-				currLine.ChunkWidths = append(currLine.ChunkWidths, 0)
-				currChunk := &currLine.Chunks[len(currLine.Chunks)-1]
-				for _, c := range word {
-					w := chunk.getLineWidth(string(c))
-					if currLine.Width+w+charSpacing > boxWidth-0.1 {
-						nc := q.Chunks[i]
-						nc.Text = ""
-						newChunkLine := chunkLine{
-							Height:      h,
-							MaxTop:      fontTop,
-							Chunks:      []TextChunk{nc},
-							ChunkWidths: []float64{0},
-						}
-						chunkLines = append(chunkLines, newChunkLine)
-						currLine = &chunkLines[len(chunkLines)-1]
-						currChunk = &currLine.Chunks[0]
-					}
-
-					currLine.Width += w + charSpacing
-					currLine.ChunkWidths[len(currLine.ChunkWidths)-1] += w
-					currChunk.Text += string(c)
-				}
+		// set position
+		top := 0.0
+		switch q.TextAlign {
+		case HorizontalAlignCenter:
+			top -= (q.Height.Pt() - column.Width) / 2
+		case HorizontalAlignRight:
+			top -= q.Height.Pt() - column.Width
+		}
+
+		// iterate over text chunks in this column
+		x := right + column.Height/2
+		for j, chunk := range column.Chunks {
+			warning2, err := chunk.drawVertical(page, x, top)
+			if err != nil {
+				return "", err
 			}
+			warning = joinWarnings(warning, warning2)
+			top -= column.ChunkWidths[j]
 		}
 	}
+	return warning, nil
+}
+
+// wrapLines returns the wrapped text considering line break, max width and max height. Lines are broken at the
+// break opportunities of the Unicode line breaking algorithm; words wider than the box are broken between grapheme
+// clusters. In vertical writing, the lines are columns limited by the height of the box.
+func (q *TextChunkBoxElement) wrapLines() ([]chunkLine, string) {
+	var warning string
+	length, breadth := q.Width.Pt(), q.Height.Pt()
+	if q.Vertical {
+		length, breadth = breadth, length
+	}
+	wrapper := newLineWrapper(q.Chunks, q.LineHeight, q.Vertical)
+	chunkLines := wrapper.wrap(length)
 
 	// check height
-	h := q.Height.Pt() * (1 + q.HeightBufferRel)
+	h := breadth * (1 + q.HeightBufferRel)
 	if h > 0 {
 		for i, l := range chunkLines {
 			h -= l.Height
@@ -280,6 +252,427 @@ func (q *TextChunkBoxElement) wrapLines() ([]chunkLine, string) {
 	return chunkLines, warning
 }
 
+// lineWrapper splits the text of chunks into lines
+type lineWrapper struct {
+	chunks     []TextChunk
+	lineHeight float64
+	text       []rune
+	chunkIndex []int // index of the chunk of every rune
+	breaks     []pdftext.LineBreak
+	graphemes  []bool
+	vertical   bool // lines are measured in vertical writing
+}
+
+func newLineWrapper(chunks []TextChunk, lineHeight float64, vertical bool) *lineWrapper {
+	w := &lineWrapper{
+		chunks:     chunks,
+		lineHeight: lineHeight,
+		vertical:   vertical,
+	}
+	for i, chunk := range chunks {
+		// arabic letters are shaped in logical order before measuring, shaping keeps the number of runes
+		text := chunk.Text
+		if !vertical {
+			text = arabic.ShapeWith(text, chunk.fonts())
+		}
+		for _, r := range text {
+			w.text = append(w.text, r)
+			w.chunkIndex = append(w.chunkIndex, i)
+		}
+	}
+	w.hyphenate()
+	w.breaks = pdftext.LineBreaks(w.text)
+	w.graphemes = pdftext.GraphemeBoundaries(w.text)
+	return w
+}
+
+// hyphenate inserts soft hyphens at the hyphenation points of words in chunks with a language
+func (q *lineWrapper) hyphenate() {
+	var hyphenation bool
+	for _, chunk := range q.chunks {
+		hyphenation = hyphenation || chunk.Language != ""
+	}
+	if !hyphenation {
+		return
+	}
+
+	isWordRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.Is(unicode.Mn, r)
+	}
+
+	text := make([]rune, 0, len(q.text))
+	chunkIndex := make([]int, 0, len(q.chunkIndex))
+	for i := 0; i < len(q.text); {
+		if !isWordRune(q.text[i]) {
+			text = append(text, q.text[i])
+			chunkIndex = append(chunkIndex, q.chunkIndex[i])
+			i++
+			continue
+		}
+
+		// find hyphenation points, unless the word is hyphenated manually
+		end := i + 1
+		for end < len(q.text) && isWordRune(q.text[end]) {
+			end++
+		}
+		var points []int
+		manual := i > 0 && q.text[i-1] == softHyphen || end < len(q.text) && q.text[end] == softHyphen
+		if h := q.chunks[q.chunkIndex[i]].hyphenator(); h != nil && !manual {
+			points = h.Hyphenate(string(q.text[i:end]))
+		}
+
+		// copy word with soft hyphens, which belong to the chunk of the preceding character
+		for k := i; k < end; k++ {
+			if len(points) != 0 && points[0] == k-i {
+				text = append(text, softHyphen)
+				chunkIndex = append(chunkIndex, q.chunkIndex[k-1])
+				points = points[1:]
+			}
+			text = append(text, q.text[k])
+			chunkIndex = append(chunkIndex, q.chunkIndex[k])
+		}
+		i = end
+	}
+	q.text, q.chunkIndex = text, chunkIndex
+}
+
+// wrap breaks the text into lines not wider than boxWidth, or only at mandatory breaks if boxWidth is 0
+func (q *lineWrapper) wrap(boxWidth float64) []chunkLine {
+	var lines []chunkLine
+	n := len(q.text)
+	start := 0
+	newParagraph := true
+	measure := lineMeasure{wrapper: q}
+	for pos := 0; pos < n; {
+		// find end of next segment that must not be broken
+		end := pos + 1
+		for end < n && q.breaks[end] == pdftext.LineBreakProhibited {
+			end++
+		}
+		mandatory := end < n && q.breaks[end] == pdftext.LineBreakMandatory || end == n && isLineSeparator(q.text[n-1])
+
+		// does the segment fit in the current line?
+		if boxWidth == 0 || measure.with(pos, end) <= boxWidth {
+			if boxWidth != 0 {
+				measure.add(pos, end)
+			}
+			pos = end
+			if mandatory {
+				lines = append(lines, q.line(start, end, false, newParagraph))
+				start, newParagraph = end, true
+				measure.reset(start)
+			}
+			continue
+		}
+
+		// continue segment in next line
+		if pos > start {
+			lines = append(lines, q.line(start, pos, true, newParagraph))
+			start, newParagraph = pos, false
+			measure.reset(start)
+			continue
+		}
+
+		// segment is wider than the box: break between grapheme clusters
+		cut := q.nextGrapheme(start)
+		measure.add(start, cut)
+		for next := q.nextGrapheme(cut); cut < end && next <= end; next = q.nextGrapheme(next) {
+			if measure.with(cut, next) > boxWidth {
+				break
+			}
+			measure.add(cut, next)
+			cut = next
+		}
+		lines = append(lines, q.line(start, cut, true, newParagraph))
+		start, pos, newParagraph = cut, cut, false
+		measure.reset(start)
+	}
+
+	// last line, also if empty after line break
+	if start < n || newParagraph {
+		lines = append(lines, q.line(start, n, false, newParagraph))
+	}
+	return lines
+}
+
+// nextGrapheme returns the start of the grapheme cluster following the one at position i
+func (q *lineWrapper) nextGrapheme(i int) int {
+	for i++; i < len(q.text) && !q.graphemes[i]; i++ {
+	}
+	return i
+}
+
+// width returns the width of the line from start to end
+func (q *lineWrapper) width(start, end int, wrapped bool) float64 {
+	_, _, w := q.lineChunks(start, end, wrapped)
+	return w
+}
+
+// charSpacing returns the char spacing added after the last character of the chunk if followed by more text
+func (q *lineWrapper) charSpacing(c *TextChunk) float64 {
+	charSpacing := c.CharSpacing.Pt()
+	if c.TextScaling != 0 && !q.vertical {
+		charSpacing *= c.TextScaling / 100
+	}
+	return charSpacing
+}
+
+// lineMeasure measures the width of a line incrementally, segment by segment, so the text of a line is not
+// measured again for every possible break. Kerning across segment boundaries is not considered.
+type lineMeasure struct {
+	wrapper *lineWrapper
+	start   int
+	width   float64    // width of the added segments including trailing whitespace
+	last    *TextChunk // chunk of the last visible text, nil if nothing is visible yet
+}
+
+// reset starts a new line at start
+func (q *lineMeasure) reset(start int) {
+	q.start, q.width, q.last = start, 0, nil
+}
+
+// with returns the width of the line, wrapped after the text from pos to end
+func (q *lineMeasure) with(pos, end int) float64 {
+	chunks, _, w := q.wrapper.lineChunks(pos, end, true)
+	if len(chunks) == 0 {
+		// trailing whitespace of the added segments is removed as well
+		return q.wrapper.width(q.start, end, true)
+	}
+	return q.join(w)
+}
+
+// add adds the text from pos to end to the line
+func (q *lineMeasure) add(pos, end int) {
+	chunks, _, w := q.wrapper.lineChunks(pos, end, false)
+	if len(chunks) == 0 {
+		return
+	}
+	q.width = q.join(w)
+	q.last = &chunks[len(chunks)-1]
+}
+
+// join returns the width of the line followed by visible text of width w
+func (q *lineMeasure) join(w float64) float64 {
+	if q.last == nil {
+		return w
+	}
+	return q.width + q.wrapper.charSpacing(q.last) + w
+}
+
+// line creates the line from start to end. If the line is wrapped, trailing whitespace is removed and a soft
+// hyphen at the end is displayed.
+func (q *lineWrapper) line(start, end int, wrapped, newParagraph bool) chunkLine {
+	l := chunkLine{NewParagraph: newParagraph}
+	l.Chunks, l.ChunkWidths, l.Width = q.lineChunks(start, end, wrapped)
+
+	// height of line
+	var heightChunks []int
+	for i := start; i < end; i++ {
+		if len(heightChunks) == 0 || heightChunks[len(heightChunks)-1] != q.chunkIndex[i] {
+			heightChunks = append(heightChunks, q.chunkIndex[i])
+		}
+	}
+	if len(heightChunks) == 0 {
+		if start > 0 {
+			heightChunks = append(heightChunks, q.chunkIndex[start-1])
+		} else {
+			for i := range q.chunks {
+				heightChunks = append(heightChunks, i)
+			}
+		}
+	}
+	// text with shifted baseline needs additional space
+	for _, i := range heightChunks {
+		chunk := &q.chunks[i]
+		rise := chunk.rise()
+		h := q.lineHeight
+		if h == 0 {
+			h = chunk.FontHeight().Pt() + math.Abs(rise)
+		}
+		if l.Height < h {
+			l.Height = h
+		}
+		if fontTop := chunk.Font.GetTop(chunk.fontSize()) + rise; l.MaxTop < fontTop {
+			l.MaxTop = fontTop
+		}
+	}
+
+	// fallback fonts used in the line, all fonts share the baseline
+	for i := range l.Chunks {
+		chunk := &l.Chunks[i]
+		chain := chunk.fonts()
+		if len(chain) == 1 {
+			continue
+		}
+		rise := chunk.rise()
+		runs, _ := chain.runs(chunk.Text)
+		for _, run := range runs {
+			if run.font == chunk.Font {
+				continue
+			}
+			if h := run.font.GetHeight(chunk.fontSize()) + math.Abs(rise); q.lineHeight == 0 && l.Height < h {
+				l.Height = h
+			}
+			if fontTop := run.font.GetTop(chunk.fontSize()) + rise; l.MaxTop < fontTop {
+				l.MaxTop = fontTop
+			}
+		}
+	}
+	return l
+}
+
+// lineChunks returns the visible text from start to end split into chunks with their widths and the total width
+func (q *lineWrapper) lineChunks(start, end int, wrapped bool) ([]TextChunk, []float64, float64) {
+	if wrapped {
+		for end > start && isTrailingSpace(q.text[end-1]) {
+			end--
+		}
+	}
+
+	var chunks []TextChunk
+	var widths []float64
+	var width float64
+	for i := start; i < end; {
+		ci := q.chunkIndex[i]
+		var runes []rune
+		for ; i < end && q.chunkIndex[i] == ci; i++ {
+			switch r := q.text[i]; {
+			case r == softHyphen && wrapped && i == end-1:
+				runes = append(runes, '-')
+			case isInvisible(r):
+			default:
+				runes = append(runes, r)
+			}
+		}
+		if len(runes) == 0 {
+			continue
+		}
+
+		// chunk width includes char spacing after last character if followed by another chunk
+		if len(chunks) != 0 {
+			charSpacing := q.charSpacing(&chunks[len(chunks)-1])
+			widths[len(widths)-1] += charSpacing
+			width += charSpacing
+		}
+
+		c := q.chunks[ci]
+		c.Text = string(runes)
+		var w float64
+		if q.vertical {
+			w = c.getColumnHeight(c.Text)
+		} else {
+			w = c.getLineWidth(c.Text)
+		}
+		chunks = append(chunks, c)
+		widths = append(widths, w)
+		width += w
+	}
+	return chunks, widths, width
+}
+
+const softHyphen = 0x00AD
+
+// isLineSeparator checks if the rune forces a line break
+func isLineSeparator(r rune) bool {
+	switch r {
+	case '\n', '\r', 0x000B, 0x000C, 0x0085, 0x2028, 0x2029:
+		return true
+	}
+	return false
+}
+
+// isTrailingSpace checks if the rune is whitespace that is removed at the end of a wrapped line
+func isTrailingSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == 0x3000 || r == 0x200B || r >= 0x2000 && r <= 0x200A || r == 0x205F
+}
+
+// isInvisible checks if the rune is a control character not to be displayed
+func isInvisible(r rune) bool {
+	return isLineSeparator(r) || r == softHyphen || r == 0x200B || r == 0x2060 || r == 0xFEFF
+}
+
+// reorderLines applies the bidirectional algorithm to the wrapped lines: embedding levels are resolved per paragraph,
+// the chunks of each line are then split and reordered for display from left to right
+func reorderLines(lines []chunkLine) {
+	for start := 0; start < len(lines); {
+		end := start + 1
+		for end < len(lines) && !lines[end].NewParagraph {
+			end++
+		}
+		reorderParagraph(lines[start:end])
+		start = end
+	}
+}
+
+// reorderParagraph applies the bidirectional algorithm to the lines of a paragraph
+func reorderParagraph(lines []chunkLine) {
+	// concatenate text of lines, arabic letters are shaped already
+	type chunkRef struct {
+		line, chunk int
+	}
+	var text []rune
+	var refs []chunkRef
+	lineStart := make([]int, len(lines))
+	lineEnd := make([]int, len(lines))
+	for i := range lines {
+		if i > 0 {
+			text = append(text, ' ')
+			refs = append(refs, chunkRef{line: -1})
+		}
+		lineStart[i] = len(text)
+		for j := range lines[i].Chunks {
+			for _, r := range lines[i].Chunks[j].Text {
+				text = append(text, r)
+				refs = append(refs, chunkRef{line: i, chunk: j})
+			}
+		}
+		lineEnd[i] = len(text)
+	}
+
+	// reorder lines
+	para := pdftext.NewBidiParagraph(string(text), pdftext.DirectionAuto)
+	for i := range lines {
+		line := &lines[i]
+		order := para.VisualOrder(lineStart[i], lineEnd[i])
+
+		var chunks []TextChunk
+		var widths []float64
+		var width float64
+		var changed bool
+		for k := 0; k < len(order); {
+			ref := refs[order[k]]
+			original := line.Chunks[ref.chunk]
+
+			// collect runes of the same chunk
+			logical := true
+			runes := []rune{para.Rune(order[k])}
+			l := k + 1
+			for ; l < len(order) && refs[order[l]] == ref; l++ {
+				logical = logical && order[l] == order[l-1]+1
+				runes = append(runes, para.Rune(order[l]))
+			}
+
+			c := original
+			c.Text = string(runes)
+			c.visual = true
+			w := line.ChunkWidths[ref.chunk]
+			if !logical || c.Text != original.Text {
+				w = c.getLineWidth(c.Text)
+				changed = true
+			}
+			chunks = append(chunks, c)
+			widths = append(widths, w)
+			width += w
+			k = l
+		}
+		line.Chunks = chunks
+		line.ChunkWidths = widths
+		if changed {
+			line.Width = width
+		}
+	}
+}
+
 // TextHeight returns the height of the text, accounting for line breaks and max width
 func (q *TextChunkBoxElement) TextHeight() Length {
 	var h float64
@@ -299,9 +692,14 @@ func (q *TextChunkBoxElement) ShrinkToFit() {
 	lines, _ := q.wrapLines()
 	q.Width, q.Height = orgW, orgH
 
+	// in vertical writing, columns are limited by the height and their sum by the width
+	w, h := q.Width.Pt(), q.Height.Pt()
+	if q.Vertical {
+		w, h = h, w
+	}
+
 	// check height
 	f := 1.0
-	h := q.Height.Pt()
 	if h > 0 {
 		// get total height
 		var totalHeight float64
@@ -316,7 +714,6 @@ func (q *TextChunkBoxElement) ShrinkToFit() {
 	}
 
 	// adapt to width
-	w := q.Width.Pt()
 	if w > 0 {
 		// get max width
 		var maxWidth float64
@@ -351,6 +748,7 @@ func (q *TextChunkBoxElement) ShrinkToFit() {
 	if f != 1 {
 		for i := range q.Chunks {
 			q.Chunks[i].FontSize *= f
+			q.Chunks[i].BaselineShift.Value *= f
 		}
 	}
 }
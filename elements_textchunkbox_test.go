@@ -0,0 +1,329 @@
+package gopdf
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/raceresult/gopdf/pdf"
+	"github.com/raceresult/gopdf/types"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// widthFont is a font with fixed widths per rune: 10 for arabic letters, 5 for arabic presentation forms and 4 for
+// other characters, independent of the font size
+type widthFont struct {
+	pdf.FontHandler
+}
+
+func (q widthFont) GetWidth(text string, _ float64) float64 {
+	var w float64
+	for _, r := range text {
+		switch {
+		case r >= 0xFB50 && r <= 0xFDFF || r >= 0xFE70 && r <= 0xFEFF:
+			w += 5
+		case unicode.Is(unicode.Arabic, r):
+			w += 10
+		default:
+			w += 4
+		}
+	}
+	return w
+}
+
+func (q widthFont) HasGylph(runes []rune) []bool {
+	res := make([]bool, len(runes))
+	for i := range res {
+		res[i] = true
+	}
+	return res
+}
+
+func (q widthFont) FallbackFont() pdf.FontHandler {
+	return nil
+}
+
+func newWidthFont(t *testing.T) widthFont {
+	t.Helper()
+	font, err := New().NewStandardFont(types.StandardFont_Helvetica, types.EncodingWinAnsi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return widthFont{FontHandler: font}
+}
+
+// reorderedLines wraps and reorders the text like Build
+func reorderedLines(box *TextChunkBoxElement) []chunkLine {
+	lines, _ := box.wrapLines()
+	reorderLines(lines)
+	return lines
+}
+
+func TestTextChunkBoxBidi(t *testing.T) {
+	font := newWidthFont(t)
+	tests := []struct {
+		chunks []string
+		want   []string
+	}{
+		{[]string{"abc שלום def"}, []string{"abc םולש def"}},
+		{[]string{"abc ", "שלום", " def"}, []string{"abc ", "םולש", " def"}},
+		{[]string{"שלום ", "abc"}, []string{"abc", " םולש"}},
+		{[]string{"של", "ום"}, []string{"םו", "לש"}},
+		{[]string{"a ", "(ב)", " c"}, []string{"a ", "(ב)", " c"}},
+		{[]string{"ב ", "(a)", " ג"}, []string{"ג ", "(a)", " ב"}},
+		{[]string{"ב (a) ג"}, []string{"ג (a) ב"}},
+		{[]string{"abc ", "سلام"}, []string{"abc ", "\ufee1\ufe8e\ufee0\ufeb3"}},
+	}
+	for _, tt := range tests {
+		box := &TextChunkBoxElement{Width: Pt(1000)}
+		for _, s := range tt.chunks {
+			box.Chunks = append(box.Chunks, TextChunk{Text: s, Font: font, FontSize: 10})
+		}
+		lines := reorderedLines(box)
+		if len(lines) != 1 {
+			t.Errorf("%q: got %d lines", tt.chunks, len(lines))
+			continue
+		}
+		var got []string
+		var width float64
+		for i, c := range lines[0].Chunks {
+			got = append(got, c.Text)
+			if w := font.GetWidth(c.Text, 10); lines[0].ChunkWidths[i] != w {
+				t.Errorf("%q: chunk %q has width %v, want %v", tt.chunks, c.Text, lines[0].ChunkWidths[i], w)
+			}
+			width += lines[0].ChunkWidths[i]
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%q: got %q, want %q", tt.chunks, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q: got %q, want %q", tt.chunks, got, tt.want)
+				break
+			}
+		}
+		if lines[0].Width != width {
+			t.Errorf("%q: line width %v, want %v", tt.chunks, lines[0].Width, width)
+		}
+	}
+}
+
+func TestTextChunkBoxArabicWidth(t *testing.T) {
+	// the shaped word is 20pt wide, unshaped 40pt
+	font := newWidthFont(t)
+	box := &TextChunkBoxElement{
+		Chunks: []TextChunk{{Text: "سلام", Font: font, FontSize: 10}},
+		Width:  Pt(25),
+	}
+	lines := reorderedLines(box)
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, expected the shaped word to fit in one line", len(lines))
+	}
+	if lines[0].Width != 20 {
+		t.Errorf("got width %v, want 20", lines[0].Width)
+	}
+	for _, r := range lines[0].Chunks[0].Text {
+		if r < 0xFE70 || r > 0xFEFF {
+			t.Errorf("rune %U not shaped", r)
+		}
+	}
+}
+
+// countingFont counts the runes measured
+type countingFont struct {
+	widthFont
+	runes *int
+}
+
+func (q countingFont) GetWidth(text string, fontSize float64) float64 {
+	*q.runes += len([]rune(text))
+	return q.widthFont.GetWidth(text, fontSize)
+}
+
+func TestTextChunkBoxWrap(t *testing.T) {
+	text := strings.Repeat("ab ", 200)
+	tests := []struct {
+		charSpacing Length
+		words       int
+	}{
+		{Pt(0), 8}, // 12pt per word without the trailing space
+		{Pt(1), 7}, // 15pt per word without the trailing space
+	}
+	for _, tt := range tests {
+		var measured int
+		font := countingFont{widthFont: newWidthFont(t), runes: &measured}
+		box := &TextChunkBoxElement{
+			Chunks: []TextChunk{{Text: text, Font: font, FontSize: 10, CharSpacing: tt.charSpacing}},
+			Width:  Pt(100),
+		}
+		lines, _ := box.wrapLines()
+		if want := (200 + tt.words - 1) / tt.words; len(lines) != want {
+			t.Errorf("char spacing %v: got %d lines, want %d", tt.charSpacing, len(lines), want)
+		}
+		for i, l := range lines {
+			if i < len(lines)-1 && l.Chunks[0].Text != strings.TrimSpace(strings.Repeat("ab ", tt.words)) {
+				t.Errorf("char spacing %v: line %d is %q", tt.charSpacing, i, l.Chunks[0].Text)
+			}
+			if l.Width > 100 {
+				t.Errorf("char spacing %v: line %d is %vpt wide", tt.charSpacing, i, l.Width)
+			}
+		}
+
+		// every part of the text is measured a few times only, not again for every break
+		if measured > 4*len(text) {
+			t.Errorf("char spacing %v: measured %d runes for text of %d runes", tt.charSpacing, measured, len(text))
+		}
+	}
+}
+
+func TestTextChunkBoxWarnings(t *testing.T) {
+	font, err := New().NewCompositeFont(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box := &TextChunkBoxElement{
+		Chunks: []TextChunk{
+			{Text: "a漢", Font: font, FontSize: 10},
+			{Text: " b字", Font: font, FontSize: 10},
+		},
+		Width:  Pt(300),
+		Height: Pt(100),
+	}
+	warning, err := box.Build(pdf.NewPage(595, 842))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(warning, "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "漢") || !strings.Contains(lines[1], "字") {
+		t.Errorf("expected one warning per line, got %q", warning)
+	}
+}
+
+func TestChunkLineJustify(t *testing.T) {
+	font := newWidthFont(t)
+	line := chunkLine{
+		Width:       32,
+		Chunks:      []TextChunk{{Text: "aa b", Font: font}, {Text: "b cc", Font: font}},
+		ChunkWidths: []float64{16, 16},
+	}
+	line.justify(40)
+	if line.Width != 40 || line.ChunkWidths[0] != 20 || line.ChunkWidths[1] != 20 {
+		t.Errorf("got width %v and chunk widths %v, want 40 and [20 20]", line.Width, line.ChunkWidths)
+	}
+	for i, c := range line.Chunks {
+		if c.wordSpacing != 4 {
+			t.Errorf("chunk %d: got word spacing %v, want 4", i, c.wordSpacing)
+		}
+	}
+
+	// lines without spaces or too wide are not changed
+	for _, line := range []chunkLine{
+		{Width: 32, Chunks: []TextChunk{{Text: "aaaaaaaa", Font: font}}, ChunkWidths: []float64{32}},
+		{Width: 48, Chunks: []TextChunk{{Text: "aaaa aaaa aa", Font: font}}, ChunkWidths: []float64{48}},
+	} {
+		width := line.Width
+		line.justify(40)
+		if line.Width != width || line.Chunks[0].wordSpacing != 0 {
+			t.Errorf("%q: line changed by justify", line.Chunks[0].Text)
+		}
+	}
+}
+
+func TestTextChunkBoxJustify(t *testing.T) {
+	b := New()
+	font, err := b.NewStandardFont(types.StandardFont_Helvetica, types.EncodingWinAnsi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box := &TextChunkBoxElement{
+		Chunks:    []TextChunk{{Text: "aaa bbb ccc ddd eee fff ggg", Font: font, FontSize: 10}},
+		Left:      Pt(30),
+		Top:       Pt(30),
+		Width:     Pt(60),
+		TextAlign: HorizontalAlignJustify,
+	}
+	out := buildUncompressed(t, b, box)
+
+	// word spacing for the first lines, none for the last line
+	m := regexp.MustCompile(`\n([-0-9.]+) Tw\n`).FindAllStringSubmatch(out, -1)
+	if len(m) < 2 {
+		t.Fatalf("got %d Tw operators, want word spacing and its reset", len(m))
+	}
+	if v, _ := strconv.ParseFloat(m[0][1], 64); v <= 0 {
+		t.Errorf("got word spacing %v for the first line, want positive", v)
+	}
+	if last := m[len(m)-1][1]; last != "0" {
+		t.Errorf("got word spacing %s for the last line, want 0", last)
+	}
+}
+
+func TestTextChunkBoxVertical(t *testing.T) {
+	// upright characters of a horizontal font: four per column of 40pt, columns from right to left
+	b := New()
+	font, err := b.NewStandardFont(types.StandardFont_Courier, types.EncodingWinAnsi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box := &TextChunkBoxElement{
+		Chunks:   []TextChunk{{Text: "abcdefgh", Font: font, FontSize: 10}},
+		Left:     Pt(30),
+		Top:      Pt(30),
+		Width:    Pt(100),
+		Height:   Pt(40),
+		Vertical: true,
+	}
+	out := buildUncompressed(t, b, box)
+	m := regexp.MustCompile(`1 0 0 1 ([-0-9.]+) ([-0-9.]+) Tm`).FindAllStringSubmatch(out, -1)
+	if len(m) != 8 {
+		t.Fatalf("got %d positioned characters, want 8", len(m))
+	}
+	pos := make([][2]float64, len(m))
+	for i := range m {
+		pos[i][0], _ = strconv.ParseFloat(m[i][1], 64)
+		pos[i][1], _ = strconv.ParseFloat(m[i][2], 64)
+	}
+	for i := 1; i < len(pos); i++ {
+		if i%4 == 0 {
+			if pos[i][0] >= pos[i-1][0] || pos[i][1] != pos[0][1] {
+				t.Errorf("character %d does not start a new column left of the previous one: %v", i, pos)
+			}
+			continue
+		}
+		if pos[i][0] != pos[i-1][0] || math.Abs(pos[i-1][1]-pos[i][1]-10) > 1e-6 {
+			t.Errorf("character %d not placed 10pt below the previous one: %v", i, pos)
+		}
+	}
+}
+
+func TestTextChunkBoxVerticalFont(t *testing.T) {
+	b := New()
+	font, err := b.NewCompositeFont(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	font.SetVertical(true)
+	box := &TextChunkBoxElement{
+		Chunks:   []TextChunk{{Text: "abc", Font: font, FontSize: 10}},
+		Left:     Pt(30),
+		Top:      Pt(30),
+		Width:    Pt(100),
+		Height:   Pt(100),
+		Vertical: true,
+	}
+	out := buildUncompressed(t, b, box)
+
+	// one run drawn with the vertical font from the center of the column
+	if !strings.Contains(out, "/Identity-V") || !strings.Contains(out, "/DW2") {
+		t.Error("font not written for vertical writing")
+	}
+	if n := strings.Count(out, " Tm\n"); n != 1 {
+		t.Errorf("got %d text positions, want 1 for the run of the vertical font", n)
+	}
+	if got := font.GetVerticalAdvance("abc", 10); got <= 0 || box.Chunks[0].getColumnHeight("abc") != got {
+		t.Errorf("column height %v differs from the vertical advance %v", box.Chunks[0].getColumnHeight("abc"), got)
+	}
+}
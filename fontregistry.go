@@ -0,0 +1,287 @@
+package gopdf
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/raceresult/gopdf/pdf"
+	"github.com/raceresult/gopdf/pdf/unitype"
+)
+
+// Font weights as used in the OS/2 table of fonts and by FontRegistry.Lookup
+const (
+	FontWeightThin       = 100
+	FontWeightExtraLight = 200
+	FontWeightLight      = 300
+	FontWeightRegular    = 400
+	FontWeightMedium     = 500
+	FontWeightSemiBold   = 600
+	FontWeightBold       = 700
+	FontWeightExtraBold  = 800
+	FontWeightBlack      = 900
+)
+
+// fontWeightNames maps the weight names used in font descriptions to weights
+var fontWeightNames = map[string]int{
+	"thin":       FontWeightThin,
+	"hairline":   FontWeightThin,
+	"extralight": FontWeightExtraLight,
+	"ultralight": FontWeightExtraLight,
+	"light":      FontWeightLight,
+	"regular":    FontWeightRegular,
+	"normal":     FontWeightRegular,
+	"book":       FontWeightRegular,
+	"medium":     FontWeightMedium,
+	"semibold":   FontWeightSemiBold,
+	"demibold":   FontWeightSemiBold,
+	"bold":       FontWeightBold,
+	"extrabold":  FontWeightExtraBold,
+	"ultrabold":  FontWeightExtraBold,
+	"black":      FontWeightBlack,
+	"heavy":      FontWeightBlack,
+}
+
+// FontRegistry indexes fonts by family, weight and style, so that text can request fonts like
+// "Noto Sans, bold, italic" instead of loading font files itself. Fonts are added to the document when they are
+// used first.
+type FontRegistry struct {
+	file  *pdf.File
+	faces []*registeredFace
+	mux   sync.Mutex
+}
+
+// registeredFace is a font known to the registry
+type registeredFace struct {
+	unitype.CollectionFace
+	collection bool
+	read       func() ([]byte, error)
+	font       pdf.FontHandler
+}
+
+// FontRegistry returns the font registry of the document
+func (q *Builder) FontRegistry() *FontRegistry {
+	if q.fontRegistry == nil {
+		q.fontRegistry = &FontRegistry{file: q.file}
+	}
+	return q.fontRegistry
+}
+
+// AddDir adds all font files (ttf, otf, ttc, otc, woff) of the directory and its subdirectories to the registry
+func (q *FontRegistry) AddDir(dir string) error {
+	return q.AddFS(os.DirFS(dir), ".")
+}
+
+// AddFS adds all font files (ttf, otf, ttc, otc, woff) of the directory root of the file system and its subdirectories to
+// the registry. Files that cannot be parsed are skipped.
+func (q *FontRegistry) AddFS(fsys fs.FS, root string) error {
+	return fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(path.Ext(name)) {
+		case ".ttf", ".otf", ".ttc", ".otc", ".woff":
+		default:
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		_ = q.add(data, func() ([]byte, error) { return fs.ReadFile(fsys, name) })
+		return nil
+	})
+}
+
+// AddFont adds a font file (ttf, otf, ttc, otc, woff) to the registry
+func (q *FontRegistry) AddFont(data []byte) error {
+	return q.add(data, func() ([]byte, error) { return data, nil })
+}
+
+// add adds the faces of the font file to the registry, read returns the file when the font is used
+func (q *FontRegistry) add(data []byte, read func() ([]byte, error)) error {
+	faces, err := unitype.ParseFaces(data)
+	if err != nil {
+		return err
+	}
+
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	for _, face := range faces {
+		q.faces = append(q.faces, &registeredFace{
+			CollectionFace: face,
+			collection:     unitype.IsCollection(data),
+			read:           read,
+		})
+	}
+	return nil
+}
+
+// Families returns the names of the font families in the registry
+func (q *FontRegistry) Families() []string {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	unique := make(map[string]struct{})
+	var res []string
+	for _, face := range q.faces {
+		if _, ok := unique[face.Family]; ok {
+			continue
+		}
+		unique[face.Family] = struct{}{}
+		res = append(res, face.Family)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Font returns the font matching the description, which consists of the family name followed by comma-separated
+// styles, e.g. "Noto Sans, bold, italic" or "Roboto, light". Weights can be given by name (thin, extralight, light,
+// regular, medium, semibold, bold, extrabold, black) or number (100-900). See Lookup for the meaning of the results.
+func (q *FontRegistry) Font(description string) (font pdf.FontHandler, synthBold, synthItalic bool, err error) {
+	family, weight, italic, err := parseFontDescription(description)
+	if err != nil {
+		return nil, false, false, err
+	}
+	return q.Lookup(family, weight, italic)
+}
+
+// SetFont sets Font, Bold and Italic of the text chunk to the font matching the description, see Font. Bold and
+// Italic are only set if the registry has no face of the requested style, so that the style is synthesized.
+func (q *FontRegistry) SetFont(chunk *TextChunk, description string) error {
+	font, bold, italic, err := q.Font(description)
+	if err != nil {
+		return err
+	}
+	chunk.Font = font
+	chunk.Bold = bold
+	chunk.Italic = italic
+	return nil
+}
+
+// Lookup returns the face of the family that matches the weight and style best, using the font matching rules of
+// CSS. synthBold and synthItalic are true if the registry has no face of the requested style, the style then needs to
+// be synthesized, e.g. by TextChunk.Bold and TextChunk.Italic.
+func (q *FontRegistry) Lookup(family string, weight int, italic bool) (font pdf.FontHandler, synthBold, synthItalic bool, err error) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	// find best face of the family
+	name := normalizeFamilyName(family)
+	var best *registeredFace
+	for _, face := range q.faces {
+		if normalizeFamilyName(face.Family) != name {
+			continue
+		}
+		if best == nil || betterFace(face, best, weight, italic) {
+			best = face
+		}
+	}
+	if best == nil {
+		return nil, false, false, errors.New("font family \"" + family + "\" not found")
+	}
+
+	// add font to document when used first
+	if best.font == nil {
+		data, err := best.read()
+		if err != nil {
+			return nil, false, false, err
+		}
+		if best.collection {
+			if data, err = unitype.ExtractCollectionFont(data, best.Index); err != nil {
+				return nil, false, false, err
+			}
+		}
+		if best.PostScriptOutlines {
+			best.font, err = q.file.NewCompositeFontFromOTF(data)
+		} else {
+			best.font, err = q.file.NewCompositeFontFromTTF(data, nil)
+		}
+		if err != nil {
+			return nil, false, false, err
+		}
+	}
+
+	synthBold = weight >= FontWeightSemiBold && best.Weight < FontWeightSemiBold
+	synthItalic = italic && !best.Italic
+	return best.font, synthBold, synthItalic, nil
+}
+
+// betterFace checks if face a matches weight and style better than face b. The style has priority over the weight.
+func betterFace(a, b *registeredFace, weight int, italic bool) bool {
+	if (a.Italic == italic) != (b.Italic == italic) {
+		return a.Italic == italic
+	}
+	return weightDistance(weight, a.Weight) < weightDistance(weight, b.Weight)
+}
+
+// weightDistance returns the rank of a face weight for the desired weight according to CSS font matching: for
+// desired weights between 400 and 500 heavier weights up to 500 are checked first, then lighter weights, then weights
+// above 500; below 400 lighter weights are preferred, above 500 heavier weights.
+func weightDistance(desired, w int) int {
+	switch {
+	case desired >= 400 && desired <= 500:
+		if w >= desired && w <= 500 {
+			return w - desired
+		}
+		if w < desired {
+			return 1000 + desired - w
+		}
+		return 2000 + w - 500
+	case desired < 400:
+		if w <= desired {
+			return desired - w
+		}
+		return 1000 + w - desired
+	default:
+		if w >= desired {
+			return w - desired
+		}
+		return 1000 + desired - w
+	}
+}
+
+// normalizeFamilyName returns the family name in lower case with single spaces
+func normalizeFamilyName(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// parseFontDescription splits a font description like "Noto Sans, bold, italic" into family, weight and style
+func parseFontDescription(s string) (family string, weight int, italic bool, err error) {
+	parts := strings.Split(s, ",")
+	family = strings.TrimSpace(parts[0])
+	if family == "" {
+		return "", 0, false, errors.New("font description without family name")
+	}
+
+	weight = FontWeightRegular
+	for _, part := range parts[1:] {
+		for _, token := range strings.Fields(strings.ToLower(part)) {
+			token = strings.ReplaceAll(token, "-", "")
+			if w, ok := fontWeightNames[token]; ok {
+				weight = w
+				continue
+			}
+			switch token {
+			case "italic", "oblique":
+				italic = true
+				continue
+			}
+			if w, err := strconv.Atoi(token); err == nil && w >= 1 && w <= 1000 {
+				weight = w
+				continue
+			}
+			return "", 0, false, errors.New("unknown style \"" + token + "\" in font description")
+		}
+	}
+	return family, weight, italic, nil
+}
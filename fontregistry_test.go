@@ -0,0 +1,139 @@
+package gopdf
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/raceresult/gopdf/pdf"
+	"github.com/raceresult/gopdf/pdf/unitype"
+	"github.com/raceresult/gopdf/types"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestFontRegistryWeightFallback(t *testing.T) {
+	// faces with the given weights whose fonts are already added to the document
+	b := New()
+	r := b.FontRegistry()
+	weights := make(map[pdf.FontHandler]int)
+	for _, w := range []int{100, 300, 500, 600, 900} {
+		font, err := b.NewStandardFont(types.StandardFont_Helvetica, types.EncodingWinAnsi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		weights[font] = w
+		r.faces = append(r.faces, &registeredFace{
+			CollectionFace: unitype.CollectionFace{Family: "Test Sans", Weight: w},
+			font:           font,
+		})
+	}
+
+	all := r.faces
+
+	// each desired weight lists the faces in the order of the CSS font matching algorithm
+	for desired, order := range map[int][]int{
+		400: {500, 300, 100, 600, 900},
+		450: {500, 300, 100, 600, 900},
+		500: {500, 300, 100, 600, 900},
+		300: {300, 100, 500, 600, 900},
+		200: {100, 300, 500, 600, 900},
+		600: {600, 900, 500, 300, 100},
+		700: {900, 600, 500, 300, 100},
+	} {
+		faces := append([]*registeredFace(nil), all...)
+		var got []int
+		for len(faces) != 0 {
+			r.faces = faces
+			font, _, _, err := r.Lookup("test  SANS", desired, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := weights[font]
+			got = append(got, w)
+			for i, face := range faces {
+				if face.Weight == w {
+					faces = append(faces[:i:i], faces[i+1:]...)
+					break
+				}
+			}
+		}
+		if !reflect.DeepEqual(got, order) {
+			t.Errorf("weight %d: got order %v, want %v", desired, got, order)
+		}
+	}
+}
+
+func TestFontRegistry(t *testing.T) {
+	b := New()
+	r := b.FontRegistry()
+	err := r.AddFS(fstest.MapFS{
+		"fonts/GoRegular.ttf":      {Data: goregular.TTF},
+		"fonts/bold/GoBold.TTF":    {Data: gobold.TTF},
+		"fonts/GoItalic.ttf":       {Data: goitalic.TTF},
+		"fonts/README.txt":         {Data: []byte("not a font")},
+		"fonts/broken/Invalid.ttf": {Data: []byte("not a font either")},
+	}, "fonts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Families(); !reflect.DeepEqual(got, []string{"Go"}) {
+		t.Errorf("got families %v", got)
+	}
+
+	face := func(font pdf.FontHandler) string {
+		for _, f := range r.faces {
+			if f.font == font && font != nil {
+				return f.FullName
+			}
+		}
+		return ""
+	}
+	for _, tc := range []struct {
+		description string
+		face        string
+		synthBold   bool
+		synthItalic bool
+	}{
+		{"Go", "Go Regular", false, false},
+		{"go, light", "Go Regular", false, false},
+		{"Go, bold", "Go Bold", false, false},
+		{"Go, 800", "Go Bold", false, false},
+		{"Go, italic", "Go Italic", false, false},
+		{"Go, bold, italic", "Go Italic", true, false},
+		{"Go, semi-bold oblique", "Go Italic", true, false},
+	} {
+		font, synthBold, synthItalic, err := r.Font(tc.description)
+		if err != nil {
+			t.Errorf("%q: %v", tc.description, err)
+			continue
+		}
+		if got := face(font); got != tc.face || synthBold != tc.synthBold || synthItalic != tc.synthItalic {
+			t.Errorf("%q: got %q, bold %v, italic %v, want %q, bold %v, italic %v", tc.description, got,
+				synthBold, synthItalic, tc.face, tc.synthBold, tc.synthItalic)
+		}
+	}
+
+	// fonts are added to the document once
+	first, _, _, _ := r.Font("Go")
+	second, _, _, _ := r.Font("Go, regular")
+	if first != second {
+		t.Error("font added twice")
+	}
+
+	// the italic face is used, bold is synthesized
+	chunk := TextChunk{Italic: true}
+	if err := r.SetFont(&chunk, "Go, black, italic"); err != nil {
+		t.Fatal(err)
+	}
+	if face(chunk.Font) != "Go Italic" || !chunk.Bold || chunk.Italic {
+		t.Errorf("SetFont: got %q, bold %v, italic %v", face(chunk.Font), chunk.Bold, chunk.Italic)
+	}
+
+	for _, description := range []string{"Noto Sans", ", bold", "Go, wide"} {
+		if _, _, _, err := r.Font(description); err == nil {
+			t.Errorf("%q: no error", description)
+		}
+	}
+}
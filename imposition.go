@@ -0,0 +1,253 @@
+package gopdf
+
+import (
+	"errors"
+	"math"
+
+	"github.com/raceresult/gopdf/pdf"
+)
+
+// ImpositionOrder defines how pages are distributed over the cells of the sheets
+type ImpositionOrder int
+
+const (
+	// ImpositionOrderSequential places the pages in reading order, Columns x Rows pages per sheet
+	ImpositionOrderSequential ImpositionOrder = 0
+
+	// ImpositionOrderBooklet creates a saddle-stitch booklet: two pages per sheet side, sheets are printed duplex,
+	// stacked and folded in the middle. The number of pages is padded with blank pages to a multiple of four.
+	ImpositionOrderBooklet ImpositionOrder = 1
+
+	// ImpositionOrderStepAndRepeat fills all cells of a sheet with the same page, one sheet per page
+	ImpositionOrderStepAndRepeat ImpositionOrder = 2
+)
+
+// Imposition describes how pages are placed on larger sheets for printing, e.g. 2-up, 4-up or booklets
+type Imposition struct {
+	SheetSize     PageSize
+	Columns, Rows int
+	Order         ImpositionOrder
+
+	// Margin of the sheet and space between the cells
+	Margin           Length
+	GutterX, GutterY Length
+
+	// Rotation of the pages within the cells in degrees (counterclockwise, multiple of 90)
+	Rotate int
+
+	// if set, pages are scaled to fit into the cells (keeping the aspect ratio), otherwise they are placed in their
+	// original size. In both cases pages are centered in the cell.
+	FitToCell bool
+
+	// crop marks around every page
+	CropMarks      bool
+	CropMarkLength Length
+	CropMarkOffset Length
+	CropMarkWidth  Length
+	CropMarkColor  Color
+}
+
+// Impose places the given forms on new sheets according to the imposition and adds the sheets as pages to the
+// document. Forms can be created from pages of this document using NewFormFromPage or from parsed documents using
+// NewCapturedPage. Nil forms leave the cell blank.
+func (q *Builder) Impose(forms []*Form, imposition Imposition) ([]*Page, error) {
+	sheets, err := imposition.arrange(forms)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Page, 0, len(sheets))
+	for _, cells := range sheets {
+		p := q.NewPage(imposition.SheetSize)
+		for i, form := range cells {
+			if form == nil {
+				continue
+			}
+			p.AddElement(imposition.place(form, i))
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// arrange distributes the forms to the cells of the sheets
+func (q *Imposition) arrange(forms []*Form) ([][]*Form, error) {
+	// check parameters
+	if q.SheetSize[0].Value <= 0 || q.SheetSize[1].Value <= 0 {
+		return nil, errors.New("sheet size not set")
+	}
+	if q.Columns < 1 || q.Rows < 1 {
+		return nil, errors.New("columns and rows must be at least 1")
+	}
+	if q.Rotate%90 != 0 {
+		return nil, errors.New("rotation must be a multiple of 90 degrees")
+	}
+	cellW, cellH := q.cellSize()
+	if cellW <= 0 || cellH <= 0 {
+		return nil, errors.New("margin and gutters exceed sheet size")
+	}
+
+	cellsPerSheet := q.Columns * q.Rows
+	var sheets [][]*Form
+	switch q.Order {
+	case ImpositionOrderSequential:
+		for i := 0; i < len(forms); i += cellsPerSheet {
+			cells := make([]*Form, cellsPerSheet)
+			copy(cells, forms[i:])
+			sheets = append(sheets, cells)
+		}
+
+	case ImpositionOrderBooklet:
+		if cellsPerSheet != 2 {
+			return nil, errors.New("booklets require exactly two cells per sheet")
+		}
+		n := (len(forms) + 3) / 4 * 4
+		get := func(i int) *Form {
+			if i < len(forms) {
+				return forms[i]
+			}
+			return nil
+		}
+		for i := 0; i < n/2; i += 2 {
+			sheets = append(sheets,
+				[]*Form{get(n - 1 - i), get(i)},     // front side
+				[]*Form{get(i + 1), get(n - 2 - i)}, // back side
+			)
+		}
+
+	case ImpositionOrderStepAndRepeat:
+		for _, f := range forms {
+			cells := make([]*Form, cellsPerSheet)
+			for i := range cells {
+				cells[i] = f
+			}
+			sheets = append(sheets, cells)
+		}
+
+	default:
+		return nil, errors.New("unknown imposition order")
+	}
+	return sheets, nil
+}
+
+// cellSize returns the width and height of one cell in pt
+func (q *Imposition) cellSize() (float64, float64) {
+	w := (q.SheetSize[0].Pt() - 2*q.Margin.Pt() - float64(q.Columns-1)*q.GutterX.Pt()) / float64(q.Columns)
+	h := (q.SheetSize[1].Pt() - 2*q.Margin.Pt() - float64(q.Rows-1)*q.GutterY.Pt()) / float64(q.Rows)
+	return w, h
+}
+
+// place returns the element drawing the form in the cell with the given index
+func (q *Imposition) place(form *Form, cell int) *imposedForm {
+	cellW, cellH := q.cellSize()
+	cellLeft := q.Margin.Pt() + float64(cell%q.Columns)*(cellW+q.GutterX.Pt())
+	cellTop := q.Margin.Pt() + float64(cell/q.Columns)*(cellH+q.GutterY.Pt())
+
+	// size of the rotated page
+	rotate := (q.Rotate%360 + 360) % 360
+	w := float64(form.BBox.URX - form.BBox.LLX)
+	h := float64(form.BBox.URY - form.BBox.LLY)
+	rw, rh := w, h
+	if rotate == 90 || rotate == 270 {
+		rw, rh = h, w
+	}
+	scale := 1.0
+	if q.FitToCell && rw > 0 && rh > 0 {
+		scale = math.Min(cellW/rw, cellH/rh)
+	}
+
+	return &imposedForm{
+		Form:       form,
+		Left:       cellLeft + (cellW-rw*scale)/2,
+		Top:        cellTop + (cellH-rh*scale)/2,
+		Width:      rw * scale,
+		Height:     rh * scale,
+		Scale:      scale,
+		Rotate:     rotate,
+		imposition: q,
+	}
+}
+
+// imposedForm draws a form rotated and scaled into a cell of a sheet
+type imposedForm struct {
+	Form                     *Form
+	Left, Top, Width, Height float64
+	Scale                    float64
+	Rotate                   int
+	imposition               *Imposition
+}
+
+// Build adds the element to the content stream
+func (q *imposedForm) Build(page *pdf.Page) (string, error) {
+	// lower left corner of the placed page
+	x := q.Left
+	y := float64(page.Data.MediaBox.URY) - q.Top - q.Height
+
+	// transformation: move bounding box to origin, scale, rotate and move to the target position
+	r := float64(q.Rotate) * math.Pi / 180
+	cos, sin := math.Round(math.Cos(r)), math.Round(math.Sin(r))
+	a, b, c, d := cos*q.Scale, sin*q.Scale, -sin*q.Scale, cos*q.Scale
+	var offX, offY float64
+	w := float64(q.Form.BBox.URX-q.Form.BBox.LLX) * q.Scale
+	h := float64(q.Form.BBox.URY-q.Form.BBox.LLY) * q.Scale
+	switch q.Rotate {
+	case 90:
+		offX = h
+	case 180:
+		offX, offY = w, h
+	case 270:
+		offY = w
+	}
+	llx, lly := float64(q.Form.BBox.LLX), float64(q.Form.BBox.LLY)
+	e := -a*llx - c*lly + offX + x
+	f := -b*llx - d*lly + offY + y
+
+	page.GraphicsState_q()
+	page.GraphicsState_cm(a, b, c, d, e, f)
+	page.XObject_Do(q.Form.Form)
+	page.GraphicsState_Q()
+
+	// crop marks
+	if q.imposition.CropMarks {
+		q.drawCropMarks(page, x, y)
+	}
+	return "", nil
+}
+
+// drawCropMarks draws crop marks at the corners of the placed page
+func (q *imposedForm) drawCropMarks(page *pdf.Page, x, y float64) {
+	length := q.imposition.CropMarkLength.Pt()
+	if length == 0 {
+		length = MM(5).Pt()
+	}
+	offset := q.imposition.CropMarkOffset.Pt()
+	if offset == 0 {
+		offset = MM(3).Pt()
+	}
+	lineWidth := q.imposition.CropMarkWidth.Pt()
+	if lineWidth == 0 {
+		lineWidth = 0.25
+	}
+
+	page.GraphicsState_q()
+	defer page.GraphicsState_Q()
+	if q.imposition.CropMarkColor == nil {
+		ColorCMYK{C: 100, M: 100, Y: 100, K: 100}.Build(page, true)
+	} else {
+		q.imposition.CropMarkColor.Build(page, true)
+	}
+	page.GraphicsState_w(lineWidth)
+	for _, corner := range [][4]float64{
+		{x, y, -1, -1},
+		{x + q.Width, y, 1, -1},
+		{x, y + q.Height, -1, 1},
+		{x + q.Width, y + q.Height, 1, 1},
+	} {
+		cx, cy, dx, dy := corner[0], corner[1], corner[2], corner[3]
+		page.Path_m(cx+dx*offset, cy)
+		page.Path_l(cx+dx*(offset+length), cy)
+		page.Path_m(cx, cy+dy*offset)
+		page.Path_l(cx, cy+dy*(offset+length))
+	}
+	page.Path_S()
+}
@@ -0,0 +1,112 @@
+package gopdf
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/raceresult/gopdf/types"
+)
+
+func TestImpositionBooklet(t *testing.T) {
+	imposition := Imposition{
+		SheetSize: GetStandardPageSize(PageSizeA4, true),
+		Columns:   2,
+		Rows:      1,
+		Order:     ImpositionOrderBooklet,
+	}
+	tests := []struct {
+		pages int
+		want  [][2]int // page numbers per sheet side, 0 for blank
+	}{
+		{8, [][2]int{{8, 1}, {2, 7}, {6, 3}, {4, 5}}},
+		{5, [][2]int{{0, 1}, {2, 0}, {0, 3}, {4, 5}}},
+		{1, [][2]int{{0, 1}, {0, 0}}},
+	}
+	for _, tt := range tests {
+		forms := make([]*Form, tt.pages)
+		pageNo := make(map[*Form]int)
+		for i := range forms {
+			forms[i] = &Form{}
+			pageNo[forms[i]] = i + 1
+		}
+		sheets, err := imposition.arrange(forms)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sheets) != len(tt.want) {
+			t.Errorf("%d pages: got %d sheet sides, want %d", tt.pages, len(sheets), len(tt.want))
+			continue
+		}
+		for i, cells := range sheets {
+			if got := [2]int{pageNo[cells[0]], pageNo[cells[1]]}; got != tt.want[i] {
+				t.Errorf("%d pages, side %d: got pages %v, want %v", tt.pages, i+1, got, tt.want[i])
+			}
+		}
+	}
+
+	imposition.Columns = 2
+	imposition.Rows = 2
+	if _, err := imposition.arrange([]*Form{{}}); err == nil {
+		t.Error("expected error for booklet with four cells per sheet")
+	}
+}
+
+func TestImpositionRotation(t *testing.T) {
+	// the page is placed centered in the second cell of the sheet: 100x50 pt, rotated 50x100 pt
+	imposition := Imposition{
+		SheetSize: PageSize{Pt(400), Pt(300)},
+		Columns:   2,
+		Rows:      1,
+		Margin:    Pt(10),
+		GutterX:   Pt(20),
+	}
+	form := &Form{BBox: types.Rectangle{LLX: 10, LLY: 20, URX: 110, URY: 70}}
+	cm := regexp.MustCompile(`([-0-9.]+) ([-0-9.]+) ([-0-9.]+) ([-0-9.]+) ([-0-9.]+) ([-0-9.]+) cm\n`)
+	tests := []struct {
+		rotate int
+		rect   [4]float64 // placed page: llx, lly, urx, ury
+		origin [2]float64 // position of the lower left corner of the page
+	}{
+		{0, [4]float64{250, 125, 350, 175}, [2]float64{250, 125}},
+		{90, [4]float64{275, 100, 325, 200}, [2]float64{325, 100}},
+		{180, [4]float64{250, 125, 350, 175}, [2]float64{350, 175}},
+		{270, [4]float64{275, 100, 325, 200}, [2]float64{275, 200}},
+		{-90, [4]float64{275, 100, 325, 200}, [2]float64{275, 200}},
+	}
+	for _, tt := range tests {
+		imposition.Rotate = tt.rotate
+		b := New()
+		b.CompressStreamsThreshold = math.MaxInt32
+		b.NewPage(imposition.SheetSize)
+		b.AddElement(imposition.place(form, 1))
+		bts, err := b.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := cm.FindStringSubmatch(string(bts))
+		if m == nil {
+			t.Fatalf("rotate %d: cm operator not found", tt.rotate)
+		}
+		var v [6]float64
+		for i := range v {
+			v[i], _ = strconv.ParseFloat(m[i+1], 64)
+		}
+		transform := func(x, y float64) (float64, float64) {
+			return v[0]*x + v[2]*y + v[4], v[1]*x + v[3]*y + v[5]
+		}
+
+		rect := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+		for _, corner := range [][2]float64{{10, 20}, {110, 20}, {10, 70}, {110, 70}} {
+			x, y := transform(corner[0], corner[1])
+			rect = [4]float64{math.Min(rect[0], x), math.Min(rect[1], y), math.Max(rect[2], x), math.Max(rect[3], y)}
+		}
+		if rect != tt.rect {
+			t.Errorf("rotate %d: page placed at %v, want %v", tt.rotate, rect, tt.rect)
+		}
+		if x, y := transform(10, 20); [2]float64{x, y} != tt.origin {
+			t.Errorf("rotate %d: lower left corner at %v, want %v", tt.rotate, [2]float64{x, y}, tt.origin)
+		}
+	}
+}
@@ -12,6 +12,9 @@ type Page struct {
 	Rotate int
 
 	elements []Element
+
+	// page imported from another pdf, used instead of a new empty page
+	imported *pdf.Page
 }
 
 // NewPage creates a new Page object with the given page size
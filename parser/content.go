@@ -0,0 +1,212 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+
+	"github.com/raceresult/gopdf/types"
+)
+
+// contentOperation is an operator of a content stream together with its operands
+type contentOperation struct {
+	Operator string
+	Operands []types.Object
+
+	// image dictionary and data of inline images (operator BI)
+	InlineDict types.Dictionary
+	InlineData []byte
+}
+
+// readContentStream splits a decoded content stream into operations
+func readContentStream(bts []byte) ([]contentOperation, error) {
+	var res []contentOperation
+	var operands []types.Object
+	for {
+		bts = trimLeftWhiteChars(bts)
+		if len(bts) == 0 {
+			return res, nil
+		}
+
+		// operands
+		switch bts[0] {
+		case '/', '(', '<', '[':
+			v, rest, err := readAny(bts)
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, v)
+			bts = rest
+			continue
+		case '%':
+			_, bts, _ = readComment(bts)
+			continue
+		case ']', ')', '>', '{', '}':
+			return nil, errors.New("unexpected delimiter in content stream")
+		}
+		w, rest := readWord(bts)
+		if len(w) == 0 {
+			return nil, errors.New("unexpected character in content stream")
+		}
+		if isNumericOperand(w) {
+			v, err := strconv.ParseFloat(string(w), 64)
+			if err != nil {
+				return nil, err
+			}
+			if bytes.IndexByte(w, '.') < 0 {
+				operands = append(operands, types.Int(v))
+			} else {
+				operands = append(operands, types.Number(v))
+			}
+			bts = rest
+			continue
+		}
+		switch string(w) {
+		case "true", "false":
+			operands = append(operands, types.Boolean(string(w) == "true"))
+			bts = rest
+			continue
+		case "null":
+			operands = append(operands, types.Null{})
+			bts = rest
+			continue
+		}
+
+		// operator
+		bts = rest
+		op := contentOperation{Operator: string(w), Operands: operands}
+		operands = nil
+		if op.Operator == "BI" {
+			var err error
+			op.InlineDict, op.InlineData, bts, err = readInlineImage(bts)
+			if err != nil {
+				return nil, err
+			}
+		}
+		res = append(res, op)
+	}
+}
+
+// isNumericOperand checks if the given word is a number, at least one digit is required
+func isNumericOperand(w []byte) bool {
+	var digits bool
+	for i, c := range w {
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case c == '.':
+		case (c == '-' || c == '+') && i == 0:
+		default:
+			return false
+		}
+	}
+	return digits
+}
+
+// readInlineImage reads the dictionary and data of an inline image following the BI operator
+func readInlineImage(bts []byte) (types.Dictionary, []byte, []byte, error) {
+	// dictionary until ID
+	dict := types.Dictionary{}
+	for {
+		bts = trimLeftWhiteChars(bts)
+		if bytes.HasPrefix(bts, []byte("ID")) && (len(bts) == 2 || isWhiteChar(bts[2])) {
+			bts = bts[2:]
+			break
+		}
+		key, rest, err := readName(bts)
+		if err != nil {
+			return nil, nil, bts, errors.New("inline image dictionary invalid")
+		}
+		value, rest, err := readAny(rest)
+		if err != nil {
+			// values like /CS /RGB are names, everything else is handled by readAny
+			return nil, nil, bts, errors.New("inline image dictionary invalid")
+		}
+		dict[key] = value
+		bts = rest
+	}
+
+	// a single white-space character follows ID
+	if len(bts) != 0 {
+		bts = bts[1:]
+	}
+
+	// data of known length: given by /L or computed for unfiltered images
+	if n, ok := inlineImageLength(dict); ok && n <= len(bts) {
+		rest := trimLeftWhiteChars(bts[n:])
+		if bytes.HasPrefix(rest, []byte("EI")) && (len(rest) == 2 || isWhiteChar(rest[2]) || isDelimiterChar(rest[2])) {
+			return dict, bts[:n], rest[2:], nil
+		}
+	}
+
+	// otherwise data until EI, which must be surrounded by white-space
+	for i := 0; i+2 <= len(bts); i++ {
+		if bts[i] != 'E' || bts[i+1] != 'I' {
+			continue
+		}
+		if i > 0 && !isWhiteChar(bts[i-1]) {
+			continue
+		}
+		if i+2 < len(bts) && !isWhiteChar(bts[i+2]) {
+			continue
+		}
+		end := i
+		if end > 0 {
+			end--
+		}
+		return dict, bts[:end], bts[i+2:], nil
+	}
+	return nil, nil, bts, errors.New("end of inline image not found")
+}
+
+// inlineImageLength returns the length of the data of an inline image: the value of /L if given, otherwise the size
+// of the samples if the data is not filtered and the color space is a device color space
+func inlineImageLength(dict types.Dictionary) (int, bool) {
+	get := func(short, long types.Name) types.Object {
+		if v, ok := dict[short]; ok {
+			return v
+		}
+		return dict[long]
+	}
+	if l, ok := get("L", "Length").(types.Int); ok && l >= 0 {
+		return int(l), true
+	}
+	if get("F", "Filter") != nil {
+		return 0, false
+	}
+
+	width, ok1 := get("W", "Width").(types.Int)
+	height, ok2 := get("H", "Height").(types.Int)
+	if !ok1 || !ok2 || width <= 0 || height <= 0 {
+		return 0, false
+	}
+	bpc, components := 1, 1
+	if mask, _ := get("IM", "ImageMask").(types.Boolean); !mask {
+		v, ok := get("BPC", "BitsPerComponent").(types.Int)
+		if !ok {
+			return 0, false
+		}
+		bpc = int(v)
+
+		switch cs := get("CS", "ColorSpace").(type) {
+		case types.Name:
+			switch cs {
+			case "G", "DeviceGray":
+			case "RGB", "DeviceRGB":
+				components = 3
+			case "CMYK", "DeviceCMYK":
+				components = 4
+			default:
+				return 0, false
+			}
+		case types.Array:
+			// indexed color space: one index per pixel
+			if len(cs) == 0 || (cs[0] != types.Name("I") && cs[0] != types.Name("Indexed")) {
+				return 0, false
+			}
+		default:
+			return 0, false
+		}
+	}
+	return int(height) * ((int(width)*components*bpc + 7) / 8), true
+}
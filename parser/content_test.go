@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadContentStreamInlineImage(t *testing.T) {
+	// sample data containing " EI " must not end the image
+	data := []byte{0x00, ' ', 'E', 'I', ' ', 0xff}
+	for _, c := range []struct {
+		name string
+		dict string
+	}{
+		{"length", "/W 3 /H 1 /BPC 8 /CS /G /F /AHx /L 6"},
+		{"unfiltered", "/W 2 /H 1 /BPC 8 /CS /RGB"},
+		{"indexed", "/W 3 /H 2 /BPC 8 /CS [/I /RGB 1 <000000ffffff>]"},
+	} {
+		content := append([]byte("q BI "+c.dict+" ID "), data...)
+		content = append(content, []byte("\nEI Q")...)
+		ops, err := readContentStream(content)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if len(ops) != 3 || ops[1].Operator != "BI" || ops[2].Operator != "Q" {
+			t.Errorf("%s: unexpected operations %v", c.name, ops)
+			continue
+		}
+		if !bytes.Equal(ops[1].InlineData, data) {
+			t.Errorf("%s: inline data %v, expected %v", c.name, ops[1].InlineData, data)
+		}
+	}
+
+	// without known length, the data ends at the first EI surrounded by white-space
+	ops, err := readContentStream([]byte("BI /W 1 /H 1 /CS /CS0 /BPC 8 ID x EI Q"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 || string(ops[0].InlineData) != "x" {
+		t.Errorf("unexpected operations %v", ops)
+	}
+}
+
+func TestReadContentStreamNumbers(t *testing.T) {
+	ops, err := readContentStream([]byte("1 -2 +3.5 .5 - . cm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 3 || ops[0].Operator != "-" || ops[1].Operator != "." || ops[2].Operator != "cm" {
+		t.Fatalf("unexpected operations %v", ops)
+	}
+	if len(ops[0].Operands) != 4 {
+		t.Errorf("expected 4 numeric operands, got %v", ops[0].Operands)
+	}
+}
@@ -0,0 +1,769 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+
+	"github.com/raceresult/gopdf/pdffile"
+	"github.com/raceresult/gopdf/types"
+)
+
+// PageImage is an image drawn on a page, either an image XObject or an inline image
+type PageImage struct {
+	// resource name and reference of the image XObject, empty for inline images
+	Name      types.Name
+	Reference types.Reference
+	Inline    bool
+
+	// transformation matrix [a b c d e f] mapping the unit square of the image to default user space of the page
+	Matrix [6]float64
+
+	// image dictionary and raw (encoded) data
+	Image types.Image
+
+	// internals
+	dict      types.Dictionary
+	resources types.Dictionary
+	file      *pdffile.File
+}
+
+// GetPageImages returns all images drawn on the given page (first page = 1), including images in form XObjects and
+// inline images
+func (q *Parser) GetPageImages(pageNo int) ([]PageImage, error) {
+	page, err := q.GetPage(pageNo)
+	if err != nil {
+		return nil, err
+	}
+
+	// collect content
+	var data []byte
+	var addContent func(obj types.Object) error
+	addContent = func(obj types.Object) error {
+		obj, err := q.file.ResolveReference(obj)
+		if err != nil {
+			return err
+		}
+		switch item := obj.(type) {
+		case nil:
+			return nil
+		case types.Array:
+			for _, v := range item {
+				if err := addContent(v); err != nil {
+					return err
+				}
+			}
+			return nil
+		case types.StreamObject:
+			decoded, err := item.Decode(q.file)
+			if err != nil {
+				return errors.New("error decoding stream: " + err.Error())
+			}
+			data = append(data, decoded...)
+			data = append(data, '\n')
+			return nil
+		default:
+			return errors.New("content stream has unexpected type")
+		}
+	}
+	if err := addContent(page.Contents); err != nil {
+		return nil, err
+	}
+
+	resources, _ := q.resolveDictionary(page.Resources)
+	var res []PageImage
+	err = q.collectImages(data, resources, [6]float64{1, 0, 0, 1, 0, 0}, 0, &res)
+	return res, err
+}
+
+// collectImages interprets the content stream and adds all images found to res
+func (q *Parser) collectImages(content []byte, resources types.Dictionary, ctm [6]float64, depth int, res *[]PageImage) error {
+	if depth > 16 {
+		return errors.New("form XObjects nested too deeply")
+	}
+	ops, err := readContentStream(content)
+	if err != nil {
+		return err
+	}
+	xobjects, _ := q.resolveDictionary(resources["XObject"])
+
+	var stack [][6]float64
+	for _, op := range ops {
+		switch op.Operator {
+		case "q":
+			stack = append(stack, ctm)
+
+		case "Q":
+			if len(stack) != 0 {
+				ctm = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+
+		case "cm":
+			m, ok := readMatrix(op.Operands)
+			if ok {
+				ctm = multiplyMatrix(m, ctm)
+			}
+
+		case "BI":
+			dict := expandInlineImageDict(op.InlineDict)
+			var img types.Image
+			if err := img.Read(dict, q.file); err != nil {
+				continue
+			}
+			img.Stream = op.InlineData
+			*res = append(*res, PageImage{
+				Inline:    true,
+				Matrix:    ctm,
+				Image:     img,
+				dict:      dict,
+				resources: resources,
+				file:      q.file,
+			})
+
+		case "Do":
+			if len(op.Operands) != 1 {
+				continue
+			}
+			name, ok := op.Operands[0].(types.Name)
+			if !ok {
+				continue
+			}
+			ref, _ := xobjects[name].(types.Reference)
+			obj, err := q.file.ResolveReference(xobjects[name])
+			if err != nil {
+				continue
+			}
+			stream, ok := obj.(types.StreamObject)
+			if !ok {
+				continue
+			}
+			dict, ok := stream.Dictionary.(types.Dictionary)
+			if !ok {
+				continue
+			}
+
+			switch dict["Subtype"] {
+			case types.Name("Image"):
+				var img types.Image
+				if err := img.Read(dict, q.file); err != nil {
+					continue
+				}
+				img.Stream = stream.Stream
+				*res = append(*res, PageImage{
+					Name:      name,
+					Reference: ref,
+					Matrix:    ctm,
+					Image:     img,
+					dict:      dict,
+					resources: resources,
+					file:      q.file,
+				})
+
+			case types.Name("Form"):
+				formCTM := ctm
+				if mObj, ok := dict.GetValue("Matrix", q.file); ok {
+					if arr, ok := mObj.(types.Array); ok {
+						if m, ok := readMatrix(arr); ok {
+							formCTM = multiplyMatrix(m, ctm)
+						}
+					}
+				}
+				formResources := resources
+				if r, ok := q.resolveDictionary(dict["Resources"]); ok {
+					formResources = r
+				}
+				decoded, err := stream.Decode(q.file)
+				if err != nil {
+					continue
+				}
+				if err := q.collectImages(decoded, formResources, formCTM, depth+1, res); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolveDictionary resolves the given object and returns it if it is a dictionary
+func (q *Parser) resolveDictionary(obj types.Object) (types.Dictionary, bool) {
+	obj, err := q.file.ResolveReference(obj)
+	if err != nil {
+		return nil, false
+	}
+	d, ok := obj.(types.Dictionary)
+	return d, ok
+}
+
+// readMatrix converts the six operands of cm or a Matrix array into a matrix
+func readMatrix(arr []types.Object) ([6]float64, bool) {
+	var m [6]float64
+	if len(arr) != 6 {
+		return m, false
+	}
+	for i, v := range arr {
+		f, ok := toFloat(v)
+		if !ok {
+			return m, false
+		}
+		m[i] = f
+	}
+	return m, true
+}
+
+// multiplyMatrix returns m1 x m2
+func multiplyMatrix(m1, m2 [6]float64) [6]float64 {
+	return [6]float64{
+		m1[0]*m2[0] + m1[1]*m2[2],
+		m1[0]*m2[1] + m1[1]*m2[3],
+		m1[2]*m2[0] + m1[3]*m2[2],
+		m1[2]*m2[1] + m1[3]*m2[3],
+		m1[4]*m2[0] + m1[5]*m2[2] + m2[4],
+		m1[4]*m2[1] + m1[5]*m2[3] + m2[5],
+	}
+}
+
+// toFloat converts Int and Number objects to float64
+func toFloat(v types.Object) (float64, bool) {
+	switch n := v.(type) {
+	case types.Int:
+		return float64(n), true
+	case types.Number:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// expandInlineImageDict replaces the abbreviations used in inline image dictionaries by the full names
+func expandInlineImageDict(dict types.Dictionary) types.Dictionary {
+	keys := map[types.Name]types.Name{
+		"BPC": "BitsPerComponent", "CS": "ColorSpace", "D": "Decode", "DP": "DecodeParms", "F": "Filter",
+		"H": "Height", "IM": "ImageMask", "I": "Interpolate", "W": "Width", "L": "Length",
+	}
+	values := map[types.Name]types.Name{
+		"G": "DeviceGray", "RGB": "DeviceRGB", "CMYK": "DeviceCMYK", "I": "Indexed",
+		"AHx": "ASCIIHexDecode", "A85": "ASCII85Decode", "LZW": "LZWDecode", "Fl": "FlateDecode",
+		"RL": "RunLengthDecode", "CCF": "CCITTFaxDecode", "DCT": "DCTDecode",
+	}
+	var expand func(v types.Object) types.Object
+	expand = func(v types.Object) types.Object {
+		switch x := v.(type) {
+		case types.Name:
+			if n, ok := values[x]; ok {
+				return n
+			}
+		case types.Array:
+			c := make(types.Array, len(x))
+			for i, item := range x {
+				c[i] = expand(item)
+			}
+			return c
+		}
+		return v
+	}
+
+	res := types.Dictionary{"Type": types.Name("XObject"), "Subtype": types.Name("Image")}
+	for k, v := range dict {
+		if n, ok := keys[k]; ok {
+			k = n
+		}
+		if k == "ColorSpace" || k == "Filter" {
+			v = expand(v)
+		}
+		res[k] = v
+	}
+	return res
+}
+
+// filters returns the filters of the image
+func (q *PageImage) filters() []types.Name {
+	obj, _ := q.file.ResolveReference(q.dict["Filter"])
+	switch v := obj.(type) {
+	case types.Name:
+		return []types.Name{v}
+	case types.Array:
+		var res []types.Name
+		for _, item := range v {
+			if n, ok := item.(types.Name); ok {
+				res = append(res, n)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// JPEG returns the JPEG data if the image is DCT encoded
+func (q *PageImage) JPEG() ([]byte, bool) {
+	ff := q.filters()
+	if len(ff) == 0 || ff[len(ff)-1] != types.Name(types.Filter_DCTDecode) {
+		return nil, false
+	}
+	data, err := q.decodeStream(len(ff) - 1)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// decodeStream applies the first n filters to the image data
+func (q *PageImage) decodeStream(n int) ([]byte, error) {
+	ff := q.filters()
+	dict := make(types.Dictionary, len(q.dict))
+	for k, v := range q.dict {
+		dict[k] = v
+	}
+	if n < len(ff) {
+		arr := make(types.Array, 0, n)
+		for _, f := range ff[:n] {
+			arr = append(arr, f)
+		}
+		dict["Filter"] = arr
+		if dp, ok := dict["DecodeParms"].(types.Array); ok && len(dp) > n {
+			dict["DecodeParms"] = dp[:n]
+		}
+	}
+	stream := types.StreamObject{Dictionary: dict, Stream: q.Image.Stream}
+	return stream.Decode(q.file)
+}
+
+// Decode decodes the image to a Go image. Supported are the color spaces DeviceGray, DeviceRGB, DeviceCMYK,
+// CalGray, CalRGB, ICCBased and Indexed with 1, 2, 4, 8 or 16 bits per component, Decode arrays, image masks and
+// soft masks (SMask). DCT encoded images are decoded by image/jpeg, the Decode array applies to them as well, e.g.
+// [1 0 1 0 1 0 1 0] for the inverted samples of Adobe CMYK JPEGs.
+func (q *PageImage) Decode() (image.Image, error) {
+	img, err := q.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	// soft mask
+	if q.Image.SMask == nil {
+		return img, nil
+	}
+	obj, err := q.file.ResolveReference(q.Image.SMask)
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := obj.(types.StreamObject)
+	if !ok {
+		return img, nil
+	}
+	dict, ok := stream.Dictionary.(types.Dictionary)
+	if !ok {
+		return img, nil
+	}
+	mask := PageImage{dict: dict, resources: q.resources, file: q.file}
+	if err := mask.Image.Read(dict, q.file); err != nil {
+		return nil, err
+	}
+	mask.Image.Stream = stream.Stream
+	alpha, err := mask.decode()
+	if err != nil {
+		return nil, err
+	}
+	return applyAlpha(img, alpha), nil
+}
+
+// decode decodes the image without soft mask
+func (q *PageImage) decode() (image.Image, error) {
+	// DCT encoded images
+	if data, ok := q.JPEG(); ok {
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return q.applyJPEGDecode(img), nil
+	}
+
+	width, height := int(q.Image.Width), int(q.Image.Height)
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("invalid image size")
+	}
+	data, err := q.decodeStream(len(q.filters()))
+	if err != nil {
+		return nil, err
+	}
+
+	// image masks
+	if q.Image.ImageMask {
+		decode := q.decodeArray(1, 1)
+		img := image.NewNRGBA(image.Rect(0, 0, width, height))
+		r := newSampleReader(data, width, 1, 1)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				v := decode[0] + float64(r.read(x, y, 0))*(decode[1]-decode[0])
+				if v < 0.5 {
+					img.SetNRGBA(x, y, color.NRGBA{A: 255})
+				}
+			}
+		}
+		return img, nil
+	}
+
+	// color space
+	cs, err := q.readColorSpace(q.Image.ColorSpace, 0)
+	if err != nil {
+		return nil, err
+	}
+	bpc := int(q.Image.BitsPerComponent)
+	switch bpc {
+	case 1, 2, 4, 8, 16:
+	default:
+		return nil, errors.New("unsupported number of bits per component")
+	}
+	maxValue := float64(int(1)<<bpc - 1)
+	decode := q.decodeArray(cs.components, cs.decodeMax(maxValue))
+	r := newSampleReader(data, width, cs.components, bpc)
+
+	var img image.Image
+	switch {
+	case cs.palette != nil:
+		pImg := image.NewNRGBA(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				idx := int(math.Round(decode[0] + float64(r.read(x, y, 0))*(decode[1]-decode[0])/maxValue))
+				if idx < 0 {
+					idx = 0
+				}
+				if idx >= len(cs.palette) {
+					idx = len(cs.palette) - 1
+				}
+				pImg.Set(x, y, cs.palette[idx])
+			}
+		}
+		img = pImg
+
+	case cs.components == 1:
+		gImg := image.NewGray(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				gImg.Pix[y*gImg.Stride+x] = scaleSample(r.read(x, y, 0), decode[0], decode[1], maxValue)
+			}
+		}
+		img = gImg
+
+	case cs.components == 3:
+		rgbImg := image.NewNRGBA(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				o := y*rgbImg.Stride + x*4
+				for c := 0; c < 3; c++ {
+					rgbImg.Pix[o+c] = scaleSample(r.read(x, y, c), decode[2*c], decode[2*c+1], maxValue)
+				}
+				rgbImg.Pix[o+3] = 255
+			}
+		}
+		img = rgbImg
+
+	case cs.components == 4:
+		cmykImg := image.NewCMYK(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				o := y*cmykImg.Stride + x*4
+				for c := 0; c < 4; c++ {
+					cmykImg.Pix[o+c] = scaleSample(r.read(x, y, c), decode[2*c], decode[2*c+1], maxValue)
+				}
+			}
+		}
+		img = cmykImg
+
+	default:
+		return nil, errors.New("unsupported color space")
+	}
+	return img, nil
+}
+
+// applyJPEGDecode applies the Decode array to an image decoded by image/jpeg. Like DCTDecode, the samples of CMYK
+// images are the values stored in the file: image/jpeg inverts the samples of CMYK JPEGs, which Adobe applications
+// store inverted, so they are inverted back and corrected by the Decode array [1 0 1 0 1 0 1 0] of such images.
+func (q *PageImage) applyJPEGDecode(img image.Image) image.Image {
+	switch v := img.(type) {
+	case *image.Gray:
+		decode := q.decodeArray(1, 1)
+		if isDefaultDecode(decode) {
+			return img
+		}
+		for i, p := range v.Pix {
+			v.Pix[i] = scaleSample(int(p), decode[0], decode[1], 255)
+		}
+		return v
+
+	case *image.CMYK:
+		decode := q.decodeArray(4, 1)
+		for y := 0; y < v.Rect.Dy(); y++ {
+			row := v.Pix[y*v.Stride : y*v.Stride+4*v.Rect.Dx()]
+			for i, p := range row {
+				c := i % 4
+				row[i] = scaleSample(255-int(p), decode[2*c], decode[2*c+1], 255)
+			}
+		}
+		return v
+
+	default:
+		decode := q.decodeArray(3, 1)
+		if isDefaultDecode(decode) {
+			return img
+		}
+		b := img.Bounds()
+		rgbImg := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+				o := y*rgbImg.Stride + x*4
+				for i, p := range [3]uint8{c.R, c.G, c.B} {
+					rgbImg.Pix[o+i] = scaleSample(int(p), decode[2*i], decode[2*i+1], 255)
+				}
+				rgbImg.Pix[o+3] = 255
+			}
+		}
+		return rgbImg
+	}
+}
+
+// isDefaultDecode checks if the Decode array maps the samples to the range 0 to 1 without changes
+func isDefaultDecode(decode []float64) bool {
+	for i := 0; i < len(decode); i += 2 {
+		if decode[i] != 0 || decode[i+1] != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeArray returns the Decode array of the image or the default array
+func (q *PageImage) decodeArray(components int, max float64) []float64 {
+	res := make([]float64, 0, 2*components)
+	if len(q.Image.Decode) == 2*components {
+		for _, v := range q.Image.Decode {
+			f, _ := toFloat(v)
+			res = append(res, f)
+		}
+		return res
+	}
+	for i := 0; i < components; i++ {
+		res = append(res, 0, max)
+	}
+	return res
+}
+
+// imageColorSpace describes the color space of an image
+type imageColorSpace struct {
+	components int
+	palette    []color.Color
+}
+
+// decodeMax returns the maximum value of the default decode array
+func (q imageColorSpace) decodeMax(maxValue float64) float64 {
+	if q.palette != nil {
+		return maxValue
+	}
+	return 1
+}
+
+// readColorSpace determines the number of components and the palette of indexed color spaces
+func (q *PageImage) readColorSpace(obj types.Object, depth int) (imageColorSpace, error) {
+	if depth > 8 {
+		return imageColorSpace{}, errors.New("color space nested too deeply")
+	}
+	obj, err := q.file.ResolveReference(obj)
+	if err != nil {
+		return imageColorSpace{}, err
+	}
+
+	switch v := obj.(type) {
+	case types.Name:
+		switch v {
+		case "DeviceGray", "CalGray", "G":
+			return imageColorSpace{components: 1}, nil
+		case "DeviceRGB", "CalRGB", "RGB":
+			return imageColorSpace{components: 3}, nil
+		case "DeviceCMYK", "CMYK":
+			return imageColorSpace{components: 4}, nil
+		}
+
+		// named color space in resources (inline images)
+		if csDict, ok := q.resolveResources("ColorSpace"); ok {
+			if cs, ok := csDict[v]; ok {
+				return q.readColorSpace(cs, depth+1)
+			}
+		}
+		return imageColorSpace{}, errors.New("unsupported color space " + string(v))
+
+	case types.Array:
+		if len(v) == 0 {
+			return imageColorSpace{}, errors.New("color space invalid")
+		}
+		family, _ := v[0].(types.Name)
+		switch family {
+		case "CalGray", "CalRGB":
+			return q.readColorSpace(family, depth+1)
+
+		case "ICCBased":
+			if len(v) < 2 {
+				return imageColorSpace{}, errors.New("color space invalid")
+			}
+			sObj, err := q.file.ResolveReference(v[1])
+			if err != nil {
+				return imageColorSpace{}, err
+			}
+			stream, ok := sObj.(types.StreamObject)
+			if !ok {
+				return imageColorSpace{}, errors.New("ICC profile invalid")
+			}
+			dict, _ := stream.Dictionary.(types.Dictionary)
+			n, _ := dict.GetValue("N", q.file)
+			switch n {
+			case types.Int(1), types.Int(3), types.Int(4):
+				return imageColorSpace{components: int(n.(types.Int))}, nil
+			}
+			if alt, ok := dict["Alternate"]; ok {
+				return q.readColorSpace(alt, depth+1)
+			}
+			return imageColorSpace{}, errors.New("ICC profile invalid")
+
+		case "Indexed", "I":
+			if len(v) != 4 {
+				return imageColorSpace{}, errors.New("color space invalid")
+			}
+			base, err := q.readColorSpace(v[1], depth+1)
+			if err != nil {
+				return imageColorSpace{}, err
+			}
+			if base.palette != nil {
+				return imageColorSpace{}, errors.New("color space invalid")
+			}
+			hival, _ := q.file.ResolveReference(v[2])
+			hi, ok := toFloat(hival)
+			if !ok {
+				return imageColorSpace{}, errors.New("color space invalid")
+			}
+			lookupObj, err := q.file.ResolveReference(v[3])
+			if err != nil {
+				return imageColorSpace{}, err
+			}
+			var lookup []byte
+			switch l := lookupObj.(type) {
+			case types.String:
+				lookup = []byte(l)
+			case types.StreamObject:
+				lookup, err = l.Decode(q.file)
+				if err != nil {
+					return imageColorSpace{}, err
+				}
+			default:
+				return imageColorSpace{}, errors.New("color space invalid")
+			}
+
+			palette := make([]color.Color, 0, int(hi)+1)
+			for i := 0; i <= int(hi); i++ {
+				o := i * base.components
+				if o+base.components > len(lookup) {
+					break
+				}
+				c := lookup[o : o+base.components]
+				switch base.components {
+				case 1:
+					palette = append(palette, color.Gray{Y: c[0]})
+				case 3:
+					palette = append(palette, color.NRGBA{R: c[0], G: c[1], B: c[2], A: 255})
+				case 4:
+					palette = append(palette, color.CMYK{C: c[0], M: c[1], Y: c[2], K: c[3]})
+				}
+			}
+			if len(palette) == 0 {
+				return imageColorSpace{}, errors.New("color space invalid")
+			}
+			return imageColorSpace{components: 1, palette: palette}, nil
+
+		default:
+			return imageColorSpace{}, errors.New("unsupported color space " + string(family))
+		}
+
+	default:
+		return imageColorSpace{}, errors.New("color space invalid")
+	}
+}
+
+// resolveResources returns the sub-dictionary of the resources with the given name
+func (q *PageImage) resolveResources(name types.Name) (types.Dictionary, bool) {
+	obj, err := q.file.ResolveReference(q.resources[name])
+	if err != nil {
+		return nil, false
+	}
+	d, ok := obj.(types.Dictionary)
+	return d, ok
+}
+
+// sampleReader reads samples from decoded image data, rows are padded to full bytes
+type sampleReader struct {
+	data       []byte
+	components int
+	bpc        int
+	rowLength  int
+}
+
+func newSampleReader(data []byte, width, components, bpc int) *sampleReader {
+	return &sampleReader{
+		data:       data,
+		components: components,
+		bpc:        bpc,
+		rowLength:  (width*components*bpc + 7) / 8,
+	}
+}
+
+// read returns the sample of component c of the pixel x/y
+func (q *sampleReader) read(x, y, c int) int {
+	bit := (x*q.components + c) * q.bpc
+	o := y*q.rowLength + bit/8
+	if o >= len(q.data) {
+		return 0
+	}
+	switch q.bpc {
+	case 8:
+		return int(q.data[o])
+	case 16:
+		if o+1 >= len(q.data) {
+			return 0
+		}
+		return int(q.data[o])<<8 | int(q.data[o+1])
+	default:
+		shift := 8 - q.bpc - bit%8
+		return int(q.data[o]>>shift) & (1<<q.bpc - 1)
+	}
+}
+
+// scaleSample maps a sample using the decode range to a byte value
+func scaleSample(v int, dMin, dMax, maxValue float64) byte {
+	f := dMin + float64(v)*(dMax-dMin)/maxValue
+	if f < 0 {
+		f = 0
+	}
+	if f > 1 {
+		f = 1
+	}
+	return byte(math.Round(f * 255))
+}
+
+// applyAlpha returns the image with the gray values of the mask as alpha channel. The mask is scaled to the image
+// size if needed.
+func applyAlpha(img image.Image, mask image.Image) image.Image {
+	b := img.Bounds()
+	mb := mask.Bounds()
+	res := image.NewNRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		my := mb.Min.Y + (y-b.Min.Y)*mb.Dy()/b.Dy()
+		for x := b.Min.X; x < b.Max.X; x++ {
+			mx := mb.Min.X + (x-b.Min.X)*mb.Dx()/b.Dx()
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			c.A = color.GrayModel.Convert(mask.At(mx, my)).(color.Gray).Y
+			res.SetNRGBA(x, y, c)
+		}
+	}
+	return res
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"os"
+	"testing"
+
+	"github.com/raceresult/gopdf/pdffile"
+	"github.com/raceresult/gopdf/types"
+)
+
+// jpegImage returns a DCT encoded CMYK image with the given Decode array
+func jpegImage(t *testing.T, data []byte, decode types.Array) *PageImage {
+	t.Helper()
+	conf, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := types.Dictionary{
+		"Type":             types.Name("XObject"),
+		"Subtype":          types.Name("Image"),
+		"Width":            types.Int(conf.Width),
+		"Height":           types.Int(conf.Height),
+		"ColorSpace":       types.Name("DeviceCMYK"),
+		"BitsPerComponent": types.Int(8),
+		"Filter":           types.Name("DCTDecode"),
+		"Length":           types.Int(len(data)),
+	}
+	if decode != nil {
+		dict["Decode"] = decode
+	}
+	file := pdffile.NewFile()
+	img := &PageImage{dict: dict, file: file}
+	if err := img.Image.Read(dict, file); err != nil {
+		t.Fatal(err)
+	}
+	img.Image.Stream = data
+	return img
+}
+
+func TestDecodeCMYKJPEG(t *testing.T) {
+	// CMYK JPEG with Adobe APP14 marker from the test data of the Go image package
+	data, err := os.ReadFile("testdata/cmyk.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, ok := src.(*image.CMYK)
+	if !ok {
+		t.Fatalf("test image decoded as %T", src)
+	}
+
+	decode := func(d types.Array) *image.CMYK {
+		img, err := jpegImage(t, data, d).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cmyk, ok := img.(*image.CMYK)
+		if !ok {
+			t.Fatalf("decoded as %T", img)
+		}
+		return cmyk
+	}
+
+	// the Decode array of Adobe CMYK JPEGs corrects the inverted samples
+	inverted := types.Array{types.Int(1), types.Int(0), types.Int(1), types.Int(0), types.Int(1), types.Int(0), types.Int(1), types.Int(0)}
+	if got := decode(inverted); !bytes.Equal(got.Pix, want.Pix) {
+		t.Error("Decode [1 0 1 0 1 0 1 0]: samples inverted twice")
+	}
+
+	// without Decode array, the samples are the values stored in the file
+	got := decode(nil)
+	for i := range got.Pix {
+		if got.Pix[i] != 255-want.Pix[i] {
+			t.Fatalf("no Decode array: sample %d is %d, want %d", i, got.Pix[i], 255-want.Pix[i])
+		}
+	}
+
+	// components are mapped separately: black only
+	got = decode(types.Array{types.Int(0), types.Int(0), types.Int(0), types.Int(0), types.Int(0), types.Int(0), types.Int(1), types.Int(0)})
+	for i := range got.Pix {
+		w := byte(0)
+		if i%4 == 3 {
+			w = want.Pix[i]
+		}
+		if got.Pix[i] != w {
+			t.Fatalf("Decode for black only: sample %d is %d, want %d", i, got.Pix[i], w)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+// Package cff parses fonts in the Compact Font Format (CFF), the outline format of OpenType fonts with PostScript
+// outlines, and subsets them for embedding in PDF files.
+// https://adobe-type-tools.github.io/font-tech-notes/pdfs/5176.CFF.pdf
+package cff
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Font is a parsed CFF font
+type Font struct {
+	names       [][]byte
+	topDict     dict
+	strings     [][]byte
+	globalSubrs [][]byte
+	charStrings [][]byte
+	charset     []byte // raw charset data, nil for predefined charsets
+	private     privateDict
+	fdArray     []fontDict // font dicts of CID-keyed fonts
+	fdSelect    []uint8    // font dict index of every glyph of CID-keyed fonts
+	scale       float64    // horizontal scale of the font matrix
+}
+
+// fontDict is a font dict of a CID-keyed font
+type fontDict struct {
+	dict    dict
+	private privateDict
+	scale   float64
+}
+
+// privateDict holds the private dict and local subroutines of a font or font dict
+type privateDict struct {
+	dict          dict
+	subrs         [][]byte
+	defaultWidthX float64
+	nominalWidthX float64
+}
+
+// top dict and private dict operators
+const (
+	opEncoding       = 16
+	opCharset        = 15
+	opCharStrings    = 17
+	opPrivate        = 18
+	opSubrs          = 19
+	opDefaultWidthX  = 20
+	opNominalWidthX  = 21
+	opCharstringType = 1206
+	opFontMatrix     = 1207
+	opROS            = 1230
+	opFDArray        = 1236
+	opFDSelect       = 1237
+)
+
+var errInvalid = errors.New("invalid CFF font")
+
+// ParseOpenType parses the 'CFF ' table of an OpenType font
+func ParseOpenType(otf []byte) (*Font, error) {
+	if len(otf) < 12 {
+		return nil, errInvalid
+	}
+	numTables := int(binary.BigEndian.Uint16(otf[4:]))
+	for i := 0; i < numTables; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(otf) {
+			return nil, errInvalid
+		}
+		if string(otf[rec:rec+4]) != "CFF " {
+			continue
+		}
+		offset := int(binary.BigEndian.Uint32(otf[rec+8:]))
+		length := int(binary.BigEndian.Uint32(otf[rec+12:]))
+		if offset < 0 || length < 0 || offset+length > len(otf) {
+			return nil, errInvalid
+		}
+		return Parse(otf[offset : offset+length])
+	}
+	return nil, errors.New("font has no CFF table")
+}
+
+// Parse parses a CFF font program. Only the first font of a font set is read.
+func Parse(data []byte) (*Font, error) {
+	if len(data) < 4 || data[0] != 1 {
+		return nil, errors.New("unsupported CFF version")
+	}
+	f := &Font{}
+
+	// header and INDEXes at fixed positions
+	pos := int(data[2])
+	var err error
+	if f.names, pos, err = readIndex(data, pos); err != nil {
+		return nil, err
+	}
+	var topDicts [][]byte
+	if topDicts, pos, err = readIndex(data, pos); err != nil {
+		return nil, err
+	}
+	if len(topDicts) == 0 {
+		return nil, errInvalid
+	}
+	if f.strings, pos, err = readIndex(data, pos); err != nil {
+		return nil, err
+	}
+	if f.globalSubrs, _, err = readIndex(data, pos); err != nil {
+		return nil, err
+	}
+	if f.topDict, err = parseDict(topDicts[0]); err != nil {
+		return nil, err
+	}
+	if v := f.topDict.get(opCharstringType); len(v) != 0 && v[0] != 2 {
+		return nil, errors.New("unsupported charstring type")
+	}
+
+	// glyphs
+	v := f.topDict.get(opCharStrings)
+	if len(v) == 0 {
+		return nil, errInvalid
+	}
+	if f.charStrings, _, err = readIndex(data, int(v[0])); err != nil {
+		return nil, err
+	}
+	if len(f.charStrings) == 0 {
+		return nil, errInvalid
+	}
+	if f.charset, err = readCharset(data, f.topDict.get(opCharset), len(f.charStrings)); err != nil {
+		return nil, err
+	}
+	f.scale = 0.001
+	if v := f.topDict.get(opFontMatrix); len(v) == 6 {
+		f.scale = v[0]
+	}
+
+	// private dict of regular fonts, font dicts of CID-keyed fonts
+	if !f.IsCIDKeyed() {
+		f.private, err = readPrivateDict(data, f.topDict.get(opPrivate))
+		return f, err
+	}
+	v = f.topDict.get(opFDArray)
+	if len(v) == 0 {
+		return nil, errInvalid
+	}
+	fontDicts, _, err := readIndex(data, int(v[0]))
+	if err != nil {
+		return nil, err
+	}
+	for _, fd := range fontDicts {
+		d, err := parseDict(fd)
+		if err != nil {
+			return nil, err
+		}
+		private, err := readPrivateDict(data, d.get(opPrivate))
+		if err != nil {
+			return nil, err
+		}
+		scale := f.scale
+		if v := d.get(opFontMatrix); len(v) == 6 {
+			scale = v[0]
+			if v := f.topDict.get(opFontMatrix); len(v) == 6 {
+				scale *= v[0]
+			}
+		}
+		f.fdArray = append(f.fdArray, fontDict{dict: d, private: private, scale: scale})
+	}
+	v = f.topDict.get(opFDSelect)
+	if len(v) == 0 {
+		return nil, errInvalid
+	}
+	if f.fdSelect, err = readFDSelect(data, int(v[0]), len(f.charStrings), len(f.fdArray)); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// IsCIDKeyed checks if the font is a CID-keyed font
+func (f *Font) IsCIDKeyed() bool {
+	return f.topDict.get(opROS) != nil
+}
+
+// Name returns the PostScript name of the font
+func (f *Font) Name() string {
+	if len(f.names) == 0 {
+		return ""
+	}
+	return string(f.names[0])
+}
+
+// NumGlyphs returns the number of glyphs in the font
+func (f *Font) NumGlyphs() int {
+	return len(f.charStrings)
+}
+
+// GlyphWidth returns the advance width of the glyph in thousandths of the font size, as encoded in its charstring
+func (f *Font) GlyphWidth(gid uint16) (float64, error) {
+	if int(gid) >= len(f.charStrings) {
+		return 0, errors.New("invalid glyph index")
+	}
+	private, scale := f.glyphPrivate(gid)
+	ip := interpreter{font: f, localSubrs: private.subrs}
+	if err := ip.run(f.charStrings[gid]); err != nil {
+		return 0, err
+	}
+	w := private.defaultWidthX
+	if ip.hasWidth {
+		w = private.nominalWidthX + ip.width
+	}
+	return w * scale * 1000, nil
+}
+
+// glyphPrivate returns the private dict and horizontal scale used by the glyph
+func (f *Font) glyphPrivate(gid uint16) (*privateDict, float64) {
+	if !f.IsCIDKeyed() {
+		return &f.private, f.scale
+	}
+	fd := &f.fdArray[f.fdSelect[gid]]
+	return &fd.private, fd.scale
+}
+
+// readIndex reads an INDEX structure at the given position and returns the items and the position after the INDEX
+func readIndex(data []byte, pos int) ([][]byte, int, error) {
+	if pos < 0 || pos+2 > len(data) {
+		return nil, 0, errInvalid
+	}
+	count := int(binary.BigEndian.Uint16(data[pos:]))
+	if count == 0 {
+		return nil, pos + 2, nil
+	}
+	if pos+3 > len(data) {
+		return nil, 0, errInvalid
+	}
+	offSize := int(data[pos+2])
+	if offSize < 1 || offSize > 4 {
+		return nil, 0, errInvalid
+	}
+	offsets := pos + 3
+	base := offsets + (count+1)*offSize - 1
+	if base >= len(data) {
+		return nil, 0, errInvalid
+	}
+	readOffset := func(i int) int {
+		var v int
+		for _, b := range data[offsets+i*offSize : offsets+(i+1)*offSize] {
+			v = v<<8 | int(b)
+		}
+		return v
+	}
+	items := make([][]byte, count)
+	prev := readOffset(0)
+	for i := 0; i < count; i++ {
+		next := readOffset(i + 1)
+		if prev < 1 || next < prev || base+next > len(data) {
+			return nil, 0, errInvalid
+		}
+		items[i] = data[base+prev : base+next]
+		prev = next
+	}
+	return items, base + prev, nil
+}
+
+// readCharset returns the raw data of the charset at the offset given by the top dict
+func readCharset(data []byte, v []float64, numGlyphs int) ([]byte, error) {
+	if len(v) == 0 || v[0] <= 2 {
+		return nil, nil // predefined charset
+	}
+	start := int(v[0])
+	if start >= len(data) {
+		return nil, errInvalid
+	}
+	pos := start + 1
+	switch data[start] {
+	case 0:
+		pos += 2 * (numGlyphs - 1)
+	case 1, 2:
+		nLeftSize := int(data[start])
+		for covered := 1; covered < numGlyphs; {
+			if pos+2+nLeftSize > len(data) {
+				return nil, errInvalid
+			}
+			nLeft := int(data[pos+2])
+			if nLeftSize == 2 {
+				nLeft = int(binary.BigEndian.Uint16(data[pos+2:]))
+			}
+			covered += nLeft + 1
+			pos += 2 + nLeftSize
+		}
+	default:
+		return nil, errInvalid
+	}
+	if pos > len(data) {
+		return nil, errInvalid
+	}
+	return data[start:pos], nil
+}
+
+// readFDSelect returns the font dict index of every glyph
+func readFDSelect(data []byte, pos, numGlyphs, numFDs int) ([]uint8, error) {
+	if pos < 0 || pos >= len(data) {
+		return nil, errInvalid
+	}
+	res := make([]uint8, numGlyphs)
+	switch data[pos] {
+	case 0:
+		if pos+1+numGlyphs > len(data) {
+			return nil, errInvalid
+		}
+		copy(res, data[pos+1:])
+	case 3:
+		if pos+3 > len(data) {
+			return nil, errInvalid
+		}
+		nRanges := int(binary.BigEndian.Uint16(data[pos+1:]))
+		if pos+3+3*nRanges+2 > len(data) {
+			return nil, errInvalid
+		}
+		for i := 0; i < nRanges; i++ {
+			r := pos + 3 + 3*i
+			first := int(binary.BigEndian.Uint16(data[r:]))
+			next := int(binary.BigEndian.Uint16(data[r+3:]))
+			for gid := first; gid < next && gid < numGlyphs; gid++ {
+				res[gid] = data[r+2]
+			}
+		}
+	default:
+		return nil, errors.New("unsupported FDSelect format")
+	}
+	for _, fd := range res {
+		if int(fd) >= numFDs {
+			return nil, errInvalid
+		}
+	}
+	return res, nil
+}
+
+// readPrivateDict reads the private dict with the given size and offset and its local subroutines
+func readPrivateDict(data []byte, v []float64) (privateDict, error) {
+	var res privateDict
+	if len(v) != 2 {
+		return res, nil
+	}
+	size, offset := int(v[0]), int(v[1])
+	if size < 0 || offset < 0 || offset+size > len(data) {
+		return res, errInvalid
+	}
+	var err error
+	if res.dict, err = parseDict(data[offset : offset+size]); err != nil {
+		return res, err
+	}
+	if v := res.dict.get(opDefaultWidthX); len(v) != 0 {
+		res.defaultWidthX = v[0]
+	}
+	if v := res.dict.get(opNominalWidthX); len(v) != 0 {
+		res.nominalWidthX = v[0]
+	}
+	if v := res.dict.get(opSubrs); len(v) != 0 {
+		if res.subrs, _, err = readIndex(data, offset+int(v[0])); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
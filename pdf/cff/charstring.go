@@ -0,0 +1,253 @@
+package cff
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Type 2 charstring operators
+const (
+	csHstem     = 1
+	csVstem     = 3
+	csVmoveto   = 4
+	csCallsubr  = 10
+	csReturn    = 11
+	csEscape    = 12
+	csEndchar   = 14
+	csHstemhm   = 18
+	csHintmask  = 19
+	csCntrmask  = 20
+	csRmoveto   = 21
+	csHmoveto   = 22
+	csVstemhm   = 23
+	csCallgsubr = 29
+)
+
+// maximum nesting of subroutine calls
+const maxSubrDepth = 10
+
+// interpreter runs a Type 2 charstring to find its width and the subroutines it uses. Path operators are not
+// evaluated.
+type interpreter struct {
+	font       *Font
+	localSubrs [][]byte
+
+	// subroutines called, indices without bias
+	usedLocal  map[int]struct{}
+	usedGlobal map[int]struct{}
+
+	stack     []float64
+	stems     int
+	depth     int
+	widthDone bool
+	hasWidth  bool
+	width     float64
+	ended     bool
+}
+
+// subrBias returns the bias added to subroutine numbers
+func subrBias(count int) int {
+	switch {
+	case count < 1240:
+		return 107
+	case count < 33900:
+		return 1131
+	}
+	return 32768
+}
+
+// run interprets the charstring until endchar or return
+func (q *interpreter) run(cs []byte) error {
+	if q.depth > maxSubrDepth {
+		return errors.New("charstring subroutines nested too deeply")
+	}
+	for pos := 0; pos < len(cs) && !q.ended; {
+		b0 := cs[pos]
+
+		// operands
+		switch {
+		case b0 >= 32 && b0 <= 246:
+			q.stack = append(q.stack, float64(int(b0)-139))
+			pos++
+			continue
+		case b0 >= 247 && b0 <= 250:
+			if pos+1 >= len(cs) {
+				return errInvalid
+			}
+			q.stack = append(q.stack, float64((int(b0)-247)*256+int(cs[pos+1])+108))
+			pos += 2
+			continue
+		case b0 >= 251 && b0 <= 254:
+			if pos+1 >= len(cs) {
+				return errInvalid
+			}
+			q.stack = append(q.stack, float64(-(int(b0)-251)*256-int(cs[pos+1])-108))
+			pos += 2
+			continue
+		case b0 == 28:
+			if pos+2 >= len(cs) {
+				return errInvalid
+			}
+			q.stack = append(q.stack, float64(int16(binary.BigEndian.Uint16(cs[pos+1:]))))
+			pos += 3
+			continue
+		case b0 == 255:
+			if pos+4 >= len(cs) {
+				return errInvalid
+			}
+			q.stack = append(q.stack, float64(int32(binary.BigEndian.Uint32(cs[pos+1:])))/65536)
+			pos += 5
+			continue
+		}
+
+		// operators
+		pos++
+		switch b0 {
+		case csHstem, csVstem, csHstemhm, csVstemhm:
+			q.checkWidth(len(q.stack)%2 == 1)
+			q.stems += len(q.stack) / 2
+		case csHintmask, csCntrmask:
+			q.checkWidth(len(q.stack)%2 == 1)
+			q.stems += len(q.stack) / 2 // implicit vstem
+			pos += (q.stems + 7) / 8
+		case csRmoveto:
+			q.checkWidth(len(q.stack) > 2)
+		case csHmoveto, csVmoveto:
+			q.checkWidth(len(q.stack) > 1)
+		case csEndchar:
+			q.checkWidth(len(q.stack) == 1 || len(q.stack) == 5)
+			q.ended = true
+		case csReturn:
+			return nil
+		case csCallsubr, csCallgsubr:
+			if len(q.stack) == 0 {
+				return errInvalid
+			}
+			subrs, used := q.localSubrs, q.usedLocal
+			if b0 == csCallgsubr {
+				subrs, used = q.font.globalSubrs, q.usedGlobal
+			}
+			i := int(q.stack[len(q.stack)-1]) + subrBias(len(subrs))
+			q.stack = q.stack[:len(q.stack)-1]
+			if i < 0 || i >= len(subrs) {
+				return errors.New("invalid charstring subroutine")
+			}
+			if used != nil {
+				used[i] = struct{}{}
+			}
+			q.depth++
+			err := q.run(subrs[i])
+			q.depth--
+			if err != nil {
+				return err
+			}
+			continue // the stack is kept
+		case csEscape:
+			if pos >= len(cs) {
+				return errInvalid
+			}
+			if q.arithmetic(cs[pos]) {
+				pos++
+				continue
+			}
+			pos++
+		}
+		q.stack = q.stack[:0]
+	}
+	return nil
+}
+
+// checkWidth reads the width from the stack at the first stack-clearing operator
+func (q *interpreter) checkWidth(hasWidth bool) {
+	if q.widthDone {
+		return
+	}
+	q.widthDone = true
+	if hasWidth {
+		q.hasWidth = true
+		q.width = q.stack[0]
+		q.stack = q.stack[1:]
+	}
+}
+
+// arithmetic executes the escaped arithmetic operator, which may calculate subroutine numbers. Returns false for
+// other operators.
+func (q *interpreter) arithmetic(op byte) bool {
+	n := len(q.stack)
+	pop := func(k int) []float64 {
+		if n < k {
+			q.stack = q.stack[:0]
+			return make([]float64, k)
+		}
+		args := append([]float64(nil), q.stack[n-k:]...)
+		q.stack = q.stack[:n-k]
+		return args
+	}
+	push := func(v ...float64) {
+		q.stack = append(q.stack, v...)
+	}
+	boolean := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+
+	switch op {
+	case 3: // and
+		a := pop(2)
+		push(boolean(a[0] != 0 && a[1] != 0))
+	case 4: // or
+		a := pop(2)
+		push(boolean(a[0] != 0 || a[1] != 0))
+	case 5: // not
+		a := pop(1)
+		push(boolean(a[0] == 0))
+	case 9: // abs
+		push(math.Abs(pop(1)[0]))
+	case 10: // add
+		a := pop(2)
+		push(a[0] + a[1])
+	case 11: // sub
+		a := pop(2)
+		push(a[0] - a[1])
+	case 12: // div
+		a := pop(2)
+		if a[1] == 0 {
+			push(0)
+		} else {
+			push(a[0] / a[1])
+		}
+	case 14: // neg
+		push(-pop(1)[0])
+	case 15: // eq
+		a := pop(2)
+		push(boolean(a[0] == a[1]))
+	case 18: // drop
+		pop(1)
+	case 22: // ifelse
+		a := pop(4)
+		if a[2] <= a[3] {
+			push(a[0])
+		} else {
+			push(a[1])
+		}
+	case 23: // random
+		push(0.5)
+	case 24: // mul
+		a := pop(2)
+		push(a[0] * a[1])
+	case 26: // sqrt
+		push(math.Sqrt(math.Abs(pop(1)[0])))
+	case 27: // dup
+		a := pop(1)
+		push(a[0], a[0])
+	case 28: // exch
+		a := pop(2)
+		push(a[1], a[0])
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package cff
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// dictEntry is an operator of a DICT with its operands
+type dictEntry struct {
+	op   int // escaped operators are 1200 + second byte
+	args []float64
+	raw  []byte // encoded operands
+}
+
+// dict is a DICT structure with entries in their original order
+type dict []dictEntry
+
+// parseDict parses the data of a DICT
+func parseDict(data []byte) (dict, error) {
+	var res dict
+	var args []float64
+	start := 0
+	for pos := 0; pos < len(data); {
+		b0 := data[pos]
+		if b0 <= 21 {
+			op := int(b0)
+			raw := data[start:pos]
+			pos++
+			if b0 == 12 {
+				if pos >= len(data) {
+					return nil, errInvalid
+				}
+				op = 1200 + int(data[pos])
+				pos++
+			}
+			res = append(res, dictEntry{op: op, args: args, raw: raw})
+			args = nil
+			start = pos
+			continue
+		}
+
+		v, n, err := readDictOperand(data[pos:])
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+		pos += n
+	}
+	return res, nil
+}
+
+// readDictOperand reads a number of a DICT and returns its value and length
+func readDictOperand(data []byte) (float64, int, error) {
+	b0 := data[0]
+	switch {
+	case b0 >= 32 && b0 <= 246:
+		return float64(int(b0) - 139), 1, nil
+	case b0 >= 247 && b0 <= 250 && len(data) >= 2:
+		return float64((int(b0)-247)*256 + int(data[1]) + 108), 2, nil
+	case b0 >= 251 && b0 <= 254 && len(data) >= 2:
+		return float64(-(int(b0)-251)*256 - int(data[1]) - 108), 2, nil
+	case b0 == 28 && len(data) >= 3:
+		return float64(int16(binary.BigEndian.Uint16(data[1:]))), 3, nil
+	case b0 == 29 && len(data) >= 5:
+		return float64(int32(binary.BigEndian.Uint32(data[1:]))), 5, nil
+	case b0 == 30:
+		return readReal(data)
+	}
+	return 0, 0, errInvalid
+}
+
+// readReal reads a real number encoded in nibbles
+func readReal(data []byte) (float64, int, error) {
+	var s []byte
+	for pos := 1; pos < len(data); pos++ {
+		for _, nibble := range []byte{data[pos] >> 4, data[pos] & 0xF} {
+			switch {
+			case nibble <= 9:
+				s = append(s, '0'+nibble)
+			case nibble == 0xA:
+				s = append(s, '.')
+			case nibble == 0xB:
+				s = append(s, 'E')
+			case nibble == 0xC:
+				s = append(s, 'E', '-')
+			case nibble == 0xE:
+				s = append(s, '-')
+			case nibble == 0xF:
+				v, err := strconv.ParseFloat(string(s), 64)
+				if err != nil {
+					return 0, 0, errInvalid
+				}
+				return v, pos + 1, nil
+			}
+		}
+	}
+	return 0, 0, errInvalid
+}
+
+// get returns the operands of the operator or nil if the dict does not contain it
+func (d dict) get(op int) []float64 {
+	for _, e := range d {
+		if e.op == op {
+			if e.args == nil {
+				return []float64{}
+			}
+			return e.args
+		}
+	}
+	return nil
+}
+
+// encode returns the DICT data. Operators in offsets get the given operands encoded with a fixed length, so the
+// size of the DICT does not depend on their values; operators in remove are left out.
+func (d dict) encode(offsets map[int][]int, remove ...int) []byte {
+	var res []byte
+	for _, e := range d {
+		if containsOp(remove, e.op) {
+			continue
+		}
+		if v, ok := offsets[e.op]; ok {
+			for _, i := range v {
+				res = appendInt32(res, i)
+			}
+		} else {
+			res = append(res, e.raw...)
+		}
+		res = appendOperator(res, e.op)
+	}
+
+	// offsets not contained in the original dict
+	var added []int
+	for op := range offsets {
+		if d.get(op) == nil {
+			added = append(added, op)
+		}
+	}
+	sort.Ints(added)
+	for _, op := range added {
+		for _, i := range offsets[op] {
+			res = appendInt32(res, i)
+		}
+		res = appendOperator(res, op)
+	}
+	return res
+}
+
+// containsOp checks if the operator is in the list
+func containsOp(ops []int, op int) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// appendInt32 appends the number in the 5 byte encoding
+func appendInt32(b []byte, v int) []byte {
+	if v > math.MaxInt32 || v < math.MinInt32 {
+		v = 0
+	}
+	return append(b, 29, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// appendOperator appends the operator, escaped if necessary
+func appendOperator(b []byte, op int) []byte {
+	if op >= 1200 {
+		return append(b, 12, byte(op-1200))
+	}
+	return append(b, byte(op))
+}
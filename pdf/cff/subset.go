@@ -0,0 +1,237 @@
+package cff
+
+import "encoding/binary"
+
+// empty charstrings replacing unused glyphs and subroutines
+var (
+	emptyGlyph = []byte{csEndchar}
+	emptySubr  = []byte{csReturn}
+)
+
+// Subset returns the font program with the charstrings of the given glyphs only. Glyphs keep their glyph IDs:
+// unused charstrings and subroutines are replaced by empty ones. The charset of CID-keyed fonts is replaced by an
+// identity mapping, so that CIDs equal glyph IDs, like in fonts that are not CID-keyed.
+func (f *Font) Subset(gids []uint16) ([]byte, error) {
+	// glyphs to keep, always including .notdef
+	keep := map[uint16]struct{}{0: {}}
+	for _, gid := range gids {
+		if int(gid) < len(f.charStrings) {
+			keep[gid] = struct{}{}
+		}
+	}
+	var maxGID uint16
+	for gid := range keep {
+		if gid > maxGID {
+			maxGID = gid
+		}
+	}
+
+	// find used subroutines
+	usedGlobal := make(map[int]struct{})
+	usedLocal := make([]map[int]struct{}, len(f.fdArray)+1) // by font dict, last one for fonts that are not CID-keyed
+	for i := range usedLocal {
+		usedLocal[i] = make(map[int]struct{})
+	}
+	for gid := range keep {
+		private, _ := f.glyphPrivate(gid)
+		fd := len(f.fdArray)
+		if f.IsCIDKeyed() {
+			fd = int(f.fdSelect[gid])
+		}
+		ip := interpreter{font: f, localSubrs: private.subrs, usedLocal: usedLocal[fd], usedGlobal: usedGlobal}
+		if err := ip.run(f.charStrings[gid]); err != nil {
+			return nil, err
+		}
+	}
+
+	// charstrings and subroutines
+	charStrings := make([][]byte, int(maxGID)+1)
+	for gid := range charStrings {
+		if _, ok := keep[uint16(gid)]; ok {
+			charStrings[gid] = f.charStrings[gid]
+		} else {
+			charStrings[gid] = emptyGlyph
+		}
+	}
+	globalSubrs := subsetSubrs(f.globalSubrs, usedGlobal)
+
+	// charset and FDSelect
+	var charset, fdSelect []byte
+	if f.IsCIDKeyed() {
+		charset = []byte{2, 0, 1, 0, 0}
+		binary.BigEndian.PutUint16(charset[3:], uint16(len(charStrings)-2))
+		if len(charStrings) == 1 {
+			charset = []byte{0}
+		}
+		fdSelect = append([]byte{0}, f.fdSelect[:len(charStrings)]...)
+	} else if f.charset != nil {
+		charset = truncateCharset(f.charset, len(charStrings))
+	}
+
+	// private dicts with subroutines
+	type private struct {
+		dict  dict
+		subrs [][]byte
+	}
+	var privates []private
+	if f.IsCIDKeyed() {
+		for i, fd := range f.fdArray {
+			privates = append(privates, private{dict: fd.private.dict, subrs: subsetSubrs(fd.private.subrs, usedLocal[i])})
+		}
+	} else {
+		privates = append(privates, private{dict: f.private.dict, subrs: subsetSubrs(f.private.subrs, usedLocal[0])})
+	}
+	privateData := make([][]byte, len(privates))
+	for i, p := range privates {
+		if len(p.subrs) == 0 {
+			privateData[i] = p.dict.encode(nil, opSubrs)
+			continue
+		}
+
+		// the subroutines follow the private dict, which has a fixed size
+		size := len(p.dict.encode(map[int][]int{opSubrs: {0}}))
+		privateData[i] = p.dict.encode(map[int][]int{opSubrs: {size}})
+		privateData[i] = append(privateData[i], writeIndex(p.subrs)...)
+	}
+	privateDictSize := func(i int) int {
+		if len(privates[i].subrs) == 0 {
+			return len(privateData[i])
+		}
+		return len(privates[i].dict.encode(map[int][]int{opSubrs: {0}}))
+	}
+
+	// layout: header, name, top dict, strings, global subrs, charset, FDSelect, charstrings, font dicts, private
+	// dicts. The size of the top dict and font dicts does not depend on the offsets.
+	topOffsets := func(charsetPos, fdSelectPos, charStringsPos, fdArrayPos, privatePos int) map[int][]int {
+		offsets := map[int][]int{opCharStrings: {charStringsPos}}
+		if charset != nil {
+			offsets[opCharset] = []int{charsetPos}
+		}
+		if f.IsCIDKeyed() {
+			offsets[opFDSelect] = []int{fdSelectPos}
+			offsets[opFDArray] = []int{fdArrayPos}
+		} else {
+			offsets[opPrivate] = []int{privateDictSize(0), privatePos}
+		}
+		return offsets
+	}
+	fontDicts := func(privatePos int) [][]byte {
+		var res [][]byte
+		for i, fd := range f.fdArray {
+			res = append(res, fd.dict.encode(map[int][]int{opPrivate: {privateDictSize(i), privatePos}}))
+			privatePos += len(privateData[i])
+		}
+		return res
+	}
+
+	header := []byte{1, 0, 4, 4}
+	names := writeIndex(f.names[:1])
+	strings := writeIndex(f.strings)
+	gsubrs := writeIndex(globalSubrs)
+	charStringsIndex := writeIndex(charStrings)
+	topSize := len(writeIndex([][]byte{f.topDict.encode(topOffsets(0, 0, 0, 0, 0), opEncoding)}))
+	var fdArraySize int
+	if f.IsCIDKeyed() {
+		fdArraySize = len(writeIndex(fontDicts(0)))
+	}
+
+	charsetPos := len(header) + len(names) + topSize + len(strings) + len(gsubrs)
+	fdSelectPos := charsetPos + len(charset)
+	charStringsPos := fdSelectPos + len(fdSelect)
+	fdArrayPos := charStringsPos + len(charStringsIndex)
+	privatePos := fdArrayPos + fdArraySize
+
+	// write font
+	res := append([]byte(nil), header...)
+	res = append(res, names...)
+	res = append(res, writeIndex([][]byte{
+		f.topDict.encode(topOffsets(charsetPos, fdSelectPos, charStringsPos, fdArrayPos, privatePos), opEncoding),
+	})...)
+	res = append(res, strings...)
+	res = append(res, gsubrs...)
+	res = append(res, charset...)
+	res = append(res, fdSelect...)
+	res = append(res, charStringsIndex...)
+	if f.IsCIDKeyed() {
+		res = append(res, writeIndex(fontDicts(privatePos))...)
+	}
+	for _, p := range privateData {
+		res = append(res, p...)
+	}
+	return res, nil
+}
+
+// subsetSubrs replaces the unused subroutines by empty ones
+func subsetSubrs(subrs [][]byte, used map[int]struct{}) [][]byte {
+	res := make([][]byte, len(subrs))
+	for i, s := range subrs {
+		if _, ok := used[i]; ok {
+			res[i] = s
+		} else {
+			res[i] = emptySubr
+		}
+	}
+	return res
+}
+
+// truncateCharset returns a charset covering the given number of glyphs in format 0
+func truncateCharset(charset []byte, numGlyphs int) []byte {
+	var sids []uint16
+	switch charset[0] {
+	case 0:
+		for pos := 1; pos+1 < len(charset) && len(sids) < numGlyphs-1; pos += 2 {
+			sids = append(sids, binary.BigEndian.Uint16(charset[pos:]))
+		}
+	default:
+		nLeftSize := int(charset[0])
+		for pos := 1; pos+2+nLeftSize <= len(charset) && len(sids) < numGlyphs-1; pos += 2 + nLeftSize {
+			first := binary.BigEndian.Uint16(charset[pos:])
+			nLeft := int(charset[pos+2])
+			if nLeftSize == 2 {
+				nLeft = int(binary.BigEndian.Uint16(charset[pos+2:]))
+			}
+			for i := 0; i <= nLeft && len(sids) < numGlyphs-1; i++ {
+				sids = append(sids, first+uint16(i))
+			}
+		}
+	}
+	res := make([]byte, 1, 1+2*len(sids))
+	for _, sid := range sids {
+		res = append(res, byte(sid>>8), byte(sid))
+	}
+	return res
+}
+
+// writeIndex encodes the items as INDEX structure
+func writeIndex(items [][]byte) []byte {
+	if len(items) == 0 {
+		return []byte{0, 0}
+	}
+	size := 1
+	for _, item := range items {
+		size += len(item)
+	}
+	offSize := 1
+	for limit := 0x100; size >= limit && offSize < 4; limit <<= 8 {
+		offSize++
+	}
+
+	res := make([]byte, 3, 3+(len(items)+1)*offSize+size)
+	binary.BigEndian.PutUint16(res, uint16(len(items)))
+	res[2] = byte(offSize)
+	offset := 1
+	writeOffset := func() {
+		for i := offSize - 1; i >= 0; i-- {
+			res = append(res, byte(offset>>(8*i)))
+		}
+	}
+	writeOffset()
+	for _, item := range items {
+		offset += len(item)
+		writeOffset()
+	}
+	for _, item := range items {
+		res = append(res, item...)
+	}
+	return res
+}
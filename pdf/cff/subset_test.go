@@ -0,0 +1,245 @@
+package cff
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// csNum encodes a small charstring number
+func csNum(v int) byte {
+	return byte(v + 139)
+}
+
+// dictData encodes DICT entries with int32 operands, given as operator followed by its operands
+func dictData(entries ...[]int) []byte {
+	var res []byte
+	for _, e := range entries {
+		for _, v := range e[1:] {
+			res = appendInt32(res, v)
+		}
+		res = appendOperator(res, e[0])
+	}
+	return res
+}
+
+// privateData encodes a private dict followed by its local subroutines
+func privateData(defaultWidthX, nominalWidthX int, subrs [][]byte) []byte {
+	size := len(dictData([]int{opDefaultWidthX, 0}, []int{opNominalWidthX, 0}, []int{opSubrs, 0}))
+	res := dictData([]int{opDefaultWidthX, defaultWidthX}, []int{opNominalWidthX, nominalWidthX}, []int{opSubrs, size})
+	return append(res, writeIndex(subrs)...)
+}
+
+// Test fonts with four glyphs:
+//
+//	0: .notdef with width nominalWidthX + 50
+//	1: default width, calls local subroutine 0
+//	2: width nominalWidthX + 100, calls global subroutine 1
+//	3: default width, calls local subroutine 1, which calls global subroutine 0
+var (
+	testCharStrings = [][]byte{
+		{csNum(50), csEndchar},
+		{csNum(0), csNum(0), csRmoveto, csNum(0 - 107), csCallsubr, csEndchar},
+		{csNum(100), csNum(0), csNum(0), csRmoveto, csNum(1 - 107), csCallgsubr, csEndchar},
+		{csNum(0), csNum(0), csRmoveto, csNum(1 - 107), csCallsubr, csEndchar},
+	}
+	testGlobalSubrs = [][]byte{
+		{csNum(10), csNum(20), 5, csReturn},
+		{csNum(30), csNum(40), 5, csReturn},
+	}
+	testLocalSubrs = [][]byte{
+		{csNum(1), csNum(2), 5, csReturn},
+		{csNum(0 - 107), csCallgsubr, csReturn},
+	}
+)
+
+// testFont builds a CFF font of the test glyphs. CID-keyed fonts use two font dicts, glyphs 0 and 1 use the first
+// one.
+func testFont(cid bool) []byte {
+	header := []byte{1, 0, 4, 4}
+	names := writeIndex([][]byte{[]byte("Test")})
+	strings := writeIndex([][]byte{[]byte("Adobe"), []byte("Identity"), []byte("glyph")})
+	gsubrs := writeIndex(testGlobalSubrs)
+	charStrings := writeIndex(testCharStrings)
+	var charset, fdSelect []byte
+	var privates [][]byte
+	if cid {
+		charset = []byte{0, 0, 10, 0, 11, 0, 12} // CIDs 10, 11, 12
+		fdSelect = []byte{0, 0, 0, 1, 1}
+		privates = [][]byte{privateData(500, 600, testLocalSubrs), privateData(700, 800, testLocalSubrs)}
+	} else {
+		charset = []byte{0, 1, 135, 1, 136, 1, 137} // SIDs 391, 392, 393 of the glyph names
+		privates = [][]byte{privateData(500, 600, testLocalSubrs)}
+	}
+
+	top := func(charsetPos, fdSelectPos, charStringsPos, fdArrayPos, privatePos int) []byte {
+		if cid {
+			return dictData(
+				[]int{opROS, 391, 392, 0},
+				[]int{opCharset, charsetPos},
+				[]int{opCharStrings, charStringsPos},
+				[]int{opFDSelect, fdSelectPos},
+				[]int{opFDArray, fdArrayPos},
+			)
+		}
+		return dictData(
+			[]int{opCharset, charsetPos},
+			[]int{opCharStrings, charStringsPos},
+			[]int{opPrivate, len(privates[0]) - len(writeIndex(testLocalSubrs)), privatePos},
+		)
+	}
+	fontDicts := func(privatePos int) []byte {
+		var dicts [][]byte
+		for _, p := range privates {
+			size := len(p) - len(writeIndex(testLocalSubrs))
+			dicts = append(dicts, dictData([]int{opPrivate, size, privatePos}))
+			privatePos += len(p)
+		}
+		return writeIndex(dicts)
+	}
+
+	charsetPos := len(header) + len(names) + len(writeIndex([][]byte{top(0, 0, 0, 0, 0)})) + len(strings) + len(gsubrs)
+	fdSelectPos := charsetPos + len(charset)
+	charStringsPos := fdSelectPos + len(fdSelect)
+	fdArrayPos := charStringsPos + len(charStrings)
+	privatePos := fdArrayPos
+	if cid {
+		privatePos += len(fontDicts(0))
+	}
+
+	var res []byte
+	res = append(res, header...)
+	res = append(res, names...)
+	res = append(res, writeIndex([][]byte{top(charsetPos, fdSelectPos, charStringsPos, fdArrayPos, privatePos)})...)
+	res = append(res, strings...)
+	res = append(res, gsubrs...)
+	res = append(res, charset...)
+	res = append(res, fdSelect...)
+	res = append(res, charStrings...)
+	if cid {
+		res = append(res, fontDicts(privatePos)...)
+	}
+	for _, p := range privates {
+		res = append(res, p...)
+	}
+	return res
+}
+
+// glyphWidths returns the widths of all glyphs, rounded to avoid floating point differences
+func glyphWidths(t *testing.T, f *Font) []float64 {
+	t.Helper()
+	var res []float64
+	for gid := 0; gid < f.NumGlyphs(); gid++ {
+		w, err := f.GlyphWidth(uint16(gid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, math.Round(w*1000)/1000)
+	}
+	return res
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		cid    bool
+		widths []float64
+	}{
+		{false, []float64{650, 500, 700, 500}},
+		{true, []float64{650, 500, 900, 700}},
+	}
+	for _, tt := range tests {
+		f, err := Parse(testFont(tt.cid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Name() != "Test" || f.NumGlyphs() != 4 || f.IsCIDKeyed() != tt.cid {
+			t.Errorf("cid %v: got name %q, %d glyphs, CID-keyed %v", tt.cid, f.Name(), f.NumGlyphs(), f.IsCIDKeyed())
+		}
+		if got := glyphWidths(t, f); !reflect.DeepEqual(got, tt.widths) {
+			t.Errorf("cid %v: got widths %v, want %v", tt.cid, got, tt.widths)
+		}
+	}
+}
+
+func TestSubset(t *testing.T) {
+	tests := []struct {
+		gids        []uint16
+		charStrings int
+		global      []bool // global subroutines kept
+		local       []bool // local subroutines kept
+	}{
+		{[]uint16{1}, 2, []bool{false, false}, []bool{true, false}},
+		{[]uint16{2}, 3, []bool{false, true}, []bool{false, false}},
+		{[]uint16{3}, 4, []bool{true, false}, []bool{false, true}},
+		{[]uint16{1, 2, 3, 7}, 4, []bool{true, true}, []bool{true, true}},
+		{nil, 1, []bool{false, false}, []bool{false, false}},
+	}
+	for _, cid := range []bool{false, true} {
+		orig, err := Parse(testFont(cid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		origWidths := glyphWidths(t, orig)
+
+		for _, tt := range tests {
+			data, err := orig.Subset(tt.gids)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := Parse(data)
+			if err != nil {
+				t.Fatalf("cid %v, glyphs %v: subset cannot be parsed: %v", cid, tt.gids, err)
+			}
+			if f.NumGlyphs() != tt.charStrings || f.IsCIDKeyed() != cid || f.Name() != "Test" {
+				t.Errorf("cid %v, glyphs %v: got %d glyphs, CID-keyed %v", cid, tt.gids, f.NumGlyphs(), f.IsCIDKeyed())
+				continue
+			}
+
+			// kept glyphs are unchanged and have the same widths
+			keep := map[uint16]bool{0: true}
+			for _, gid := range tt.gids {
+				keep[gid] = true
+			}
+			widths := glyphWidths(t, f)
+			for gid := 0; gid < f.NumGlyphs(); gid++ {
+				kept := bytes.Equal(f.charStrings[gid], orig.charStrings[gid])
+				if kept != keep[uint16(gid)] {
+					t.Errorf("cid %v, glyphs %v: glyph %d kept %v", cid, tt.gids, gid, kept)
+				}
+				if kept && widths[gid] != origWidths[gid] {
+					t.Errorf("cid %v, glyphs %v: glyph %d has width %v, want %v", cid, tt.gids, gid, widths[gid], origWidths[gid])
+				}
+			}
+
+			// subroutines keep their numbers, unused ones are empty. Local subroutines of CID-keyed fonts are kept if
+			// used by the glyphs of any font dict.
+			for i, want := range tt.global {
+				if got := bytes.Equal(f.globalSubrs[i], testGlobalSubrs[i]); got != want {
+					t.Errorf("cid %v, glyphs %v: global subroutine %d kept %v", cid, tt.gids, i, got)
+				}
+			}
+			privates := []privateDict{f.private}
+			if cid {
+				privates = nil
+				for _, fd := range f.fdArray {
+					privates = append(privates, fd.private)
+				}
+			}
+			for i, want := range tt.local {
+				var got bool
+				for _, p := range privates {
+					got = got || bytes.Equal(p.subrs[i], testLocalSubrs[i])
+				}
+				if got != want {
+					t.Errorf("cid %v, glyphs %v: local subroutine %d kept %v", cid, tt.gids, i, got)
+				}
+			}
+
+			// CIDs equal glyph IDs
+			if cid && f.NumGlyphs() > 1 && !bytes.Equal(f.charset, []byte{2, 0, 1, 0, byte(f.NumGlyphs() - 2)}) {
+				t.Errorf("cid %v, glyphs %v: got charset %v", cid, tt.gids, f.charset)
+			}
+		}
+	}
+}
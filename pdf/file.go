@@ -28,9 +28,12 @@ type File struct {
 	Version float64
 
 	// internals
-	fonts         []FontHandler
+	fonts         []fileFont
 	toUnicode     types.Reference
 	cidSystemInfo types.Reference
+	nullRef       types.Reference
+	outlines      types.Dictionary
+	acroForm      types.Dictionary
 	catalog       types.DocumentCatalog
 	pageTree      types.PageTreeNode
 	creator       *pdffile.File
@@ -38,6 +41,12 @@ type File struct {
 	newImageMux   sync.Mutex
 }
 
+// fileFont is a font added to the file with the objects created for it
+type fileFont struct {
+	handler FontHandler
+	objects []types.Reference
+}
+
 // NewFile creates a new File object
 func NewFile() *File {
 	q := &File{
@@ -60,6 +69,25 @@ func (q *File) NewPage(width, height float64) *Page {
 	return p
 }
 
+// copyObject copies the object with the given reference from the source file unless already copied and returns the
+// reference of the copy. References within the object are copied by copyRef. The number of the copy is reserved
+// before, so that cyclic references, e.g. /Parent or /P back-pointers, point to the copy.
+func (q *File) copyObject(sourceFile *pdffile.File, copiedMap map[types.Reference]types.Reference, ref types.Reference,
+	copyRef func(ref types.Reference) types.Reference) types.Reference {
+	if newRef, ok := copiedMap[ref]; ok {
+		return newRef
+	}
+
+	obj, err := sourceFile.GetObject(ref)
+	if err != nil || obj == nil {
+		obj = types.Null{}
+	}
+	newRef := q.creator.AddObject(types.Null{})
+	copiedMap[ref] = newRef
+	_ = q.creator.SetObject(newRef, types.Copy(obj, copyRef))
+	return newRef
+}
+
 // CopyPage adds and returns a new Page
 func (q *File) CopyPage(sourcePage types.Page, sourceFile *pdffile.File) *Page {
 	if q.copiedObjects == nil {
@@ -72,15 +100,7 @@ func (q *File) CopyPage(sourcePage types.Page, sourceFile *pdffile.File) *Page {
 	defer func() { q.copiedObjects[sourceFile] = copiedMap }()
 	var copyRef func(ref types.Reference) types.Reference
 	copyRef = func(ref types.Reference) types.Reference {
-		if newRef, ok := copiedMap[ref]; ok {
-			return newRef
-		}
-
-		obj, _ := sourceFile.GetObject(ref)
-		copiedMap[ref] = types.Reference{} // to avoid endless recursion
-		newRef := q.creator.AddObject(types.Copy(obj, copyRef))
-		copiedMap[ref] = newRef
-		return newRef
+		return q.copyObject(sourceFile, copiedMap, ref, copyRef)
 	}
 
 	p := &Page{
@@ -93,10 +113,8 @@ func (q *File) CopyPage(sourcePage types.Page, sourceFile *pdffile.File) *Page {
 // WriteTo writes the parsed to the given writer
 func (q *File) WriteTo(w io.Writer) (int64, error) {
 	// finish fonts
-	for _, f := range q.fonts {
-		if err := f.finish(); err != nil {
-			return 0, err
-		}
+	if err := q.finishFonts(); err != nil {
+		return 0, err
 	}
 
 	// info
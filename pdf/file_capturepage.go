@@ -21,15 +21,7 @@ func (q *File) NewCapturedPage(sourcePage types.Page, sourceFile *pdffile.File)
 	defer func() { q.copiedObjects[sourceFile] = copiedMap }()
 	var copyRef func(ref types.Reference) types.Reference
 	copyRef = func(ref types.Reference) types.Reference {
-		if newRef, ok := copiedMap[ref]; ok {
-			return newRef
-		}
-
-		obj, _ := sourceFile.GetObject(ref)
-		copiedMap[ref] = types.Reference{} // to avoid endless recursion
-		newRef := q.creator.AddObject(types.Copy(obj, copyRef))
-		copiedMap[ref] = newRef
-		return newRef
+		return q.copyObject(sourceFile, copiedMap, ref, copyRef)
 	}
 
 	// collect content
@@ -84,8 +76,12 @@ func (q *File) NewCapturedPage(sourcePage types.Page, sourceFile *pdffile.File)
 	}), nil
 }
 
-// NewFormFromPage creates a new form object from the given page
+// NewFormFromPage creates a new form object from the given page. Forms cannot contain annotations, so the page must not
+// have links.
 func (q *File) NewFormFromPage(page *Page) (types.Reference, error) {
+	if len(page.links) != 0 {
+		return types.Reference{}, errors.New("links cannot be added to forms")
+	}
 	stream, err := types.NewStream(bytes.Join(page.contents, []byte{'\n'}), types.Filter_FlateDecode)
 	if err != nil {
 		return types.Reference{}, err
@@ -2,52 +2,223 @@ package pdf
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
 
+	"github.com/raceresult/gopdf/pdf/cff"
+	"github.com/raceresult/gopdf/pdf/type1"
 	"github.com/raceresult/gopdf/pdf/unitype"
 	"github.com/raceresult/gopdf/types"
+	"github.com/raceresult/gopdf/types/standardfont/afm"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/sfnt"
 	"golang.org/x/image/math/fixed"
 )
 
-// NewStandardFont adds and returns a new standard font (expected to be available on all pdf consuming systems)
+// NewStandardFont adds and returns a new standard font (expected to be available on all pdf consuming systems) with
+// a predefined encoding
 func (q *File) NewStandardFont(name types.StandardFontName, encoding types.Encoding) (*StandardFont, error) {
+	return q.NewStandardFontWithEncoding(name, encoding)
+}
+
+// NewStandardFontWithEncoding adds and returns a new standard font. The encoding is a predefined encoding or a custom
+// encoding, e.g. to use glyphs of the font not contained in WinAnsiEncoding or glyphs of Symbol and ZapfDingbats by
+// Unicode characters.
+func (q *File) NewStandardFontWithEncoding(name types.StandardFontName, encoding types.SimpleEncoding) (*StandardFont, error) {
+	var objects []types.Reference
+	if encoding == nil {
+		encoding = types.Encoding("")
+	}
 	f := types.StandardFont{
 		BaseFont: name,
-		Encoding: encoding,
 	}
 	metrics, err := f.Metrics()
 	if err != nil {
 		return nil, err
 	}
+
+	// encodings with differences are written as encoding dictionary, which requires a font dictionary
+	var obj types.Object
+	switch e := encoding.EncodingEntry().(type) {
+	case nil:
+		obj = f
+	case types.Encoding:
+		f.Encoding = e
+		obj = f
+	default:
+		toUnicode, err := q.addSimpleEncodingToUnicode(encoding)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, toUnicode)
+		obj = types.Font{
+			Subtype:   types.FontSub_Type1,
+			BaseFont:  types.Name(name),
+			Encoding:  e,
+			ToUnicode: toUnicode,
+		}
+	}
+
 	fh := &StandardFont{
-		reference: q.creator.AddObject(f),
-		encoding:  encoding,
-		metrics:   metrics,
+		fontReference: fontReference{reference: q.addFontObject(&objects, obj)},
+		encoding:      encoding,
+		metrics:       metrics,
 	}
-	q.fonts = append(q.fonts, fh)
+	q.addFont(fh, objects)
 	return fh, nil
 }
 
-// NewTrueTypeFont adds and returns a new true type font
-func (q *File) NewTrueTypeFont(ttf []byte, encoding types.Encoding, embed bool) (*TrueTypeFont, error) {
-	// parse font by unitype
-	fnt, err := unitype.Parse(bytes.NewReader(ttf))
+// NewType1Font adds and returns a new PostScript Type 1 font given as font program (PFB or PFA) and font metrics (AFM).
+// The font program is embedded. If encoding is nil, the built-in encoding of the font is used.
+func (q *File) NewType1Font(program, afmData []byte, encoding types.SimpleEncoding) (*Type1Font, error) {
+	var objects []types.Reference
+	if encoding == nil {
+		encoding = types.Encoding("")
+	}
+
+	// parse font program and metrics
+	prog, err := type1.Parse(program)
 	if err != nil {
 		return nil, err
 	}
-	metrics := fnt.GetMetrics()
+	metrics, err := afm.Parse(bytes.NewReader(afmData))
+	if err != nil {
+		return nil, err
+	}
+	if metrics.FontName != prog.FontName {
+		return nil, errors.New("font metrics of " + metrics.FontName + " do not belong to font " + prog.FontName)
+	}
 
-	// embed font
-	var fontFileRef types.Reference
-	if embed {
-		ttfStream, err := types.NewStream(ttf)
+	// embed font program
+	bts := prog.Bytes()
+	fontFile, err := types.NewStream(bts)
+	if err != nil {
+		return nil, err
+	}
+	fontFile.Dictionary = types.Dictionary{
+		"Length":  types.Int(len(bts)),
+		"Length1": types.Int(len(prog.Clear)),
+		"Length2": types.Int(len(prog.Encrypted)),
+		"Length3": types.Int(len(prog.Trailer)),
+	}
+
+	// create font descriptor
+	var flags int
+	if metrics.Direction[0].IsFixedPitch {
+		flags += 1 << 0
+	}
+	if metrics.EncodingScheme == "FontSpecific" {
+		flags += 1 << 2
+	} else {
+		flags += 1 << 5
+	}
+	if metrics.Direction[0].ItalicAngle != 0 {
+		flags += 1 << 6
+	}
+	stemV := metrics.StdVW.Float64()
+	if stemV == 0 {
+		weight := 400
+		if strings.Contains(strings.ToLower(metrics.Weight), "bold") {
+			weight = 700
+		}
+		stemV = float64(calcStemV(weight))
+	}
+	fd := types.FontDescriptor{
+		FontName: types.Name(metrics.FontName),
+		Flags:    types.Int(flags),
+		FontBBox: types.Rectangle{
+			LLX: types.Number(metrics.BBox.LLX.Float64()),
+			LLY: types.Number(metrics.BBox.LLY.Float64()),
+			URX: types.Number(metrics.BBox.URX.Float64()),
+			URY: types.Number(metrics.BBox.URY.Float64()),
+		},
+		ItalicAngle: types.Number(metrics.Direction[0].ItalicAngle.Float64()),
+		Ascent:      types.Number(metrics.Ascender.Float64()),
+		Descent:     types.Number(metrics.Descender.Float64()),
+		CapHeight:   types.Number(metrics.CapHeight.Float64()),
+		XHeight:     types.Number(metrics.XHeight.Float64()),
+		StemV:       types.Number(stemV),
+		FontFile:    q.addFontObject(&objects, fontFile),
+	}
+
+	// create font
+	f := types.Font{
+		Subtype:        types.FontSub_Type1,
+		BaseFont:       fd.FontName,
+		Encoding:       encoding.EncodingEntry(),
+		FirstChar:      -1,
+		FontDescriptor: q.addFontObject(&objects, fd),
+	}
+	names := make([]string, 256)
+	for c := range names {
+		if names[c] = encoding.GlyphName(byte(c)); names[c] == "" {
+			names[c] = metrics.GlyphName(c)
+		}
+		if names[c] == "" {
+			continue
+		}
+		if f.FirstChar < 0 {
+			f.FirstChar = types.Int(c)
+		}
+		f.LastChar = types.Int(c)
+	}
+	if f.FirstChar < 0 {
+		return nil, errors.New("font " + metrics.FontName + " has no encoded glyphs")
+	}
+	var widths types.Array
+	for c := f.FirstChar; c <= f.LastChar; c++ {
+		widths = append(widths, types.Int(metrics.GetGlyphAdvanceByName(names[c])))
+	}
+	f.Widths = widths
+	if _, ok := f.Encoding.(types.EncodingDictionary); ok {
+		toUnicode, err := q.addSimpleEncodingToUnicode(encoding)
 		if err != nil {
 			return nil, err
 		}
-		fontFileRef = q.creator.AddObject(ttfStream)
+		f.ToUnicode = toUnicode
+		objects = append(objects, toUnicode)
 	}
 
+	// create Type1Font object
+	fh := &Type1Font{StandardFont{
+		fontReference: fontReference{reference: q.addFontObject(&objects, f)},
+		encoding:      encoding,
+		metrics:       &metrics,
+	}}
+	q.addFont(fh, objects)
+	return fh, nil
+}
+
+// NewTrueTypeFont adds and returns a new true type font with a predefined encoding. If embed is true, a subset of the
+// font with the glyphs of the characters used is embedded. WOFF files are decoded. Of font collections, the first font
+// is used, see NewTrueTypeFontFromCollection to select another one.
+func (q *File) NewTrueTypeFont(ttf []byte, encoding types.Encoding, embed bool) (*TrueTypeFont, error) {
+	return q.NewTrueTypeFontWithEncoding(ttf, encoding, embed)
+}
+
+// NewTrueTypeFontWithEncoding is like NewTrueTypeFont with a predefined or custom encoding
+func (q *File) NewTrueTypeFontWithEncoding(ttf []byte, encoding types.SimpleEncoding, embed bool) (*TrueTypeFont, error) {
+	var objects []types.Reference
+	if encoding == nil {
+		encoding = types.Encoding("")
+	}
+	ttf, err := decodeFontFile(ttf, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// parse font by unitype
+	fnt, err := unitype.Parse(bytes.NewReader(ttf))
+	if err != nil {
+		return nil, err
+	}
+	metrics := fnt.GetMetrics()
+
 	// create font descriptor
 	var flags int
 	if metrics.IsFixedPitch {
@@ -73,45 +244,158 @@ func (q *File) NewTrueTypeFont(ttf []byte, encoding types.Encoding, embed bool)
 		XHeight:      types.Number(metrics.XHeight),
 		StemV:        types.Number(calcStemV(metrics.Weight)),
 		MissingWidth: types.Number(metrics.DefaultWidth),
-		FontFile2:    fontFileRef,
 	}
-	fdRef := q.creator.AddObject(fd)
+	fdRef := q.addFontObject(&objects, &fd)
 
 	// create font
 	f := types.Font{
 		Subtype:        types.FontSub_TrueType,
 		BaseFont:       fd.FontName,
-		Encoding:       encoding,
+		Encoding:       encoding.EncodingEntry(),
 		FirstChar:      32,
 		LastChar:       255,
 		FontDescriptor: fdRef,
 	}
+	for c := 1; c < 32; c++ {
+		if encoding.GlyphName(byte(c)) != "" {
+			f.FirstChar = types.Int(c)
+			break
+		}
+	}
+	codeRune := func(c byte) rune {
+		if r := encoding.Rune(c); r != 0 {
+			return r
+		}
+		return rune(c)
+	}
 	var widths types.Array
 	for i := f.FirstChar; i <= f.LastChar; i++ {
-		index := fnt.LookupRunes([]rune{rune(i)})
+		index := fnt.LookupRunes([]rune{codeRune(byte(i))})
 		w := fnt.GetGlyphAdvance(index[0])
 		widths = append(widths, types.Int(w))
 	}
 	f.Widths = widths
+	if _, ok := f.Encoding.(types.EncodingDictionary); ok {
+		toUnicode, err := q.addSimpleEncodingToUnicode(encoding)
+		if err != nil {
+			return nil, err
+		}
+		f.ToUnicode = toUnicode
+		objects = append(objects, toUnicode)
+	}
 
 	// create TrueTypeFont object
 	fh := &TrueTypeFont{
-		reference: q.creator.AddObject(f),
-		encoding:  encoding,
-		font:      fnt,
-		metrics:   metrics,
+		fontReference: fontReference{reference: q.addFontObject(&objects, &f)},
+		encoding:      encoding,
+		font:          fnt,
+		metrics:       metrics,
 	}
-	q.fonts = append(q.fonts, fh)
+
+	// embed subset of the font with the glyphs of the character codes used
+	if embed {
+		fh.usedRunes = make(map[rune]struct{})
+		fh.onFinish = func() error {
+			runes := make([]rune, 0, len(fh.usedRunes))
+			for r := range fh.usedRunes {
+				for _, c := range []byte(encoding.Encode(string(r))) {
+					runes = append(runes, codeRune(c))
+				}
+			}
+			indices := append(fnt.LookupRunes(runes), 0)
+			newFont, err := fnt.SubsetKeepIndices(indices)
+			if err != nil {
+				return err
+			}
+			var bts bytes.Buffer
+			if err := newFont.Write(&bts); err != nil {
+				return err
+			}
+			fileStream, err := types.NewStream(bts.Bytes())
+			if err != nil {
+				return err
+			}
+			fd.FontFile2 = q.creator.AddObject(fileStream)
+			fd.FontName = types.Name(subsetTag(indices) + "+" + string(fd.FontName))
+			f.BaseFont = fd.FontName
+			return nil
+		}
+	}
+	q.addFont(fh, objects)
 	return fh, nil
 }
 
-// NewCompositeFontFromTTF creates a new composite front from the given true type font
+// NewTrueTypeFontFromCollection is like NewTrueTypeFontWithEncoding with the font with the given index of a font
+// collection (ttc), see unitype.ParseFaces and unitype.CollectionFontIndex to find the index of a font.
+func (q *File) NewTrueTypeFontFromCollection(ttc []byte, index int, encoding types.SimpleEncoding, embed bool) (*TrueTypeFont, error) {
+	ttf, err := decodeFontFile(ttc, index)
+	if err != nil {
+		return nil, err
+	}
+	return q.NewTrueTypeFontWithEncoding(ttf, encoding, embed)
+}
+
+// NewCompositeFontFromTTF creates a new composite front from the given true type font, WOFF files are decoded. Of font
+// collections, the first font is used, see NewCompositeFontFromCollection to select another one.
 func (q *File) NewCompositeFontFromTTF(ttf []byte, fallback FontHandler) (*CompositeFont, error) {
+	ttf, err := decodeFontFile(ttf, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	// parse font by unitype
 	fnt, err := unitype.Parse(bytes.NewReader(ttf))
 	if err != nil {
 		return nil, err
 	}
+	return q.newCompositeFont(fnt, fnt.GetNameByID(1), fallback)
+}
+
+// NewCompositeFontFromCollection is like NewCompositeFontFromTTF with the font with the given index of a font
+// collection (ttc), see unitype.ParseFaces and unitype.CollectionFontIndex to find the index of a font.
+func (q *File) NewCompositeFontFromCollection(ttc []byte, index int, fallback FontHandler) (*CompositeFont, error) {
+	ttf, err := decodeFontFile(ttc, index)
+	if err != nil {
+		return nil, err
+	}
+	return q.NewCompositeFontFromTTF(ttf, fallback)
+}
+
+// NewCompositeFontFromVariableTTF creates a new composite font from an instance of the given variable true type font.
+// coords contains the values of the design axes by tag, e.g. {"wght": 700, "wdth": 75, "slnt": -10}, axes not given
+// keep their default value.
+func (q *File) NewCompositeFontFromVariableTTF(ttf []byte, coords map[string]float64, fallback FontHandler) (*CompositeFont, error) {
+	ttf, err := decodeFontFile(ttf, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// parse font by unitype and create the instance
+	fnt, err := unitype.Parse(bytes.NewReader(ttf))
+	if err != nil {
+		return nil, err
+	}
+	instance, err := fnt.Instance(coords)
+	if err != nil {
+		return nil, err
+	}
+
+	// name of the instance by the axis values
+	name := fnt.GetNameByID(1)
+	tags := make([]string, 0, len(coords))
+	for t := range coords {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	for _, t := range tags {
+		name += "-" + t + strconv.FormatFloat(coords[t], 'f', -1, 64)
+	}
+	return q.newCompositeFont(instance, name, fallback)
+}
+
+// newCompositeFont creates a new composite font from the font parsed by unitype
+func (q *File) newCompositeFont(fnt *unitype.Font, name string, fallback FontHandler) (*CompositeFont, error) {
+	var objects []types.Reference
 	metrics := fnt.GetMetrics()
 
 	// create font descriptor
@@ -124,7 +408,7 @@ func (q *File) NewCompositeFontFromTTF(ttf []byte, fallback FontHandler) (*Compo
 	}
 	flags += 1 << 5
 	fd := types.FontDescriptor{
-		FontName: types.Name(fnt.GetNameByID(1)),
+		FontName: types.Name(name),
 		Flags:    types.Int(flags),
 		FontBBox: types.Rectangle{
 			LLX: types.Number(metrics.XMin),
@@ -140,33 +424,48 @@ func (q *File) NewCompositeFontFromTTF(ttf []byte, fallback FontHandler) (*Compo
 		StemV:        types.Number(calcStemV(metrics.Weight)),
 		MissingWidth: types.Number(metrics.DefaultWidth),
 	}
-	fdRef := q.creator.AddObject(&fd)
+	fdRef := q.addFontObject(&objects, &fd)
 
 	// create CID font
 	cid := &types.CIDFont{
 		Subtype:        types.FontSub_CIDFontType2,
 		BaseFont:       fd.FontName,
-		CIDSystemInfo:  q.getCIDSystemInfo(),
 		FontDescriptor: fdRef,
 		DW:             types.Int(750),
 	}
-	cidRef := q.creator.AddObject(cid)
+	cidRef := q.addFontObject(&objects, cid)
 	f := types.Type0Font{
 		BaseFont:        cid.BaseFont,
 		Encoding:        types.Name("Identity-H"),
 		DescendantFonts: types.Array{cidRef},
-		ToUnicode:       q.getToUnicode(),
 	}
 
 	// create CompositeFont object
 	fh := &CompositeFont{
-		reference:    q.creator.AddObject(f),
-		usedRunes:    make(map[rune]struct{}),
-		font:         fnt,
-		metrics:      fnt.GetMetrics(),
-		fallbackFont: fallback,
+		fontReference: fontReference{reference: q.addFontObject(&objects, &f)},
+		usedRunes:     make(map[rune]struct{}),
+		usedGlyphs:    make(map[unitype.GlyphIndex][]rune),
+		font:          fnt,
+		metrics:       fnt.GetMetrics(),
+	}
+	if fallback != nil {
+		fh.fallbacks = []FontHandler{fallback}
 	}
 	fh.onFinish = func() error {
+		cid.CIDSystemInfo = q.getCIDSystemInfo()
+
+		// shaped text is encoded by glyph IDs
+		if fh.shaping() {
+			if err := q.finishShapedFont(fnt, fh.usedGlyphs, &fd, cid, &f); err != nil {
+				return err
+			}
+			if fh.vertical {
+				q.finishVerticalFont(fnt, fh.usedGlyphs, cid, &f)
+			}
+			return nil
+		}
+		f.ToUnicode = q.getToUnicode()
+
 		// determine highest rune number
 		var maxRune rune
 		runes := make([]rune, 0, len(fh.usedRunes))
@@ -213,12 +512,118 @@ func (q *File) NewCompositeFontFromTTF(ttf []byte, fallback FontHandler) (*Compo
 		cid.W = widths
 		return nil
 	}
-	q.fonts = append(q.fonts, fh)
+	q.addFont(fh, objects)
 	return fh, nil
 }
 
-// NewCompositeFontFromOTF creates a new composite front from the given open type font
+// finishShapedFont embeds the subsetted font and creates widths and ToUnicode mapping for fonts using glyph IDs
+// as character codes
+func (q *File) finishShapedFont(fnt *unitype.Font, usedGlyphs map[unitype.GlyphIndex][]rune, fd *types.FontDescriptor,
+	cid *types.CIDFont, f *types.Type0Font) error {
+	indices := make([]unitype.GlyphIndex, 0, len(usedGlyphs))
+	for gid := range usedGlyphs {
+		indices = append(indices, gid)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	// add subsetted font to document
+	newFont, err := fnt.SubsetKeepIndices(indices)
+	if err != nil {
+		return err
+	}
+	var bts bytes.Buffer
+	if err := newFont.Write(&bts); err != nil {
+		return err
+	}
+	fileStream, err := types.NewStream(bts.Bytes())
+	if err != nil {
+		return err
+	}
+	fd.FontFile2 = q.creator.AddObject(fileStream)
+
+	// widths
+	widths := types.Array{}
+	for _, gid := range indices {
+		w := fnt.GetGlyphAdvance(gid)
+		widths = append(widths, types.Int(gid), types.Int(gid), types.Int(w))
+	}
+	cid.CIDToGIDMap = types.Name("Identity")
+	cid.W = widths
+
+	f.ToUnicode, err = q.addGlyphToUnicode(indices, usedGlyphs)
+	return err
+}
+
+// addGlyphToUnicode adds the ToUnicode mapping of fonts using glyph IDs as character codes
+func (q *File) addGlyphToUnicode(indices []unitype.GlyphIndex, usedGlyphs map[unitype.GlyphIndex][]rune) (types.Reference, error) {
+	var cmap bytes.Buffer
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n/CIDSystemInfo\n<</Registry (Adobe)\n/Ordering (UCS)\n/Supplement 0\n>> def\n/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	var mapped []unitype.GlyphIndex
+	for _, gid := range indices {
+		if len(usedGlyphs[gid]) != 0 {
+			mapped = append(mapped, gid)
+		}
+	}
+	for len(mapped) != 0 {
+		n := len(mapped)
+		if n > 100 {
+			n = 100
+		}
+		cmap.WriteString(strconv.Itoa(n) + " beginbfchar\n")
+		for _, gid := range mapped[:n] {
+			cmap.WriteString(fmt.Sprintf("<%04X> <", int(gid)))
+			for _, c := range utf16.Encode(usedGlyphs[gid]) {
+				cmap.WriteString(fmt.Sprintf("%04X", c))
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+		mapped = mapped[n:]
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend")
+	toUnicode, err := types.NewStream(cmap.Bytes())
+	if err != nil {
+		return types.Reference{}, err
+	}
+	return q.creator.AddObject(toUnicode), nil
+}
+
+// finishVerticalFont switches the font to vertical writing and adds the vertical metrics of the used glyphs
+func (q *File) finishVerticalFont(fnt *unitype.Font, usedGlyphs map[unitype.GlyphIndex][]rune, cid *types.CIDFont,
+	f *types.Type0Font) {
+	f.Encoding = types.Name("Identity-V")
+
+	// default metrics: origin at the ascender, advance of the font's height
+	advance, originY, _ := fnt.GetGlyphVerticalMetrics(0)
+	cid.DW2 = types.Array{types.Int(originY), types.Int(-advance)}
+	if !fnt.HasVerticalMetrics() {
+		return
+	}
+
+	// individual metrics: vertical advance and position vector of each glyph
+	indices := make([]unitype.GlyphIndex, 0, len(usedGlyphs))
+	for gid := range usedGlyphs {
+		indices = append(indices, gid)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	metrics := types.Array{}
+	for _, gid := range indices {
+		advance, originY, _ := fnt.GetGlyphVerticalMetrics(gid)
+		metrics = append(metrics, types.Int(gid), types.Int(gid), types.Int(-advance),
+			types.Int(fnt.GetGlyphAdvance(gid)/2), types.Int(originY))
+	}
+	cid.W2 = metrics
+}
+
+// NewCompositeFontFromOTF creates a new composite front from the given open type font. Of font collections, the first
+// font is used, see NewCompositeFontFromOTFCollection to select another one.
 func (q *File) NewCompositeFontFromOTF(otf []byte) (*CompositeFontOTF, error) {
+	var objects []types.Reference
+	otf, err := decodeFontFile(otf, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	// parse font by sfnt (supports otf)
 	fnt, err := sfnt.Parse(otf)
 	if err != nil {
@@ -237,6 +642,11 @@ func (q *File) NewCompositeFontFromOTF(otf []byte) (*CompositeFontOTF, error) {
 		return nil, err
 	}
 
+	// fonts with CFF outlines are subsetted
+	if cffFont, err := cff.ParseOpenType(otf); err == nil {
+		return q.newCompositeFontFromCFF(fnt, cffFont, metrics, bounds, name)
+	}
+
 	otfStream, err := types.NewStream(otf)
 	if err != nil {
 		return nil, err
@@ -246,7 +656,7 @@ func (q *File) NewCompositeFontFromOTF(otf []byte) (*CompositeFontOTF, error) {
 		"Length3": types.Int(len(otf)),
 		"Subtype": types.Name("OpenType"),
 	}
-	fontFileRef := q.creator.AddObject(otfStream)
+	fontFileRef := q.addFontObject(&objects, otfStream)
 
 	// create font descriptor
 	var flags int
@@ -275,33 +685,34 @@ func (q *File) NewCompositeFontFromOTF(otf []byte) (*CompositeFontOTF, error) {
 		//MissingWidth: types.Number(font.GetGlyphAdvance(0)), // todo
 		FontFile3: fontFileRef,
 	}
-	fdRef := q.creator.AddObject(&fd)
+	fdRef := q.addFontObject(&objects, &fd)
 
 	// create CID font
 	cid := &types.CIDFont{
 		Subtype:        types.FontSub_CIDFontType2,
 		BaseFont:       fd.FontName,
-		CIDSystemInfo:  q.getCIDSystemInfo(),
 		FontDescriptor: fdRef,
 		DW:             types.Int(750),
 	}
-	cidRef := q.creator.AddObject(cid)
+	cidRef := q.addFontObject(&objects, cid)
 	f := types.Type0Font{
 		BaseFont:        cid.BaseFont,
 		Encoding:        types.Name("Identity-H"),
 		DescendantFonts: types.Array{cidRef},
-		ToUnicode:       q.getToUnicode(),
 	}
 
 	// create CompositeFont object
 	fh := &CompositeFontOTF{
-		reference: q.creator.AddObject(f),
-		usedRunes: make(map[rune]struct{}),
-		font:      fnt,
-		metrics:   metrics,
-		bounds:    bounds,
+		fontReference: fontReference{reference: q.addFontObject(&objects, &f)},
+		usedRunes:     make(map[rune]struct{}),
+		font:          fnt,
+		metrics:       metrics,
+		bounds:        bounds,
 	}
 	fh.onFinish = func() error {
+		cid.CIDSystemInfo = q.getCIDSystemInfo()
+		f.ToUnicode = q.getToUnicode()
+
 		// determine highest rune number
 		var maxRune rune
 		runes := make([]rune, 0, len(fh.usedRunes))
@@ -331,10 +742,363 @@ func (q *File) NewCompositeFontFromOTF(otf []byte) (*CompositeFontOTF, error) {
 		cid.W = widths
 		return nil
 	}
-	q.fonts = append(q.fonts, fh)
+	q.addFont(fh, objects)
+	return fh, nil
+}
+
+// NewCompositeFontFromOTFCollection is like NewCompositeFontFromOTF with the font with the given index of a font
+// collection (otc), see unitype.ParseFaces and unitype.CollectionFontIndex to find the index of a font.
+func (q *File) NewCompositeFontFromOTFCollection(otc []byte, index int) (*CompositeFontOTF, error) {
+	otf, err := decodeFontFile(otc, index)
+	if err != nil {
+		return nil, err
+	}
+	return q.NewCompositeFontFromOTF(otf)
+}
+
+// newCompositeFontFromCFF creates a composite font from an open type font with CFF outlines, which is embedded as
+// subsetted CFF font program. Text is encoded by glyph IDs.
+func (q *File) newCompositeFontFromCFF(fnt *sfnt.Font, cffFont *cff.Font, metrics font.Metrics,
+	bounds fixed.Rectangle26_6, name string) (*CompositeFontOTF, error) {
+	var objects []types.Reference
+	// create font descriptor
+	fd := types.FontDescriptor{
+		FontName: types.Name(name),
+		Flags:    types.Int(1 << 5),
+		FontBBox: types.Rectangle{
+			LLX: types.Number(bounds.Min.X),
+			LLY: types.Number(bounds.Min.Y),
+			URX: types.Number(bounds.Max.X),
+			URY: types.Number(bounds.Max.Y),
+		},
+		Ascent:    types.Number(metrics.Ascent),
+		Descent:   types.Number(metrics.Descent),
+		CapHeight: types.Number(metrics.CapHeight),
+		XHeight:   types.Number(metrics.XHeight),
+	}
+	fdRef := q.addFontObject(&objects, &fd)
+
+	// create CID font, CIDs are glyph IDs
+	cid := &types.CIDFont{
+		Subtype:        types.FontSub_CIDFontType0,
+		BaseFont:       fd.FontName,
+		FontDescriptor: fdRef,
+		DW:             types.Int(750),
+	}
+	cidRef := q.addFontObject(&objects, cid)
+	f := types.Type0Font{
+		BaseFont:        cid.BaseFont,
+		Encoding:        types.Name("Identity-H"),
+		DescendantFonts: types.Array{cidRef},
+	}
+
+	// create CompositeFont object
+	fh := &CompositeFontOTF{
+		fontReference: fontReference{reference: q.addFontObject(&objects, &f)},
+		usedRunes:     make(map[rune]struct{}),
+		usedGlyphs:    make(map[unitype.GlyphIndex][]rune),
+		font:          fnt,
+		cff:           cffFont,
+		metrics:       metrics,
+		bounds:        bounds,
+	}
+	fh.onFinish = func() error {
+		cid.CIDSystemInfo = q.getCIDSystemInfo()
+
+		indices := make([]unitype.GlyphIndex, 0, len(fh.usedGlyphs))
+		gids := make([]uint16, 0, len(fh.usedGlyphs))
+		for gid := range fh.usedGlyphs {
+			indices = append(indices, gid)
+		}
+		sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+		for _, gid := range indices {
+			gids = append(gids, uint16(gid))
+		}
+
+		// add subsetted font to document
+		bts, err := cffFont.Subset(gids)
+		if err != nil {
+			return err
+		}
+		fileStream, err := types.NewStream(bts)
+		if err != nil {
+			return err
+		}
+		fileStream.Dictionary = types.Dictionary{
+			"Length":  types.Int(len(bts)),
+			"Subtype": types.Name("CIDFontType0C"),
+		}
+		fd.FontFile3 = q.creator.AddObject(fileStream)
+
+		// widths from the CFF font
+		widths := types.Array{}
+		for _, gid := range gids {
+			w, err := cffFont.GlyphWidth(gid)
+			if err != nil {
+				return err
+			}
+			widths = append(widths, types.Int(gid), types.Int(gid), types.Number(w))
+		}
+		cid.W = widths
+
+		f.ToUnicode, err = q.addGlyphToUnicode(indices, fh.usedGlyphs)
+		return err
+	}
+	q.addFont(fh, objects)
 	return fh, nil
 }
 
+// NewColorFont creates a new Type 3 font from the given true type font with color glyphs, e.g. an emoji font using the
+// COLR/CPAL, sbix or CBDT table. Use it as fallback font of a composite font to show emojis in regular text.
+func (q *File) NewColorFont(ttf []byte) (*ColorFont, error) {
+	var objects []types.Reference
+	ttf, err := decodeFontFile(ttf, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// parse font by unitype
+	fnt, err := unitype.Parse(bytes.NewReader(ttf))
+	if err != nil {
+		return nil, err
+	}
+
+	f := types.Type3Font{
+		FontMatrix: types.Array{types.Number(0.001), types.Int(0), types.Int(0), types.Number(0.001), types.Int(0), types.Int(0)},
+	}
+	fh := &ColorFont{
+		fontReference: fontReference{reference: q.addFontObject(&objects, &f)},
+		font:          fnt,
+		metrics:       fnt.GetMetrics(),
+		codes:         map[unitype.GlyphIndex]byte{0: 0},
+		glyphs:        []unitype.GlyphIndex{0},
+		usedGlyphs:    make(map[unitype.GlyphIndex][]rune),
+	}
+	fh.onFinish = func() error {
+		// glyph descriptions share the resources
+		p := NewPage(0, 0)
+		scale := 1000 / float64(fnt.UnitsPerEm())
+		glyphs := make([]type3Glyph, len(fh.glyphs))
+		for c, gid := range fh.glyphs {
+			w := fnt.GetGlyphAdvance(gid)
+			glyphs[c] = type3Glyph{name: types.Name("g" + strconv.Itoa(int(gid))), width: float64(w)}
+			if c != 0 && gid != 0 {
+				glyphs[c].runes = fh.usedGlyphs[gid]
+			}
+
+			p.contents = nil
+			p.graphicsState = &graphicsState{}
+			p.Type3Font_d0(float64(w), 0)
+			if layers := fnt.ColorLayers(gid); len(layers) != 0 {
+				for _, layer := range layers {
+					outline, err := fnt.GlyphOutline(layer.Glyph)
+					if err != nil {
+						return err
+					}
+					if len(outline) == 0 {
+						continue
+					}
+					p.GraphicsState_q()
+					if !layer.Foreground {
+						p.Color_rg(float64(layer.Color.R)/255, float64(layer.Color.G)/255, float64(layer.Color.B)/255)
+						if layer.Color.A != 255 {
+							p.GraphicsState_gs(p.AddExtGState(types.Dictionary{"ca": types.Number(float64(layer.Color.A) / 255)}))
+						}
+					}
+					drawOutline(p, outline, [6]float64{scale, 0, 0, scale, 0, 0})
+					p.Path_f()
+					p.GraphicsState_Q()
+				}
+			} else if bitmap := fnt.GlyphBitmap(gid); bitmap != nil {
+				img, err := q.NewImage(bitmap.PNG)
+				if err != nil {
+					return err
+				}
+				s := 1000 / float64(bitmap.PixelsPerEm)
+				p.GraphicsState_q()
+				p.GraphicsState_cm(float64(bitmap.Width)*s, 0, 0, float64(bitmap.Height)*s, float64(bitmap.Left)*s, float64(bitmap.Bottom)*s)
+				p.XObject_Do(img.Reference)
+				p.GraphicsState_Q()
+			} else {
+				outline, err := fnt.GlyphOutline(gid)
+				if err != nil {
+					return err
+				}
+				if len(outline) != 0 {
+					drawOutline(p, outline, [6]float64{scale, 0, 0, scale, 0, 0})
+					p.Path_f()
+				}
+			}
+			glyphs[c].contents = p.contents
+		}
+		return q.finishType3Font(&f, 0, glyphs, p.Data.Resources)
+	}
+	q.addFont(fh, objects)
+	return fh, nil
+}
+
+// NewType3Font creates a new Type 3 font, glyphs are added by Type3Font.AddGlyph or Type3Font.AddGlyphSVG. ascent and
+// descent (negative) are given in the glyph coordinate system of 1000 units per em and determine the line height.
+func (q *File) NewType3Font(ascent, descent float64) *Type3Font {
+	var objects []types.Reference
+	f := types.Type3Font{
+		FontMatrix: types.Array{types.Number(0.001), types.Int(0), types.Int(0), types.Number(0.001), types.Int(0), types.Int(0)},
+	}
+	fh := &Type3Font{
+		fontReference: fontReference{reference: q.addFontObject(&objects, &f)},
+		ascent:        ascent,
+		descent:       descent,
+		page:          NewPage(0, 0),
+		codes:         make(map[rune]byte),
+	}
+	fh.onFinish = func() error {
+		glyphs := fh.glyphs
+		if len(glyphs) == 0 {
+			glyphs = []type3Glyph{{name: ".notdef", contents: [][]byte{[]byte("0 0 d0")}}}
+		}
+		return q.finishType3Font(&f, 1, glyphs, fh.page.Data.Resources)
+	}
+	q.addFont(fh, objects)
+	return fh
+}
+
+// type3Glyph is the glyph description of a character code of a Type 3 font
+type type3Glyph struct {
+	name     types.Name
+	width    float64
+	contents [][]byte
+	runes    []rune
+}
+
+// finishType3Font adds the glyph descriptions, encoding, widths and ToUnicode mapping to a Type 3 font. glyphs contains
+// the glyphs of the character codes starting at firstChar, glyph descriptions of the same name are added only once.
+func (q *File) finishType3Font(f *types.Type3Font, firstChar int, glyphs []type3Glyph, resources types.Object) error {
+	charProcs := types.Dictionary{}
+	differences := types.Array{types.Int(firstChar)}
+	widths := types.Array{}
+	runes := make([][]rune, firstChar+len(glyphs))
+	for i, g := range glyphs {
+		differences = append(differences, g.name)
+		widths = append(widths, types.Number(g.width))
+		runes[firstChar+i] = g.runes
+		if _, ok := charProcs[g.name]; ok {
+			continue
+		}
+		ref, err := newContentStream(q.creator, g.contents, q.CompressStreamsThreshold)
+		if err != nil {
+			return err
+		}
+		charProcs[g.name] = ref
+	}
+
+	f.CharProcs = charProcs
+	f.Encoding = types.Dictionary{"Type": types.Name("Encoding"), "Differences": differences}
+	f.FirstChar = types.Int(firstChar)
+	f.LastChar = types.Int(firstChar + len(glyphs) - 1)
+	f.Widths = widths
+	f.Resources = resources
+
+	var err error
+	f.ToUnicode, err = q.addByteToUnicode(runes)
+	return err
+}
+
+// addSimpleEncodingToUnicode adds the ToUnicode mapping of a simple font with custom encoding, since pdf readers do not
+// necessarily know the Unicode values of the glyph names
+func (q *File) addSimpleEncodingToUnicode(encoding types.SimpleEncoding) (types.Reference, error) {
+	runes := make([][]rune, 256)
+	for c := range runes {
+		if r := encoding.Rune(byte(c)); r != 0 {
+			runes[c] = []rune{r}
+		}
+	}
+	return q.addByteToUnicode(runes)
+}
+
+// addByteToUnicode adds the ToUnicode mapping of simple fonts, runes contains the characters by character code
+func (q *File) addByteToUnicode(runes [][]rune) (types.Reference, error) {
+	var cmap bytes.Buffer
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n/CIDSystemInfo\n<</Registry (Adobe)\n/Ordering (UCS)\n/Supplement 0\n>> def\n/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n1 begincodespacerange\n<00> <FF>\nendcodespacerange\n")
+	var codes []int
+	for c, r := range runes {
+		if len(r) != 0 {
+			codes = append(codes, c)
+		}
+	}
+	for len(codes) != 0 {
+		n := len(codes)
+		if n > 100 {
+			n = 100
+		}
+		cmap.WriteString(strconv.Itoa(n) + " beginbfchar\n")
+		for _, c := range codes[:n] {
+			cmap.WriteString(fmt.Sprintf("<%02X> <", c))
+			for _, u := range utf16.Encode(runes[c]) {
+				cmap.WriteString(fmt.Sprintf("%04X", u))
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+		codes = codes[n:]
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend")
+	toUnicode, err := types.NewStream(cmap.Bytes())
+	if err != nil {
+		return types.Reference{}, err
+	}
+	return q.creator.AddObject(toUnicode), nil
+}
+
+// decodeFontFile returns the font of a font file: WOFF files are decoded, of font collections the font with the given
+// index is returned, other fonts unchanged
+func decodeFontFile(data []byte, index int) ([]byte, error) {
+	if unitype.IsWOFF(data) {
+		var err error
+		if data, err = unitype.DecodeWOFF(data); err != nil {
+			return nil, err
+		}
+	}
+	if !unitype.IsCollection(data) {
+		if index != 0 {
+			return nil, errors.New("font file is not a font collection")
+		}
+		return data, nil
+	}
+	return unitype.ExtractCollectionFont(data, index)
+}
+
+// addFontObject adds an object created for a font and appends its reference to objects. Images may be added by
+// NewImageConcurrent at the same time, so the objects of a font are not necessarily consecutive.
+func (q *File) addFontObject(objects *[]types.Reference, obj types.Object) types.Reference {
+	q.newImageMux.Lock()
+	defer q.newImageMux.Unlock()
+	ref := q.creator.AddObject(obj)
+	*objects = append(*objects, ref)
+	return ref
+}
+
+// addFont registers a new font with the objects added for it, which are removed if the font is never used
+func (q *File) addFont(fh FontHandler, objects []types.Reference) {
+	q.fonts = append(q.fonts, fileFont{handler: fh, objects: objects})
+}
+
+// finishFonts finishes the fonts used in the document and removes the objects of fonts never used, e.g. because all
+// text is drawn as glyph outlines
+func (q *File) finishFonts() error {
+	var unused []types.Reference
+	for _, f := range q.fonts {
+		if !f.handler.used() {
+			unused = append(unused, f.objects...)
+			continue
+		}
+		if err := f.handler.finish(); err != nil {
+			return err
+		}
+	}
+	q.creator.RemoveObjects(unused)
+	return nil
+}
+
 // copied from fpdf.php
 func (q *File) getCIDSystemInfo() types.Reference {
 	if q.cidSystemInfo.Number == 0 {
@@ -356,6 +1120,23 @@ func (q *File) getToUnicode() types.Reference {
 	return q.toUnicode
 }
 
+// subsetTag returns the tag of a font subset: six upper-case letters derived from the glyphs of the subset
+func subsetTag(indices []unitype.GlyphIndex) string {
+	sorted := append([]unitype.GlyphIndex{}, indices...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	h := fnv.New32a()
+	for _, gid := range sorted {
+		_, _ = h.Write([]byte{byte(gid >> 8), byte(gid)})
+	}
+	v := h.Sum32()
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + byte(v%26)
+		v /= 26
+	}
+	return string(tag)
+}
+
 func calcStemV(weight int) int {
 	f := float64(weight) / 65
 	return int(50 + (f * f) + 0.5)
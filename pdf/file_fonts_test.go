@@ -0,0 +1,228 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"regexp"
+	"testing"
+
+	"github.com/raceresult/gopdf/parser"
+	"github.com/raceresult/gopdf/pdf/unitype"
+	"github.com/raceresult/gopdf/types"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestNewStandardFontEncoding(t *testing.T) {
+	f := NewFile()
+
+	// untyped string constants are still accepted as encoding
+	win, err := f.NewStandardFont(types.StandardFont_Helvetica, "WinAnsiEncoding")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := win.Encode("€"); got != "\x80" {
+		t.Errorf("WinAnsiEncoding: got %q, want %q", got, "\x80")
+	}
+
+	custom, err := types.NewCustomEncoding(types.EncodingWinAnsi, map[rune]string{'Ł': "Lslash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := f.NewStandardFontWithEncoding(types.StandardFont_Helvetica, custom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := custom.Encode("Ł")
+	if len(want) != 1 || want == "?" {
+		t.Fatalf("custom encoding does not contain Ł: %q", want)
+	}
+	if got := font.Encode("Ł"); got != want {
+		t.Errorf("custom encoding: got %q, want %q", got, want)
+	}
+}
+
+func TestUnusedFontInterleavedObjects(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+
+	// an image added by NewImageConcurrent while an unused font is created lies between the objects of the font
+	f := NewFile()
+	var objects []types.Reference
+	fd := f.addFontObject(&objects, types.Dictionary{"Type": types.Name("FontDescriptor")})
+	img, err := f.NewImageConcurrent(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	font := &StandardFont{fontReference: fontReference{reference: f.addFontObject(&objects, types.Dictionary{
+		"Type":           types.Name("Font"),
+		"FontDescriptor": fd,
+	})}}
+	f.addFont(font, objects)
+	f.NewPage(595, 842)
+	bts, err := f.Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := parser.New(bts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj, err := p.File().GetObject(img.Reference); err != nil || obj == nil {
+		t.Error("image removed with the unused font")
+	}
+	if bytes.Contains(bts, []byte("/FontDescriptor")) {
+		t.Error("objects of unused font written")
+	}
+}
+
+// encodeCollection combines font files to a font collection, the tables are not shared
+func encodeCollection(fonts ...[]byte) []byte {
+	res := make([]byte, 12+4*len(fonts))
+	copy(res, "ttcf")
+	binary.BigEndian.PutUint32(res[4:], 0x00010000)
+	binary.BigEndian.PutUint32(res[8:], uint32(len(fonts)))
+	for i, f := range fonts {
+		binary.BigEndian.PutUint32(res[12+4*i:], uint32(len(res)))
+		numTables := int(binary.BigEndian.Uint16(f[4:]))
+		dir := len(res)
+		res = append(res, f[:12+16*numTables]...)
+		for j := 0; j < numTables; j++ {
+			rec := res[dir+12+16*j:]
+			offset := binary.BigEndian.Uint32(rec[8:])
+			length := binary.BigEndian.Uint32(rec[12:])
+			binary.BigEndian.PutUint32(rec[8:], uint32(len(res)))
+			res = append(res, f[offset:offset+length]...)
+			for len(res)%4 != 0 {
+				res = append(res, 0)
+			}
+		}
+	}
+	return res
+}
+
+func TestNewFontFromCollection(t *testing.T) {
+	ttc := encodeCollection(goregular.TTF, gomono.TTF)
+	f := NewFile()
+	regular, err := f.NewCompositeFontFromTTF(goregular.TTF, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mono, err := f.NewCompositeFontFromTTF(gomono.TTF, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first font is used by default, another one by index
+	first, err := f.NewCompositeFontFromTTF(ttc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := f.NewCompositeFontFromCollection(ttc, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trueType, err := f.NewTrueTypeFontFromCollection(ttc, 1, types.EncodingWinAnsi, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const text = "iiiWWW"
+	if got, want := first.GetWidth(text, 10), regular.GetWidth(text, 10); got != want {
+		t.Errorf("default font: got width %v, want %v", got, want)
+	}
+	if got, want := second.GetWidth(text, 10), mono.GetWidth(text, 10); got != want {
+		t.Errorf("composite font 1: got width %v, want %v", got, want)
+	}
+	if got, want := trueType.GetWidth(text, 10), mono.GetWidth(text, 10); got != want {
+		t.Errorf("true type font 1: got width %v, want %v", got, want)
+	}
+
+	if _, err := f.NewCompositeFontFromCollection(ttc, 2, nil); err == nil {
+		t.Error("expected error for index out of range")
+	}
+	if _, err := f.NewCompositeFontFromCollection(goregular.TTF, 1, nil); err == nil {
+		t.Error("expected error for index of font which is not a collection")
+	}
+}
+
+func TestTrueTypeFontSubset(t *testing.T) {
+	build := func(text string, embed bool) (*types.Font, *types.FontDescriptor, []byte) {
+		f := NewFile()
+		font, err := f.NewTrueTypeFont(goregular.TTF, types.EncodingWinAnsi, embed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := f.NewPage(595, 842)
+		p.TextState_Tf(font, 10)
+		p.TextShowing_Tj(text)
+		if _, err := f.Write(); err != nil {
+			t.Fatal(err)
+		}
+
+		obj, err := f.creator.GetObject(font.Reference())
+		if err != nil {
+			t.Fatal(err)
+		}
+		fnt := obj.(*types.Font)
+		obj, err = f.creator.GetObject(fnt.FontDescriptor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fd := obj.(*types.FontDescriptor)
+		if !embed {
+			return fnt, fd, nil
+		}
+		obj, err = f.creator.GetObject(fd.FontFile2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream := obj.(types.StreamObject)
+		data, err := stream.Decode(f.creator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fnt, fd, data
+	}
+
+	fnt, fd, data := build("Hello €", true)
+	if !regexp.MustCompile(`^[A-Z]{6}\+Go$`).MatchString(string(fd.FontName)) {
+		t.Errorf("got font name %q, want subset tag", fd.FontName)
+	}
+	if fnt.BaseFont != fd.FontName {
+		t.Errorf("got base font %q, want %q", fnt.BaseFont, fd.FontName)
+	}
+	if len(data) >= len(goregular.TTF)/2 {
+		t.Errorf("got font file of %d bytes, want subset of %d bytes", len(data), len(goregular.TTF))
+	}
+
+	// the subset contains the outlines of the characters used only
+	subset, err := unitype.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range "Helo€x" {
+		outline, err := subset.GlyphOutline(subset.LookupRunes([]rune{r})[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (len(outline) != 0) != (r != 'x') {
+			t.Errorf("%q: got outline of %d segments", r, len(outline))
+		}
+	}
+
+	// the tag depends on the glyphs of the subset
+	_, fd2, _ := build("olleH€ ", true)
+	_, fd3, _ := build("Hello", true)
+	if fd2.FontName != fd.FontName || fd3.FontName == fd.FontName {
+		t.Errorf("got font names %q, %q for the same and %q for other glyphs", fd.FontName, fd2.FontName, fd3.FontName)
+	}
+
+	if _, fd, _ := build("Hello", false); fd.FontName != "Go" || fd.FontFile2.Number != 0 {
+		t.Errorf("not embedded: got font name %q, font file %v", fd.FontName, fd.FontFile2)
+	}
+}
@@ -0,0 +1,649 @@
+package pdf
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/raceresult/gopdf/pdffile"
+	"github.com/raceresult/gopdf/types"
+)
+
+// sourcePage is a page found in the page tree of another pdf, together with its object reference
+type sourcePage struct {
+	ref  types.Reference
+	page types.Page
+}
+
+// pageImport holds the state of one ImportPages call
+type pageImport struct {
+	file        *File
+	source      *pdffile.File
+	catalog     types.DocumentCatalog
+	copyRef     func(ref types.Reference) types.Reference
+	copied      map[types.Reference]types.Reference
+	pages       map[types.Reference]*Page
+	sourceAnnot map[types.Reference]struct{}
+}
+
+// ImportPages copies pages from another pdf (first page = 1) including their annotations, links, form fields and
+// outline entries. Destinations pointing to imported pages are remapped, links to pages which are not imported are
+// removed. If no page numbers are given, all pages are imported. The pages need to be added to the file using AddPage.
+// A page can be imported several times, e.g. a repeated separator page: each occurrence is a page object of its own
+// sharing contents and resources with the others. Destinations and outline entries point to the first occurrence,
+// form field widgets are only kept on the first occurrence.
+func (q *File) ImportPages(sourceFile *pdffile.File, pageNos ...int) ([]*Page, error) {
+	imp := pageImport{
+		file:        q,
+		source:      sourceFile,
+		pages:       make(map[types.Reference]*Page),
+		sourceAnnot: make(map[types.Reference]struct{}),
+	}
+
+	// read catalog and page tree of source file
+	catalogObj, err := sourceFile.GetObject(sourceFile.Root)
+	if err != nil {
+		return nil, err
+	}
+	catalogDict, ok := catalogObj.(types.Dictionary)
+	if !ok {
+		return nil, errors.New("catalog invalid")
+	}
+	if err := imp.catalog.Read(catalogDict); err != nil {
+		return nil, err
+	}
+	allPages, pageTreeNodes, err := collectSourcePages(sourceFile, imp.catalog.Pages, nil, make(map[types.Reference]struct{}))
+	if err != nil {
+		return nil, err
+	}
+
+	// determine pages to import
+	if len(pageNos) == 0 {
+		for i := range allPages {
+			pageNos = append(pageNos, i+1)
+		}
+	}
+	selected := make([]sourcePage, 0, len(pageNos))
+	for _, no := range pageNos {
+		if no < 1 || no > len(allPages) {
+			return nil, errors.New("page " + strconv.Itoa(no) + " not found")
+		}
+		selected = append(selected, allPages[no-1])
+	}
+
+	// prepare copy function: references to imported pages point to the new pages, references to all other pages
+	// and page tree nodes are replaced by null, otherwise the entire source document would be copied. The null
+	// references only apply to this import, the objects copied are shared with CopyPage and NewCapturedPage.
+	if q.copiedObjects == nil {
+		q.copiedObjects = make(map[*pdffile.File]map[types.Reference]types.Reference)
+	}
+	copiedMap, ok := q.copiedObjects[sourceFile]
+	if !ok {
+		copiedMap = make(map[types.Reference]types.Reference)
+	}
+	defer func() { q.copiedObjects[sourceFile] = copiedMap }()
+	excluded := make(map[types.Reference]types.Reference)
+	imp.copied = copiedMap
+	imp.copyRef = func(ref types.Reference) types.Reference {
+		if newRef, ok := excluded[ref]; ok {
+			return newRef
+		}
+		return q.copyObject(sourceFile, copiedMap, ref, imp.copyRef)
+	}
+	nullRef := q.getNullReference()
+	for _, ref := range pageTreeNodes {
+		excluded[ref] = nullRef
+	}
+	for _, sp := range allPages {
+		if _, ok := copiedMap[sp.ref]; !ok {
+			excluded[sp.ref] = nullRef
+		}
+	}
+	res := make([]*Page, len(selected))
+	for i, sp := range selected {
+		p := &Page{graphicsState: &graphicsState{}}
+		p.reference = q.creator.AddObject(&p.Data)
+		res[i] = p
+		if _, ok := imp.pages[sp.ref]; ok {
+			continue
+		}
+		imp.pages[sp.ref] = p
+		delete(excluded, sp.ref)
+		copiedMap[sp.ref] = p.reference
+	}
+
+	// copy pages and annotations
+	for i, sp := range selected {
+		p := res[i]
+		data := sp.page
+		annots := data.Annots
+		data.Annots = nil
+		p.Data = data.Copy(imp.copyRef).(types.Page)
+		p.Data.Resources, err = readResources(p.Data.Resources, q.creator)
+		if err != nil {
+			return nil, err
+		}
+		p.Data.Annots, err = imp.importAnnotations(annots, p, imp.pages[sp.ref] != p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// outlines and form fields
+	if err := imp.importOutlines(); err != nil {
+		return nil, err
+	}
+	if err := imp.importFormFields(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// AddPage adds a page to the file, for example a page returned by ImportPages
+func (q *File) AddPage(p *Page) {
+	q.Pages = append(q.Pages, p)
+}
+
+// getNullReference returns a reference to a null object, used to replace references to objects that were not copied
+func (q *File) getNullReference() types.Reference {
+	if q.nullRef.Number == 0 {
+		q.nullRef = q.creator.AddObject(types.Null{})
+	}
+	return q.nullRef
+}
+
+// collectSourcePages walks the page tree of a source file and returns all pages with inherited attributes resolved,
+// as well as the references of all intermediate page tree nodes
+func collectSourcePages(f *pdffile.File, ref types.Reference, inherited types.Dictionary, visited map[types.Reference]struct{}) ([]sourcePage, []types.Reference, error) {
+	if _, ok := visited[ref]; ok {
+		return nil, nil, errors.New("page tree contains a loop")
+	}
+	visited[ref] = struct{}{}
+
+	obj, err := f.GetObject(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	node, ok := obj.(types.Dictionary)
+	if !ok {
+		return nil, nil, errors.New("page tree item is not a dictionary")
+	}
+	typName, _ := node["Type"].(types.Name)
+
+	// copy inheritable attributes
+	dict := make(types.Dictionary, len(node)+len(inherited))
+	for k, v := range inherited {
+		dict[k] = v
+	}
+	for k, v := range node {
+		dict[k] = v
+	}
+
+	switch typName {
+	case "Pages":
+		inh := types.Dictionary{}
+		for _, k := range []types.Name{"Resources", "MediaBox", "CropBox", "Rotate"} {
+			if v, ok := dict[k]; ok {
+				inh[k] = v
+			}
+		}
+
+		kidsObj, _ := dict.GetValue("Kids", f)
+		kids, ok := kidsObj.(types.Array)
+		if !ok {
+			return nil, nil, errors.New("page tree node Kids invalid")
+		}
+		var pages []sourcePage
+		nodes := []types.Reference{ref}
+		for _, kid := range kids {
+			kidRef, ok := kid.(types.Reference)
+			if !ok {
+				return nil, nil, errors.New("page tree node kid is not a reference")
+			}
+			pp, nn, err := collectSourcePages(f, kidRef, inh, visited)
+			if err != nil {
+				return nil, nil, err
+			}
+			pages = append(pages, pp...)
+			nodes = append(nodes, nn...)
+		}
+		return pages, nodes, nil
+
+	case "Page":
+		var p types.Page
+		if err := p.Read(dict, f); err != nil {
+			return nil, nil, err
+		}
+		return []sourcePage{{ref: ref, page: p}}, nil, nil
+
+	default:
+		return nil, nil, errors.New("unknown page tree item type " + string(typName))
+	}
+}
+
+// importAnnotations copies the annotations of a page, remaps destinations and drops links to pages not imported. Of
+// pages imported repeatedly, the annotations are copied again for each occurrence except form field widgets.
+func (q *pageImport) importAnnotations(annots types.Object, page *Page, repeated bool) (types.Object, error) {
+	if annots == nil {
+		return nil, nil
+	}
+	obj, err := q.source.ResolveReference(annots)
+	if err != nil {
+		return nil, err
+	}
+	arr, ok := obj.(types.Array)
+	if !ok {
+		return nil, errors.New("page field Annots invalid")
+	}
+
+	// annotations to keep
+	type annotation struct {
+		dict  types.Dictionary
+		ref   types.Reference
+		isRef bool
+	}
+	var kept []annotation
+	for _, item := range arr {
+		annotObj, err := q.source.ResolveReference(item)
+		if err != nil {
+			continue
+		}
+		annot, ok := annotObj.(types.Dictionary)
+		if !ok {
+			continue
+		}
+		if repeated && annot["Subtype"] == types.Name("Widget") {
+			continue
+		}
+		annot, keep := q.remapDestinations(annot)
+		if !keep {
+			continue
+		}
+		ref, isRef := item.(types.Reference)
+		kept = append(kept, annotation{dict: annot, ref: ref, isRef: isRef})
+	}
+	if len(kept) == 0 {
+		return nil, nil
+	}
+
+	// repeated pages: references between the annotations, e.g. to popups, point to the copies of this occurrence
+	copyRef := q.copyRef
+	if repeated {
+		copies := make(map[types.Reference]types.Reference)
+		for _, a := range kept {
+			if a.isRef {
+				copies[a.ref] = q.file.creator.AddObject(types.Dictionary{})
+			}
+		}
+		copyRef = func(ref types.Reference) types.Reference {
+			if newRef, ok := copies[ref]; ok {
+				return newRef
+			}
+			return q.copyRef(ref)
+		}
+	}
+
+	res := make(types.Array, 0, len(kept))
+	for _, a := range kept {
+		if !a.isRef {
+			res = append(res, q.copyAnnotation(a.dict, page, copyRef))
+			continue
+		}
+		if repeated {
+			newRef := copyRef(a.ref)
+			if err := q.file.creator.SetObject(newRef, q.copyAnnotation(a.dict, page, copyRef)); err != nil {
+				return nil, err
+			}
+			res = append(res, newRef)
+			continue
+		}
+
+		// use the same reference mapping for indirect annotations so that form fields and popups point to the
+		// copied annotation; the number is reserved first for popups pointing back to their parent
+		q.sourceAnnot[a.ref] = struct{}{}
+		if newRef, ok := q.copied[a.ref]; ok {
+			res = append(res, newRef)
+			continue
+		}
+		newRef := q.file.creator.AddObject(types.Null{})
+		q.copied[a.ref] = newRef
+		if err := q.file.creator.SetObject(newRef, q.copyAnnotation(a.dict, page, copyRef)); err != nil {
+			return nil, err
+		}
+		res = append(res, newRef)
+	}
+	return res, nil
+}
+
+// copyAnnotation copies an annotation dictionary and points it to the given page
+func (q *pageImport) copyAnnotation(annot types.Dictionary, page *Page, copyRef func(ref types.Reference) types.Reference) types.Dictionary {
+	res := annot.Copy(copyRef).(types.Dictionary)
+	if _, ok := res["P"]; ok {
+		res["P"] = page.reference
+	}
+	return res
+}
+
+// remapDestinations replaces named destinations in Dest and GoTo actions with explicit destinations. If the
+// destination points to a page that is not imported, false is returned.
+func (q *pageImport) remapDestinations(dict types.Dictionary) (types.Dictionary, bool) {
+	res := make(types.Dictionary, len(dict))
+	for k, v := range dict {
+		res[k] = v
+	}
+
+	if v, ok := dict["Dest"]; ok {
+		dest, ok := q.resolveDestination(v)
+		if !ok {
+			return nil, false
+		}
+		res["Dest"] = dest
+	}
+
+	if v, ok := dict["A"]; ok {
+		actionObj, err := q.source.ResolveReference(v)
+		if err != nil {
+			return nil, false
+		}
+		action, ok := actionObj.(types.Dictionary)
+		if ok && action["S"] == types.Name("GoTo") {
+			dest, ok := q.resolveDestination(action["D"])
+			if !ok {
+				return nil, false
+			}
+			newAction := make(types.Dictionary, len(action))
+			for k, v := range action {
+				newAction[k] = v
+			}
+			newAction["D"] = dest
+			res["A"] = newAction
+		}
+	}
+	return res, true
+}
+
+// resolveDestination returns the explicit destination for the given destination object and whether it points to an
+// imported page
+func (q *pageImport) resolveDestination(dest types.Object) (types.Array, bool) {
+	obj, err := q.source.ResolveReference(dest)
+	if err != nil {
+		return nil, false
+	}
+
+	switch v := obj.(type) {
+	case types.Name:
+		// PDF 1.1: named destinations in the Dests dictionary of the catalog
+		if q.catalog.Dests.Number == 0 {
+			return nil, false
+		}
+		destsObj, err := q.source.GetObject(q.catalog.Dests)
+		if err != nil {
+			return nil, false
+		}
+		dests, ok := destsObj.(types.Dictionary)
+		if !ok {
+			return nil, false
+		}
+		return q.resolveDestination(dests[v])
+
+	case types.String:
+		// PDF 1.2: named destinations in the Dests name tree
+		namesObj, err := q.source.ResolveReference(q.catalog.Names)
+		if err != nil {
+			return nil, false
+		}
+		names, ok := namesObj.(types.Dictionary)
+		if !ok {
+			return nil, false
+		}
+		treeObj, ok := names.GetValue("Dests", q.source)
+		if !ok {
+			return nil, false
+		}
+		tree, ok := treeObj.(types.Dictionary)
+		if !ok {
+			return nil, false
+		}
+		return q.resolveDestination(lookupNameTree(q.source, tree, v, 0))
+
+	case types.Dictionary:
+		// dictionary with D entry
+		return q.resolveDestination(v["D"])
+
+	case types.Array:
+		if len(v) == 0 {
+			return nil, false
+		}
+		ref, ok := v[0].(types.Reference)
+		if !ok {
+			return nil, false
+		}
+		if _, ok := q.pages[ref]; !ok {
+			return nil, false
+		}
+		return v, true
+
+	default:
+		return nil, false
+	}
+}
+
+// lookupNameTree returns the value for the given key in a name tree
+func lookupNameTree(f *pdffile.File, node types.Dictionary, key types.String, depth int) types.Object {
+	if depth > 32 {
+		return nil
+	}
+	if namesObj, ok := node.GetValue("Names", f); ok {
+		if names, ok := namesObj.(types.Array); ok {
+			for i := 0; i+1 < len(names); i += 2 {
+				if k, ok := names[i].(types.String); ok && k == key {
+					return names[i+1]
+				}
+			}
+		}
+	}
+	if kidsObj, ok := node.GetValue("Kids", f); ok {
+		if kids, ok := kidsObj.(types.Array); ok {
+			for _, kid := range kids {
+				kidObj, err := f.ResolveReference(kid)
+				if err != nil {
+					continue
+				}
+				kidDict, ok := kidObj.(types.Dictionary)
+				if !ok {
+					continue
+				}
+				if v := lookupNameTree(f, kidDict, key, depth+1); v != nil {
+					return v
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// importOutlines copies all outline items pointing to imported pages (or having such children) to the outline
+// of the file
+func (q *pageImport) importOutlines() error {
+	if q.catalog.Outlines.Number == 0 {
+		return nil
+	}
+	rootObj, err := q.source.GetObject(q.catalog.Outlines)
+	if err != nil {
+		return err
+	}
+	root, ok := rootObj.(types.Dictionary)
+	if !ok {
+		return errors.New("outlines is not a dictionary")
+	}
+
+	items := q.importOutlineItems(root, make(map[types.Reference]struct{}))
+	if len(items) == 0 {
+		return nil
+	}
+
+	// append to outline root
+	f := q.file
+	if f.outlines == nil {
+		f.outlines = types.Dictionary{"Type": types.Name("Outlines")}
+		f.catalog.Outlines = f.creator.AddObject(f.outlines)
+	}
+	for _, item := range items {
+		f.appendOutlineItem(f.outlines, f.catalog.Outlines, item)
+	}
+	return nil
+}
+
+// outlineItem is an outline item copied from the source file, not yet linked into the outline tree
+type outlineItem struct {
+	dict     types.Dictionary
+	open     bool
+	children []outlineItem
+}
+
+// importOutlineItems returns the copies of all children of the given outline node
+func (q *pageImport) importOutlineItems(node types.Dictionary, visited map[types.Reference]struct{}) []outlineItem {
+	var res []outlineItem
+	next, _ := node["First"].(types.Reference)
+	for next.Number != 0 {
+		if _, ok := visited[next]; ok {
+			break
+		}
+		visited[next] = struct{}{}
+
+		obj, err := q.source.GetObject(next)
+		if err != nil {
+			break
+		}
+		src, ok := obj.(types.Dictionary)
+		if !ok {
+			break
+		}
+		next, _ = src["Next"].(types.Reference)
+
+		// children
+		children := q.importOutlineItems(src, visited)
+
+		// destination
+		item := outlineItem{dict: types.Dictionary{"Title": src["Title"]}, children: children}
+		dict, keep := q.remapDestinations(src)
+		if keep {
+			for _, k := range []types.Name{"Dest", "A", "C", "F"} {
+				if v, ok := dict[k]; ok {
+					item.dict[k] = types.Copy(v, q.copyRef)
+				}
+			}
+		} else if len(children) == 0 {
+			continue
+		}
+		count, _ := src["Count"].(types.Int)
+		item.open = count > 0
+		res = append(res, item)
+	}
+	return res
+}
+
+// appendOutlineItem adds the item and its children as last child to the given outline node
+func (q *File) appendOutlineItem(parent types.Dictionary, parentRef types.Reference, item outlineItem) int {
+	ref := q.creator.AddObject(item.dict)
+	item.dict["Parent"] = parentRef
+	if last, ok := parent["Last"].(types.Reference); ok {
+		lastObj, _ := q.creator.GetObject(last)
+		if lastDict, ok := lastObj.(types.Dictionary); ok {
+			lastDict["Next"] = ref
+		}
+		item.dict["Prev"] = last
+	} else {
+		parent["First"] = ref
+	}
+	parent["Last"] = ref
+
+	// children
+	var visible int
+	for _, child := range item.children {
+		visible += 1 + q.appendOutlineItem(item.dict, ref, child)
+	}
+	if len(item.children) != 0 {
+		if item.open {
+			item.dict["Count"] = types.Int(visible)
+		} else {
+			item.dict["Count"] = types.Int(-len(item.children))
+			visible = 0
+		}
+	}
+
+	// update count of parent
+	if _, isItem := parent["Title"]; !isItem {
+		c, _ := parent["Count"].(types.Int)
+		parent["Count"] = c + 1 + types.Int(visible)
+	}
+	return visible
+}
+
+// importFormFields copies all form fields which have a widget annotation on an imported page
+func (q *pageImport) importFormFields() error {
+	obj, err := q.source.ResolveReference(q.catalog.AcroForm)
+	if err != nil || obj == nil {
+		return nil
+	}
+	acroForm, ok := obj.(types.Dictionary)
+	if !ok {
+		return nil
+	}
+	fieldsObj, _ := acroForm.GetValue("Fields", q.source)
+	fields, _ := fieldsObj.(types.Array)
+
+	var newFields types.Array
+	for _, field := range fields {
+		ref, ok := field.(types.Reference)
+		if !ok || !q.hasImportedWidget(ref, 0) {
+			continue
+		}
+		newFields = append(newFields, q.copyRef(ref))
+	}
+	if len(newFields) == 0 {
+		return nil
+	}
+
+	// merge into AcroForm dictionary of the file
+	f := q.file
+	if f.acroForm == nil {
+		f.acroForm = types.Dictionary{"Fields": types.Array{}}
+		f.catalog.AcroForm = f.acroForm
+	}
+	f.acroForm["Fields"] = append(f.acroForm["Fields"].(types.Array), newFields...)
+	for _, k := range []types.Name{"NeedAppearances", "DR", "DA", "Q"} {
+		if _, ok := f.acroForm[k]; ok {
+			continue
+		}
+		if v, ok := acroForm[k]; ok {
+			f.acroForm[k] = types.Copy(v, q.copyRef)
+		}
+	}
+	return nil
+}
+
+// hasImportedWidget checks if the form field or one of its kids is an annotation on an imported page
+func (q *pageImport) hasImportedWidget(ref types.Reference, depth int) bool {
+	if _, ok := q.sourceAnnot[ref]; ok {
+		return true
+	}
+	if depth > 32 {
+		return false
+	}
+	obj, err := q.source.GetObject(ref)
+	if err != nil {
+		return false
+	}
+	dict, ok := obj.(types.Dictionary)
+	if !ok {
+		return false
+	}
+	kidsObj, _ := dict.GetValue("Kids", q.source)
+	kids, _ := kidsObj.(types.Array)
+	for _, kid := range kids {
+		if kidRef, ok := kid.(types.Reference); ok && q.hasImportedWidget(kidRef, depth+1) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/raceresult/gopdf/parser"
+	"github.com/raceresult/gopdf/pdffile"
+	"github.com/raceresult/gopdf/types"
+)
+
+// parsedTestFile writes a file with the given number of empty pages and parses it
+func parsedTestFile(t *testing.T, pages int) *parser.Parser {
+	t.Helper()
+	f := NewFile()
+	for i := 0; i < pages; i++ {
+		f.NewPage(595, 842)
+	}
+	bts, err := f.Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := parser.New(bts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestImportPagesDuplicate(t *testing.T) {
+	src := parsedTestFile(t, 2)
+	f := NewFile()
+	pages, err := f.ImportPages(src.File(), 1, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 3 || pages[0] == pages[2] || pages[0].reference == pages[2].reference {
+		t.Fatal("expected distinct pages for each occurrence")
+	}
+	if pages[0].Data.Resources != pages[2].Data.Resources {
+		t.Error("expected repeated page to share the copied resources")
+	}
+	if pages[0].Data.Contents != pages[2].Data.Contents {
+		t.Error("expected repeated page to share the copied contents")
+	}
+}
+
+func TestImportPagesCyclicReferences(t *testing.T) {
+	// annotation and popup pointing at each other and at the page, plus a cycle of two plain objects
+	src := parsedTestFile(t, 1).File()
+	catalog := resolveSourceDict(t, src, src.Root)
+	kids, _ := resolveSourceDict(t, src, catalog["Pages"])["Kids"].(types.Array)
+	if len(kids) != 1 {
+		t.Fatal("page not found")
+	}
+	page := kids[0].(types.Reference)
+	a := src.AddObject(types.Null{})
+	b := src.AddObject(types.Null{})
+	x := src.AddObject(types.Null{})
+	y := src.AddObject(types.Null{})
+	_ = src.SetObject(a, types.Dictionary{"Type": types.Name("Annot"), "Subtype": types.Name("Text"),
+		"Popup": b, "P": page, "X": x})
+	_ = src.SetObject(b, types.Dictionary{"Type": types.Name("Annot"), "Subtype": types.Name("Popup"),
+		"Parent": a, "P": page})
+	_ = src.SetObject(x, types.Dictionary{"Next": y})
+	_ = src.SetObject(y, types.Dictionary{"Next": x})
+	resolveSourceDict(t, src, page)["Annots"] = types.Array{a, b}
+
+	f := NewFile()
+	pages, err := f.ImportPages(src, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range pages {
+		annots, ok := p.Data.Annots.(types.Array)
+		if !ok || len(annots) != 2 {
+			t.Fatalf("page %d: got annotations %v", i, p.Data.Annots)
+		}
+		annot := resolveDict(t, f, annots[0])
+		popup := resolveDict(t, f, annots[1])
+		if annot["Popup"] != annots[1] || popup["Parent"] != annots[0] {
+			t.Errorf("page %d: annotation and popup not linked to each other", i)
+		}
+		if annot["P"] != p.reference || popup["P"] != p.reference {
+			t.Errorf("page %d: annotations not linked to their page", i)
+		}
+		xr, _ := annot["X"].(types.Reference)
+		yr, _ := resolveDict(t, f, xr)["Next"].(types.Reference)
+		if next, _ := resolveDict(t, f, yr)["Next"].(types.Reference); xr.Number == 0 || yr.Number == 0 || next != xr {
+			t.Errorf("page %d: cyclic references not copied", i)
+		}
+	}
+}
+
+// resolveSourceDict returns the dictionary referenced by obj in the source file
+func resolveSourceDict(t *testing.T, f *pdffile.File, obj types.Object) types.Dictionary {
+	t.Helper()
+	res, err := f.ResolveReference(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, ok := res.(types.Dictionary)
+	if !ok {
+		t.Fatalf("expected dictionary, got %v", res)
+	}
+	return d
+}
+
+// resolveDict returns the dictionary referenced by obj in the file
+func resolveDict(t *testing.T, f *File, obj types.Object) types.Dictionary {
+	t.Helper()
+	ref, ok := obj.(types.Reference)
+	if !ok || ref.Number == 0 {
+		t.Fatalf("expected reference, got %v", obj)
+	}
+	res, err := f.creator.GetObject(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, ok := res.(types.Dictionary)
+	if !ok {
+		t.Fatalf("object %d is not a dictionary", ref.Number)
+	}
+	return d
+}
+
+func TestImportPagesSharedCopies(t *testing.T) {
+	src := parsedTestFile(t, 2)
+	f := NewFile()
+	if _, err := f.ImportPages(src.File(), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// page tree nodes and pages not imported are excluded by the import only, later copies by CopyPage or
+	// NewCapturedPage must not see the null references
+	for ref, newRef := range f.copiedObjects[src.File()] {
+		if newRef == f.getNullReference() {
+			t.Errorf("object %d replaced by null for later copies", ref.Number)
+		}
+	}
+}
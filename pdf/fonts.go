@@ -1,8 +1,13 @@
 package pdf
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
 	"sync"
+	"sync/atomic"
 
+	"github.com/raceresult/gopdf/pdf/cff"
 	"github.com/raceresult/gopdf/pdf/unitype"
 	"github.com/raceresult/gopdf/types"
 	"github.com/raceresult/gopdf/types/standardfont/afm"
@@ -14,6 +19,7 @@ import (
 
 type FontHandler interface {
 	finish() error
+	used() bool
 	Reference() types.Reference
 	Encode(s string) string
 	GetWidth(text string, fontSize float64) float64
@@ -27,25 +33,102 @@ type FontHandler interface {
 	FallbackFont() FontHandler
 }
 
+// fontReference is the reference of the font dictionary. Getting the reference marks the font as used, fonts never
+// used, e.g. if all text is drawn as glyph outlines, are not written to the file.
+type fontReference struct {
+	reference  types.Reference
+	referenced int32
+}
+
+func (q *fontReference) Reference() types.Reference {
+	atomic.StoreInt32(&q.referenced, 1)
+	return q.reference
+}
+func (q *fontReference) used() bool {
+	return atomic.LoadInt32(&q.referenced) != 0
+}
+
+// kernedArray builds the array for the TJ operator from the runes and the kerning adjustment after each rune
+func kernedArray(runes []rune, kerns []int, encode func(text string) string) types.Array {
+	var res types.Array
+	start := 0
+	for i, k := range kerns {
+		if k == 0 {
+			continue
+		}
+		res = append(res, types.String(encode(string(runes[start:i+1]))), types.Int(-k))
+		start = i + 1
+	}
+	if start < len(runes) {
+		res = append(res, types.String(encode(string(runes[start:]))))
+	}
+	return res
+}
+
 // ---------------------------------------------------------------------------------------------------------------------
 
 // StandardFont references a standard font and provides additional function like font metrics
 type StandardFont struct {
-	reference types.Reference
-	encoding  types.Encoding
-	metrics   *afm.Font
+	fontReference
+	encoding types.SimpleEncoding
+	metrics  *afm.Font
+	kerning  map[[2]string]int
+}
+
+// SetKerning enables or disables pair kerning using the kerning pairs of the font metrics. Kerning is applied to
+// width calculation and output.
+func (q *StandardFont) SetKerning(enabled bool) {
+	if enabled {
+		q.kerning = q.metrics.GetKerningPairsByName()
+	} else {
+		q.kerning = nil
+	}
+}
+
+// glyphNames returns the names of the glyphs the characters of the text are encoded to
+func (q *StandardFont) glyphNames(text string) []string {
+	codes := q.encoding.Encode(text)
+	res := make([]string, len(codes))
+	for i := 0; i < len(codes); i++ {
+		if res[i] = q.encoding.GlyphName(codes[i]); res[i] == "" {
+			res[i] = q.metrics.GlyphName(int(codes[i]))
+		}
+	}
+	return res
+}
+
+// kerns returns the kerning adjustment after each rune
+func (q *StandardFont) kerns(runes []rune) []int {
+	res := make([]int, len(runes))
+	names := q.glyphNames(string(runes))
+	if len(names) != len(runes) {
+		return res
+	}
+	for i := 0; i+1 < len(runes); i++ {
+		res[i] = q.kerning[[2]string{names[i], names[i+1]}]
+	}
+	return res
+}
+func (q *StandardFont) shaping() bool {
+	return q.kerning != nil
+}
+func (q *StandardFont) encodeShaped(text string) []shapedRun {
+	runes := []rune(text)
+	return []shapedRun{{array: kernedArray(runes, q.kerns(runes), q.Encode)}}
 }
 
 func (q *StandardFont) Encode(text string) string {
 	return q.encoding.Encode(text)
 }
-func (q *StandardFont) Reference() types.Reference {
-	return q.reference
-}
 func (q *StandardFont) GetWidth(text string, fontSize float64) float64 {
 	var w int
-	for _, c := range text {
-		w += q.metrics.GetGlyphAdvance(int(c))
+	for _, name := range q.glyphNames(text) {
+		w += q.metrics.GetGlyphAdvanceByName(name)
+	}
+	if q.kerning != nil {
+		for _, k := range q.kerns([]rune(text)) {
+			w += k
+		}
 	}
 	return float64(w) * fontSize / 1000
 }
@@ -83,25 +166,72 @@ func (q *StandardFont) finish() error {
 
 // ---------------------------------------------------------------------------------------------------------------------
 
+// Type1Font references an embedded PostScript Type 1 font. Metrics and kerning are taken from the AFM file and
+// handled like those of standard fonts.
+type Type1Font struct {
+	StandardFont
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
 // TrueTypeFont references a TrueType font and provides additional function like font metrics
 type TrueTypeFont struct {
-	reference types.Reference
-	encoding  types.Encoding
-	font      *unitype.Font
-	metrics   unitype.Metrics
+	fontReference
+	encoding     types.SimpleEncoding
+	font         *unitype.Font
+	metrics      unitype.Metrics
+	kerning      bool
+	usedRunes    map[rune]struct{}
+	usedRunesMux sync.Mutex
+	onFinish     func() error
+}
+
+// SetKerning enables or disables pair kerning using the GPOS or 'kern' table of the font. Kerning is applied to
+// width calculation and output.
+func (q *TrueTypeFont) SetKerning(enabled bool) {
+	q.kerning = enabled
+}
+
+// kerns returns the kerning adjustment after each rune
+func (q *TrueTypeFont) kerns(runes []rune) []int {
+	res := make([]int, len(runes))
+	glyphs := q.font.Shape(runes, FeatureKerning)
+	if len(glyphs) != len(runes) {
+		return res
+	}
+	for i, g := range glyphs {
+		res[i] = g.Advance - q.font.GetGlyphAdvance(g.Index)
+	}
+	return res
+}
+func (q *TrueTypeFont) shaping() bool {
+	return q.kerning
+}
+func (q *TrueTypeFont) encodeShaped(text string) []shapedRun {
+	runes := []rune(text)
+	return []shapedRun{{array: kernedArray(runes, q.kerns(runes), q.Encode)}}
 }
 
 func (q *TrueTypeFont) Encode(text string) string {
+	if q.usedRunes != nil {
+		q.usedRunesMux.Lock()
+		for _, r := range text {
+			q.usedRunes[r] = struct{}{}
+		}
+		q.usedRunesMux.Unlock()
+	}
 	return q.encoding.Encode(text)
 }
-func (q *TrueTypeFont) Reference() types.Reference {
-	return q.reference
-}
 func (q *TrueTypeFont) GetWidth(text string, fontSize float64) float64 {
 	var w int
 	for _, ind := range q.font.LookupRunes([]rune(text)) {
 		w += q.font.GetGlyphAdvance(ind)
 	}
+	if q.kerning {
+		for _, k := range q.kerns([]rune(text)) {
+			w += k
+		}
+	}
 	return float64(w) * fontSize / 1000
 }
 func (q *TrueTypeFont) GetAscent(fontSize float64) float64 {
@@ -132,27 +262,192 @@ func (q *TrueTypeFont) HasGylph(runes []rune) []bool {
 func (q *TrueTypeFont) FallbackFont() FontHandler {
 	return nil
 }
+func (q *TrueTypeFont) glyphOutlines(text string) ([]glyphOutline, error) {
+	runes := []rune(text)
+	glyphs := unshapedGlyphs(q.font, runes)
+	if q.kerning {
+		if shaped := q.font.Shape(runes, FeatureKerning); len(shaped) == len(runes) {
+			glyphs = shaped
+		}
+	}
+	return unitypeGlyphOutlines(q.font, glyphs)
+}
 func (q *TrueTypeFont) finish() error {
-	return nil
+	if q.onFinish == nil {
+		return nil
+	}
+	return q.onFinish()
 }
 
 // --------------------------------------------------------------------------------------------------------------------
 
 // CompositeFont references a composite font and provides additional function like font metrics
 type CompositeFont struct {
-	reference    types.Reference
+	fontReference
 	usedRunes    map[rune]struct{}
 	usedRunesMux sync.Mutex
 	onFinish     func() error
 	font         *unitype.Font
 	metrics      unitype.Metrics
-	fallbackFont FontHandler
+	fallbacks    []FontHandler
+	features     []string
+	usedGlyphs   map[unitype.GlyphIndex][]rune
+	vertical     bool
+
+	// shaped texts, texts are usually shaped twice: for the width and for the output
+	shapeCache    map[string][]unitype.ShapedGlyph
+	shapeCacheMux sync.Mutex
 }
 
-func (q *CompositeFont) Reference() types.Reference {
-	return q.reference
+// maxShapeCache is the number of shaped texts cached by a CompositeFont, the cache is cleared when exceeded
+const maxShapeCache = 1024
+
+// OpenType features for CompositeFont.SetFeatures
+const (
+	FeatureLigatures      = "liga"
+	FeatureKerning        = "kern"
+	FeatureTabularNumbers = "tnum"
+	FeatureSmallCaps      = "smcp"
+)
+
+// SetFeatures enables OpenType shaping with the given features, e.g. FeatureLigatures and FeatureKerning. Text is
+// then encoded by glyph IDs and shown with positioning adjustments. Must be called before the font is used.
+func (q *CompositeFont) SetFeatures(features ...string) {
+	q.features = features
+}
+
+// Features returns the OpenType features available in the font
+func (q *CompositeFont) Features() []string {
+	return q.font.Features()
 }
+
+// SetVertical enables vertical writing: text is encoded with Identity-V and shown top to bottom, using the vertical
+// metrics of the font and the vertical glyph variants (OpenType features "vert" and "vrt2"). Must be called before
+// the font is used.
+func (q *CompositeFont) SetVertical(vertical bool) {
+	q.vertical = vertical
+}
+
+// Vertical returns true if the font is used for vertical writing
+func (q *CompositeFont) Vertical() bool {
+	return q.vertical
+}
+
+// GetVerticalAdvance returns the height of the text in vertical writing mode
+func (q *CompositeFont) GetVerticalAdvance(text string, fontSize float64) float64 {
+	var h int
+	for _, g := range q.shaped(text) {
+		adv, _, _ := q.font.GetGlyphVerticalMetrics(g.Index)
+		h += adv
+	}
+	return float64(h) * fontSize / 1000
+}
+
+// shaping returns true if OpenType features are enabled
+func (q *CompositeFont) shaping() bool {
+	return len(q.features) != 0 || q.vertical
+}
+
+// shapingFeatures returns the OpenType features used for shaping
+func (q *CompositeFont) shapingFeatures() []string {
+	if !q.vertical {
+		return q.features
+	}
+	return append([]string{"vert", "vrt2"}, q.features...)
+}
+
+// shaped returns the shaped text from the cache or shapes it. The glyphs must not be modified.
+func (q *CompositeFont) shaped(text string) []unitype.ShapedGlyph {
+	q.shapeCacheMux.Lock()
+	defer q.shapeCacheMux.Unlock()
+	if glyphs, ok := q.shapeCache[text]; ok {
+		return glyphs
+	}
+	if q.shapeCache == nil || len(q.shapeCache) >= maxShapeCache {
+		q.shapeCache = make(map[string][]unitype.ShapedGlyph)
+	}
+	glyphs := q.font.Shape([]rune(text), q.shapingFeatures()...)
+	q.shapeCache[text] = glyphs
+	return glyphs
+}
+
+// shape shapes the text and marks the glyphs as used
+func (q *CompositeFont) shape(text string) []unitype.ShapedGlyph {
+	glyphs := q.shaped(text)
+	q.usedRunesMux.Lock()
+	for _, g := range glyphs {
+		if r, ok := q.usedGlyphs[g.Index]; !ok || len(r) == 0 {
+			q.usedGlyphs[g.Index] = g.Runes
+		}
+	}
+	q.usedRunesMux.Unlock()
+	return glyphs
+}
+
+// encodeShaped returns the shaped text as arrays for the TJ operator: glyph IDs with positioning adjustments. A new
+// run starts where the vertical offset of the glyphs changes.
+func (q *CompositeFont) encodeShaped(text string) []shapedRun {
+	var runs []shapedRun
+	var res types.Array
+	var curr []byte
+	flush := func() {
+		if len(curr) != 0 {
+			res = append(res, types.String(curr))
+			curr = nil
+		}
+	}
+	adjust := func(v int) {
+		if v == 0 {
+			return
+		}
+		flush()
+		if n := len(res); n != 0 {
+			if prev, ok := res[n-1].(types.Int); ok {
+				res[n-1] = prev + types.Int(v)
+				return
+			}
+		}
+		res = append(res, types.Int(v))
+	}
+
+	// horizontal positioning adjustments do not apply to vertical writing
+	if q.vertical {
+		for _, g := range q.shape(text) {
+			curr = append(curr, byte(g.Index>>8), byte(g.Index))
+		}
+		return []shapedRun{{array: types.Array{types.String(curr)}}}
+	}
+
+	// TJ numbers are subtracted from the text position
+	var yOffset int
+	for i, g := range q.shape(text) {
+		if i != 0 && g.YOffset != yOffset {
+			flush()
+			runs = append(runs, shapedRun{yOffset: yOffset, array: res})
+			res = nil
+		}
+		yOffset = g.YOffset
+		adjust(-g.XOffset)
+		curr = append(curr, byte(g.Index>>8), byte(g.Index))
+		adjust(-(g.Advance - q.font.GetGlyphAdvance(g.Index) - g.XOffset))
+	}
+	flush()
+	if len(res) != 0 {
+		runs = append(runs, shapedRun{yOffset: yOffset, array: res})
+	}
+	return runs
+}
+
 func (q *CompositeFont) Encode(text string) string {
+	if q.shaping() {
+		glyphs := q.shape(text)
+		bts := make([]byte, 0, 2*len(glyphs))
+		for _, g := range glyphs {
+			bts = append(bts, byte(g.Index>>8), byte(g.Index))
+		}
+		return string(bts)
+	}
+
 	var repl bool
 	q.usedRunesMux.Lock()
 	runes := []rune(text)
@@ -178,6 +473,12 @@ func (q *CompositeFont) Encode(text string) string {
 }
 func (q *CompositeFont) GetWidth(text string, fontSize float64) float64 {
 	var w int
+	if q.shaping() {
+		for _, g := range q.shaped(text) {
+			w += g.Advance
+		}
+		return float64(w) * fontSize / 1000
+	}
 	for _, ind := range q.font.LookupRunes([]rune(text)) {
 		w += q.font.GetGlyphAdvance(ind)
 	}
@@ -209,7 +510,27 @@ func (q *CompositeFont) HasGylph(runes []rune) []bool {
 	return dest
 }
 func (q *CompositeFont) FallbackFont() FontHandler {
-	return q.fallbackFont
+	if len(q.fallbacks) == 0 {
+		return nil
+	}
+	return q.fallbacks[0]
+}
+func (q *CompositeFont) glyphOutlines(text string) ([]glyphOutline, error) {
+	if len(q.features) != 0 {
+		return unitypeGlyphOutlines(q.font, q.font.Shape([]rune(text), q.features...))
+	}
+	return unitypeGlyphOutlines(q.font, unshapedGlyphs(q.font, []rune(text)))
+}
+
+// SetFallbackFonts sets the ordered chain of fonts used for characters the font has no glyph for, e.g. fonts for
+// Cyrillic, Arabic, Thai and CJK. For every character the first font of the chain providing a glyph is used.
+func (q *CompositeFont) SetFallbackFonts(fonts ...FontHandler) {
+	q.fallbacks = fonts
+}
+
+// FallbackFonts returns the ordered chain of fallback fonts
+func (q *CompositeFont) FallbackFonts() []FontHandler {
+	return q.fallbacks
 }
 func (q *CompositeFont) finish() error {
 	if q.onFinish == nil {
@@ -222,19 +543,33 @@ func (q *CompositeFont) finish() error {
 
 // CompositeFontOTF references a composite font and provides additional function like font metrics
 type CompositeFontOTF struct {
-	reference    types.Reference
+	fontReference
 	usedRunes    map[rune]struct{}
 	usedRunesMux sync.Mutex
 	onFinish     func() error
 	font         *sfnt.Font
 	metrics      font.Metrics
 	bounds       fixed.Rectangle26_6
+	cff          *cff.Font // CFF outlines, text is then encoded by glyph IDs
+	usedGlyphs   map[unitype.GlyphIndex][]rune
+	fallbacks    []FontHandler
 }
 
-func (q *CompositeFontOTF) Reference() types.Reference {
-	return q.reference
-}
 func (q *CompositeFontOTF) Encode(text string) string {
+	if q.cff != nil {
+		bts := make([]byte, 0, 2*len(text))
+		q.usedRunesMux.Lock()
+		for _, r := range text {
+			gid, _ := q.font.GlyphIndex(nil, r)
+			if rr, ok := q.usedGlyphs[unitype.GlyphIndex(gid)]; !ok || len(rr) == 0 {
+				q.usedGlyphs[unitype.GlyphIndex(gid)] = []rune{r}
+			}
+			bts = append(bts, byte(gid>>8), byte(gid))
+		}
+		q.usedRunesMux.Unlock()
+		return string(bts)
+	}
+
 	q.usedRunesMux.Lock()
 	for _, r := range text {
 		q.usedRunes[r] = struct{}{}
@@ -245,6 +580,16 @@ func (q *CompositeFontOTF) Encode(text string) string {
 	return sn
 }
 func (q *CompositeFontOTF) GetWidth(text string, fontSize float64) float64 {
+	if q.cff != nil {
+		var w float64
+		for _, r := range text {
+			ind, _ := q.font.GlyphIndex(nil, r)
+			gw, _ := q.cff.GlyphWidth(uint16(ind))
+			w += gw
+		}
+		return w * fontSize / 1000
+	}
+
 	var w int
 	for _, r := range text {
 		ind, err := q.font.GlyphIndex(nil, r)
@@ -288,7 +633,69 @@ func (q *CompositeFontOTF) HasGylph(runes []rune) []bool {
 	return dest
 }
 func (q *CompositeFontOTF) FallbackFont() FontHandler {
-	return nil
+	if len(q.fallbacks) == 0 {
+		return nil
+	}
+	return q.fallbacks[0]
+}
+func (q *CompositeFontOTF) glyphOutlines(text string) ([]glyphOutline, error) {
+	var buf sfnt.Buffer
+	var res []glyphOutline
+	for _, r := range text {
+		ind, err := q.font.GlyphIndex(&buf, r)
+		if err != nil {
+			return nil, err
+		}
+		segments, err := q.font.LoadGlyph(&buf, ind, fixed.I(1000), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		// sfnt uses a coordinate system with y pointing down, contours are closed implicitly
+		g := glyphOutline{
+			scale:   1,
+			advance: q.GetWidth(string(r), 1000),
+			space:   r == ' ',
+		}
+		point := func(p fixed.Point26_6) unitype.OutlinePoint {
+			return unitype.OutlinePoint{X: float64(p.X) / 64, Y: -float64(p.Y) / 64}
+		}
+		for i, seg := range segments {
+			switch seg.Op {
+			case sfnt.SegmentOpMoveTo:
+				if i != 0 {
+					g.segments = append(g.segments, unitype.PathSegment{Op: unitype.PathClose})
+				}
+				g.segments = append(g.segments, unitype.PathSegment{Op: unitype.PathMoveTo,
+					Points: []unitype.OutlinePoint{point(seg.Args[0])}})
+			case sfnt.SegmentOpLineTo:
+				g.segments = append(g.segments, unitype.PathSegment{Op: unitype.PathLineTo,
+					Points: []unitype.OutlinePoint{point(seg.Args[0])}})
+			case sfnt.SegmentOpQuadTo:
+				g.segments = append(g.segments, unitype.PathSegment{Op: unitype.PathQuadTo,
+					Points: []unitype.OutlinePoint{point(seg.Args[0]), point(seg.Args[1])}})
+			case sfnt.SegmentOpCubeTo:
+				g.segments = append(g.segments, unitype.PathSegment{Op: unitype.PathCubeTo,
+					Points: []unitype.OutlinePoint{point(seg.Args[0]), point(seg.Args[1]), point(seg.Args[2])}})
+			}
+		}
+		if len(g.segments) != 0 {
+			g.segments = append(g.segments, unitype.PathSegment{Op: unitype.PathClose})
+		}
+		res = append(res, g)
+	}
+	return res, nil
+}
+
+// SetFallbackFonts sets the ordered chain of fonts used for characters the font has no glyph for. For every
+// character the first font of the chain providing a glyph is used.
+func (q *CompositeFontOTF) SetFallbackFonts(fonts ...FontHandler) {
+	q.fallbacks = fonts
+}
+
+// FallbackFonts returns the ordered chain of fallback fonts
+func (q *CompositeFontOTF) FallbackFonts() []FontHandler {
+	return q.fallbacks
 }
 func (q *CompositeFontOTF) finish() error {
 	if q.onFinish == nil {
@@ -296,3 +703,257 @@ func (q *CompositeFontOTF) finish() error {
 	}
 	return q.onFinish()
 }
+
+// -------------------------------------------------------------------------------------------------------------------
+
+// ColorFont references a Type 3 font drawing the color glyphs of a TrueType font, e.g. an emoji font. Glyphs are
+// painted from the layers of the COLR table, from the PNG bitmaps of the sbix or CBDT table or, for other glyphs, from
+// the outline. Character codes are assigned to the glyphs in order of use; as Type 3 fonts are simple fonts, at most
+// 254 different glyphs can be shown, further glyphs are shown as .notdef.
+type ColorFont struct {
+	fontReference
+	onFinish   func() error
+	font       *unitype.Font
+	metrics    unitype.Metrics
+	codes      map[unitype.GlyphIndex]byte
+	glyphs     []unitype.GlyphIndex // glyph by character code
+	usedGlyphs map[unitype.GlyphIndex][]rune
+	mux        sync.Mutex
+}
+
+// shape shapes the text with the substitutions for emoji sequences, e.g. ZWJ sequences and flags
+func (q *ColorFont) shape(text string) []unitype.ShapedGlyph {
+	return q.font.Shape([]rune(text), "ccmp", FeatureLigatures)
+}
+
+// code returns the character code of the glyph, assigns the next free one if not done yet. Code 32 is skipped since
+// word spacing is applied to it.
+func (q *ColorFont) code(g unitype.ShapedGlyph) byte {
+	if r, ok := q.usedGlyphs[g.Index]; !ok || len(r) == 0 {
+		q.usedGlyphs[g.Index] = g.Runes
+	}
+	if c, ok := q.codes[g.Index]; ok {
+		return c
+	}
+	if len(q.glyphs) == 32 {
+		q.glyphs = append(q.glyphs, 0)
+	}
+	if len(q.glyphs) > 255 {
+		return 0
+	}
+	c := byte(len(q.glyphs))
+	q.codes[g.Index] = c
+	q.glyphs = append(q.glyphs, g.Index)
+	return c
+}
+
+func (q *ColorFont) Encode(text string) string {
+	glyphs := q.shape(text)
+	bts := make([]byte, 0, len(glyphs))
+	q.mux.Lock()
+	for _, g := range glyphs {
+		bts = append(bts, q.code(g))
+	}
+	q.mux.Unlock()
+	return string(bts)
+}
+func (q *ColorFont) GetWidth(text string, fontSize float64) float64 {
+	var w int
+	for _, g := range q.shape(text) {
+		w += q.font.GetGlyphAdvance(g.Index)
+	}
+	return float64(w) * fontSize / 1000
+}
+func (q *ColorFont) GetAscent(fontSize float64) float64 {
+	return float64(q.metrics.Ascent) * fontSize / 1000
+}
+func (q *ColorFont) GetTop(fontSize float64) float64 {
+	return float64(q.metrics.YMax) * fontSize / 1000
+}
+func (q *ColorFont) GetBottom(fontSize float64) float64 {
+	return float64(q.metrics.YMin) * fontSize / 1000
+}
+func (q *ColorFont) GetHeight(fontSize float64) float64 {
+	return float64(q.metrics.TextHeight) * fontSize / 1000
+}
+func (q *ColorFont) GetUnderlineThickness(size float64) float64 {
+	return float64(q.metrics.UnderlineThickness) * size / 1000
+}
+func (q *ColorFont) GetUnderlinePosition(size float64) float64 {
+	return float64(q.metrics.UnderlinePosition) * size / 1000
+}
+func (q *ColorFont) HasGylph(runes []rune) []bool {
+	dest := make([]bool, 0, len(runes))
+	for _, ind := range q.font.LookupRunes(runes) {
+		dest = append(dest, ind > 0)
+	}
+	return dest
+}
+func (q *ColorFont) FallbackFont() FontHandler {
+	return nil
+}
+func (q *ColorFont) finish() error {
+	if q.onFinish == nil {
+		return nil
+	}
+	return q.onFinish()
+}
+
+// drawOutline adds the path of a glyph outline to the page, transformed by the matrix [a b c d e f] like by the cm
+// operator. Quadratic curves are converted to cubic ones.
+func drawOutline(p *Page, segments []unitype.PathSegment, m [6]float64) {
+	tr := func(pt unitype.OutlinePoint) (float64, float64) {
+		return m[0]*pt.X + m[2]*pt.Y + m[4], m[1]*pt.X + m[3]*pt.Y + m[5]
+	}
+	var curr unitype.OutlinePoint
+	for _, s := range segments {
+		switch s.Op {
+		case unitype.PathMoveTo:
+			curr = s.Points[0]
+			p.Path_m(tr(curr))
+		case unitype.PathLineTo:
+			curr = s.Points[0]
+			p.Path_l(tr(curr))
+		case unitype.PathQuadTo:
+			ctrl, end := s.Points[0], s.Points[1]
+			x1, y1 := tr(unitype.OutlinePoint{X: curr.X + 2*(ctrl.X-curr.X)/3, Y: curr.Y + 2*(ctrl.Y-curr.Y)/3})
+			x2, y2 := tr(unitype.OutlinePoint{X: end.X + 2*(ctrl.X-end.X)/3, Y: end.Y + 2*(ctrl.Y-end.Y)/3})
+			x3, y3 := tr(end)
+			p.Path_c(x1, y1, x2, y2, x3, y3)
+			curr = end
+		case unitype.PathCubeTo:
+			x1, y1 := tr(s.Points[0])
+			x2, y2 := tr(s.Points[1])
+			x3, y3 := tr(s.Points[2])
+			p.Path_c(x1, y1, x2, y2, x3, y3)
+			curr = s.Points[2]
+		case unitype.PathClose:
+			p.Path_h()
+		}
+	}
+}
+
+// -------------------------------------------------------------------------------------------------------------------
+
+// Type3Font references a Type 3 font with glyphs drawn by path and image operators, e.g. for icons or pictograms.
+// Glyphs are described in a glyph coordinate system of 1000 units per em with the baseline at y=0. As Type 3 fonts are
+// simple fonts, at most 255 glyphs can be added.
+type Type3Font struct {
+	fontReference
+	onFinish func() error
+	ascent   float64
+	descent  float64
+	page     *Page // content builder of the glyph descriptions, shares the resources
+	codes    map[rune]byte
+	glyphs   []type3Glyph // glyph by character code starting at 1
+}
+
+// AddGlyph adds a glyph for the given character. The glyph is drawn by the function draw using the path, color, image
+// etc. operators of the given page, in the glyph coordinate system of 1000 units per em. Parts without explicit
+// color are painted in the color of the text. width is the advance of the glyph.
+func (q *Type3Font) AddGlyph(r rune, width float64, draw func(p *Page)) error {
+	if _, ok := q.codes[r]; ok {
+		return errors.New("glyph for character " + strconv.QuoteRune(r) + " already added")
+	}
+
+	// space keeps its character code since word spacing is applied to it, other characters get the lowest free code
+	code := 32
+	if r != ' ' {
+		for code = 1; code <= 255; code++ {
+			if code != 32 && (code > len(q.glyphs) || q.glyphs[code-1].runes == nil) {
+				break
+			}
+		}
+	}
+	if code > 255 {
+		return errors.New("type 3 font cannot hold more than 255 glyphs")
+	}
+
+	q.page.contents = nil
+	q.page.graphicsState = &graphicsState{}
+	q.page.Type3Font_d0(width, 0)
+	draw(q.page)
+
+	for len(q.glyphs) < code {
+		q.glyphs = append(q.glyphs, type3Glyph{name: ".notdef", contents: [][]byte{[]byte("0 0 d0")}})
+	}
+	q.glyphs[code-1] = type3Glyph{
+		name:     types.Name(fmt.Sprintf("u%04X", r)),
+		width:    width,
+		contents: q.page.contents,
+		runes:    []rune{r},
+	}
+	q.codes[r] = byte(code)
+	return nil
+}
+
+// AddGlyphSVG adds a glyph for the given character from SVG path data (the d attribute of a path element), e.g. of an
+// icon. The path is given in the coordinate system of the SVG viewBox of the given size (y axis pointing down); its
+// height is scaled to one em with the top at the ascent of the font. The path is filled using the nonzero winding
+// number rule in the color of the text, the advance of the glyph is the scaled width of the viewBox.
+func (q *Type3Font) AddGlyphSVG(r rune, data string, viewBoxWidth, viewBoxHeight float64) error {
+	if viewBoxWidth <= 0 || viewBoxHeight <= 0 {
+		return errors.New("invalid size of SVG viewBox")
+	}
+	scale := 1000 / viewBoxHeight
+	m := [6]float64{scale, 0, 0, -scale, 0, q.ascent}
+	if err := drawSVGPath(NewPage(0, 0), data, m); err != nil {
+		return err
+	}
+	return q.AddGlyph(r, viewBoxWidth*scale, func(p *Page) {
+		_ = drawSVGPath(p, data, m)
+		p.Path_f()
+	})
+}
+
+func (q *Type3Font) Encode(text string) string {
+	bts := make([]byte, 0, len(text))
+	for _, r := range text {
+		bts = append(bts, q.codes[r])
+	}
+	return string(bts)
+}
+func (q *Type3Font) GetWidth(text string, fontSize float64) float64 {
+	var w float64
+	for _, r := range text {
+		if c, ok := q.codes[r]; ok {
+			w += q.glyphs[c-1].width
+		}
+	}
+	return w * fontSize / 1000
+}
+func (q *Type3Font) GetAscent(fontSize float64) float64 {
+	return q.ascent * fontSize / 1000
+}
+func (q *Type3Font) GetTop(fontSize float64) float64 {
+	return q.ascent * fontSize / 1000
+}
+func (q *Type3Font) GetBottom(fontSize float64) float64 {
+	return q.descent * fontSize / 1000
+}
+func (q *Type3Font) GetHeight(fontSize float64) float64 {
+	return (q.ascent - q.descent) * fontSize / 1000
+}
+func (q *Type3Font) GetUnderlineThickness(size float64) float64 {
+	return 50 * size / 1000
+}
+func (q *Type3Font) GetUnderlinePosition(size float64) float64 {
+	return -100 * size / 1000
+}
+func (q *Type3Font) HasGylph(runes []rune) []bool {
+	dest := make([]bool, 0, len(runes))
+	for _, r := range runes {
+		_, ok := q.codes[r]
+		dest = append(dest, ok)
+	}
+	return dest
+}
+func (q *Type3Font) FallbackFont() FontHandler {
+	return nil
+}
+func (q *Type3Font) finish() error {
+	if q.onFinish == nil {
+		return nil
+	}
+	return q.onFinish()
+}
@@ -0,0 +1,225 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/raceresult/gopdf/pdf/unitype"
+	"github.com/raceresult/gopdf/types"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestStandardFontKerning(t *testing.T) {
+	f := NewFile()
+	font, err := f.NewStandardFont(types.StandardFont_Helvetica, types.EncodingWinAnsi)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Helvetica.afm: KPX A V -70, KPX V A -80
+	unkerned := font.GetWidth("AVA", 10)
+	font.SetKerning(true)
+	if got, want := font.GetWidth("AVA", 10), unkerned-1.5; math.Abs(got-want) > 1e-9 {
+		t.Errorf("got width %v, want %v", got, want)
+	}
+
+	p := f.NewPage(595, 842)
+	p.TextState_Tf(font, 10)
+	p.contents = nil
+	p.TextShowing_Tj("AVA")
+	if got, want := string(bytes.Join(p.contents, []byte{'\n'})), "[(A) 70 (V) 80 (A)] TJ"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	font.SetKerning(false)
+	if got := font.GetWidth("AVA", 10); got != unkerned {
+		t.Errorf("got width %v with kerning disabled, want %v", got, unkerned)
+	}
+}
+
+// addFontTable returns the font file with the table added, the other tables are copied
+func addFontTable(ttf []byte, tag string, data []byte) []byte {
+	numTables := int(binary.BigEndian.Uint16(ttf[4:]))
+	tables := map[string][]byte{tag: data}
+	for i := 0; i < numTables; i++ {
+		rec := ttf[12+16*i:]
+		offset := binary.BigEndian.Uint32(rec[8:])
+		tables[string(rec[:4])] = ttf[offset : offset+binary.BigEndian.Uint32(rec[12:])]
+	}
+	tags := make([]string, 0, len(tables))
+	for t := range tables {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+
+	res := make([]byte, 12+16*len(tags))
+	copy(res, ttf[:4])
+	binary.BigEndian.PutUint16(res[4:], uint16(len(tags)))
+	for i, t := range tags {
+		rec := res[12+16*i:]
+		copy(rec, t)
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(res)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(tables[t])))
+		res = append(res, tables[t]...)
+		for len(res)%4 != 0 {
+			res = append(res, 0)
+		}
+	}
+	return res
+}
+
+func TestColorFont(t *testing.T) {
+	plain, err := unitype.Parse(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := plain.LookupRunes([]rune("AOIl"))
+
+	// A: layers O in red, I in the text color and l in semi-transparent blue
+	colr := []uint16{0, 1, 0, 14, 0, 20, 3, uint16(g[0]), 0, 3,
+		uint16(g[1]), 0, uint16(g[2]), 0xFFFF, uint16(g[3]), 1}
+	cpal := []uint16{0, 2, 1, 2, 0, 14, 0, 0x0000, 0xFFFF, 0xFF00, 0x0080}
+	var colrData, cpalData bytes.Buffer
+	_ = binary.Write(&colrData, binary.BigEndian, colr)
+	_ = binary.Write(&cpalData, binary.BigEndian, cpal)
+	ttf := addFontTable(addFontTable(goregular.TTF, "COLR", colrData.Bytes()), "CPAL", cpalData.Bytes())
+
+	f := NewFile()
+	f.CompressStreamsThreshold = math.MaxInt32
+	font, err := f.NewColorFont(ttf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := font.Encode("ABA"); got != "\x01\x02\x01" {
+		t.Errorf("got codes %q, want %q", got, "\x01\x02\x01")
+	}
+	p := f.NewPage(595, 842)
+	p.TextState_Tf(font, 10)
+	p.TextShowing_Tj("AB")
+	if _, err := f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := f.creator.GetObject(font.Reference())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t3, ok := obj.(*types.Type3Font)
+	if !ok {
+		t.Fatalf("expected Type 3 font, got %T", obj)
+	}
+	charProc := func(gid unitype.GlyphIndex) string {
+		t.Helper()
+		obj, err := f.creator.GetObject(t3.CharProcs.(types.Dictionary)[types.Name("g"+strconv.Itoa(int(gid)))].(types.Reference))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "\n" + string(obj.(types.StreamObject).Stream) + "\n"
+	}
+
+	// color glyph: three filled layers, the second one without color
+	layers := charProc(g[0])
+	if strings.Count(layers, "\nf\n") != 3 || strings.Count(layers, " rg\n") != 2 {
+		t.Errorf("unexpected layers of color glyph:\n%s", layers)
+	}
+	if !strings.Contains(layers, "\n1 0 0 rg\n") || !strings.Contains(layers, "\n0 0 1 rg\n") ||
+		!strings.Contains(layers, " gs\n") {
+		t.Errorf("missing colors of color glyph:\n%s", layers)
+	}
+
+	// glyph without color layers: outline in the text color
+	if outline := charProc(plain.LookupRunes([]rune("B"))[0]); strings.Contains(outline, " rg") ||
+		!strings.Contains(outline, "\nf\n") {
+		t.Errorf("unexpected description of glyph without color:\n%s", outline)
+	}
+	if widths, ok := t3.Widths.(types.Array); !ok || len(widths) != 3 || t3.LastChar != types.Int(2) {
+		t.Errorf("got widths %v, last char %v", t3.Widths, t3.LastChar)
+	}
+}
+
+func TestType3Font(t *testing.T) {
+	f := NewFile()
+	f.CompressStreamsThreshold = math.MaxInt32
+	font := f.NewType3Font(800, -200)
+	if err := font.AddGlyph('A', 600, func(p *Page) {
+		p.Path_re(0, 0, 600, 700)
+		p.Path_f()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := font.AddGlyph(' ', 250, func(p *Page) {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := font.AddGlyphSVG('B', "M0 0 H10 V10 H0 Z", 10, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := font.AddGlyph('A', 600, func(p *Page) {}); err == nil {
+		t.Error("no error for glyph added twice")
+	}
+	if err := font.AddGlyphSVG('C', "M0", 10, 10); err == nil {
+		t.Error("no error for invalid SVG path data")
+	}
+	if err := font.AddGlyphSVG('C', "M0 0 H10", 0, 10); err == nil {
+		t.Error("no error for invalid SVG viewBox")
+	}
+
+	// space keeps code 32, the other characters get the lowest free codes
+	if got, want := font.Encode("A B"), "\x01\x20\x02"; got != want {
+		t.Errorf("got codes %q, want %q", got, want)
+	}
+	if got := font.GetWidth("A BC", 10); math.Abs(got-18.5) > 1e-9 {
+		t.Errorf("got width %v, want 18.5", got)
+	}
+	if got := font.HasGylph([]rune("ABC ")); !reflect.DeepEqual(got, []bool{true, true, false, true}) {
+		t.Errorf("got HasGylph %v", got)
+	}
+
+	p := f.NewPage(595, 842)
+	p.TextState_Tf(font, 10)
+	p.TextShowing_Tj("A B")
+	if _, err := f.Write(); err != nil {
+		t.Fatal(err)
+	}
+	obj, err := f.creator.GetObject(font.Reference())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t3, ok := obj.(*types.Type3Font)
+	if !ok {
+		t.Fatalf("expected Type 3 font, got %T", obj)
+	}
+	if t3.FirstChar != types.Int(1) || t3.LastChar != types.Int(32) {
+		t.Errorf("got characters %v to %v, want 1 to 32", t3.FirstChar, t3.LastChar)
+	}
+	widths, _ := t3.Widths.(types.Array)
+	if len(widths) != 32 || widths[0] != types.Number(600) || widths[1] != types.Number(1000) ||
+		widths[2] != types.Number(0) || widths[31] != types.Number(250) {
+		t.Errorf("got widths %v", widths)
+	}
+	differences := t3.Encoding.(types.Dictionary)["Differences"].(types.Array)
+	if len(differences) != 33 || differences[1] != types.Name("u0041") || differences[2] != types.Name("u0042") ||
+		differences[3] != types.Name(".notdef") || differences[32] != types.Name("u0020") {
+		t.Errorf("got differences %v", differences)
+	}
+
+	// the SVG path is scaled to one em with the top at the ascent
+	charProcs := t3.CharProcs.(types.Dictionary)
+	if len(charProcs) != 4 {
+		t.Errorf("got %d glyph descriptions, want 4", len(charProcs))
+	}
+	ref, _ := charProcs["u0042"].(types.Reference)
+	obj, err = f.creator.GetObject(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, _ := obj.(types.StreamObject)
+	if got, want := string(stream.Stream), "1000 0 d0\n0 800 m\n1000 800 l\n1000 -200 l\n0 -200 l\nh\nf"; got != want {
+		t.Errorf("got glyph description\n%s\nwant\n%s", got, want)
+	}
+}
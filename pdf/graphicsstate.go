@@ -16,6 +16,7 @@ type graphicsState struct {
 	DashPhase        float64
 	DashArray        []float64
 	TextState        types.TextState
+	CTM              [6]float64 // current transformation matrix, all zero for the identity matrix
 }
 
 // graphicsStateColor handles colors within the graphicsState
@@ -54,3 +55,11 @@ func (q *graphicsStateColor) SetIfNotEqual(name types.ColorSpaceFamily, addName
 	q.Values = values
 	return true
 }
+
+// ctm returns the current transformation matrix
+func (q *graphicsState) ctm() [6]float64 {
+	if q.CTM == [6]float64{} {
+		return [6]float64{1, 0, 0, 1, 0, 0}
+	}
+	return q.CTM
+}
@@ -2,6 +2,7 @@ package pdf
 
 import (
 	"bytes"
+	"errors"
 	"strconv"
 
 	"github.com/raceresult/gopdf/pdffile"
@@ -13,6 +14,9 @@ type Page struct {
 	// additional page data, can also be modified from the outside
 	Data types.Page
 
+	// text of text elements is drawn as glyph outlines instead of by fonts, see TextOutline
+	TextOutlines bool
+
 	// list of commands/operators already added to the page
 	contents [][]byte
 
@@ -22,6 +26,12 @@ type Page struct {
 	// internal text and graphics state to check if commands actually change the state
 	graphicsState      *graphicsState
 	graphicsStateStack []*graphicsState
+
+	// reference of the page object if already added to the file, e.g. for imported pages
+	reference types.Reference
+
+	// link annotations added to the page, see AddLink
+	links []types.Dictionary
 }
 
 // NewPage creates and returns a new page
@@ -83,7 +93,7 @@ func (q *Page) AddFont(f FontHandler) types.Name {
 	}
 
 	// create new name and add
-	n := types.Name("F" + strconv.Itoa(len(d)+1))
+	n := uniqueName(d, "F")
 	d[n] = ref
 	res.Font = d
 	q.Data.Resources = res
@@ -111,7 +121,7 @@ func (q *Page) AddXObject(obj types.Reference) types.Name {
 	}
 
 	// create new name and add
-	n := types.Name("img" + strconv.Itoa(len(d)+1))
+	n := uniqueName(d, "img")
 	d[n] = obj
 	res.XObject = d
 	q.Data.Resources = res
@@ -141,13 +151,91 @@ func (q *Page) AddExtGState(obj types.Dictionary) types.Name {
 	}
 
 	// create new name and add
-	n := types.Name("GS" + strconv.Itoa(len(d)+1))
+	n := uniqueName(d, "GS")
 	d[n] = obj
 	res.ExtGState = d
 	q.Data.Resources = res
 	return n
 }
 
+// uniqueName returns a resource name with the given prefix that is not used in the dictionary yet
+func uniqueName(d types.Dictionary, prefix string) types.Name {
+	for i := len(d) + 1; ; i++ {
+		n := types.Name(prefix + strconv.Itoa(i))
+		if _, ok := d[n]; !ok {
+			return n
+		}
+	}
+}
+
+// readResources converts the resources of a parsed or copied page to a ResourceDictionary. Fonts, XObjects and
+// ExtGStates are resolved and copied, so that they can be extended without changing objects shared with other pages.
+func readResources(obj types.Object, file types.Resolver) (types.ResourceDictionary, error) {
+	var res types.ResourceDictionary
+	if obj == nil {
+		return res, nil
+	}
+	obj, err := file.ResolveReference(obj)
+	if err != nil {
+		return res, err
+	}
+	switch v := obj.(type) {
+	case types.ResourceDictionary:
+		res = v
+	case types.Dictionary:
+		if err := res.Read(v); err != nil {
+			return res, err
+		}
+	default:
+		return res, errors.New("page resources invalid")
+	}
+
+	// resolve and copy sub-dictionaries
+	copyDict := func(obj types.Object) (types.Object, error) {
+		if obj == nil {
+			return nil, nil
+		}
+		obj, err := file.ResolveReference(obj)
+		if err != nil {
+			return nil, err
+		}
+		d, ok := obj.(types.Dictionary)
+		if !ok {
+			return nil, errors.New("page resources invalid")
+		}
+		c := make(types.Dictionary, len(d))
+		for k, v := range d {
+			c[k] = v
+		}
+		return c, nil
+	}
+	if res.Font, err = copyDict(res.Font); err != nil {
+		return res, err
+	}
+	if res.XObject, err = copyDict(res.XObject); err != nil {
+		return res, err
+	}
+	if res.ExtGState, err = copyDict(res.ExtGState); err != nil {
+		return res, err
+	}
+	if res.ProcSet != nil {
+		ps, err := file.ResolveReference(res.ProcSet)
+		if err != nil {
+			return res, err
+		}
+		arr, _ := ps.(types.Array)
+		procSets := make(types.Array, 0, len(arr))
+		for _, v := range arr {
+			if n, ok := v.(types.Name); ok {
+				v = types.ProcedureSet(n)
+			}
+			procSets = append(procSets, v)
+		}
+		res.ProcSet = procSets
+	}
+	return res, nil
+}
+
 // AddCommand adds any command/pdf operator to the content stream of the page
 func (q *Page) AddCommand(operator string, args ...types.Object) {
 	arr := make([][]byte, 0, len(args)+1)
@@ -161,26 +249,88 @@ func (q *Page) AddCommand(operator string, args ...types.Object) {
 // create is called when building the pdf file. It is supposed to add all objects to the creator and return a
 // reference to the page object
 func (q *Page) create(creator *pdffile.File, compressThreshold int) (types.Reference, error) {
-	// Content may already be set if page was copied from other PDF
-	if q.Data.Contents==nil {
-		// join data
-		data := bytes.Join(q.contents, []byte{'\n'})
-
-		// create stream
-		var stream types.StreamObject
-		var err error
-		if len(data) >= compressThreshold {
-			stream, err = types.NewStream(data, types.Filter_FlateDecode)
-		} else {
-			stream, err = types.NewStream(data)
+	switch {
+	case q.Data.Contents == nil:
+		ref, err := newContentStream(creator, q.contents, compressThreshold)
+		if err != nil {
+			return types.Reference{}, err
 		}
+		q.Data.Contents = ref
+
+	case len(q.contents) != 0:
+		// content already set since page was copied from other PDF: add new content on top
+		contents, err := combineContents(creator, q.Data.Contents, nil, q.contents, compressThreshold)
 		if err != nil {
 			return types.Reference{}, err
 		}
+		q.Data.Contents = contents
+	}
 
-		// create page object and return reference to it
-		q.Data.Contents = creator.AddObject(stream)
+	if err := q.addLinks(creator); err != nil {
+		return types.Reference{}, err
 	}
 
+	if q.reference.Number != 0 {
+		return q.reference, nil
+	}
 	return creator.AddObject(q.Data), nil
 }
+
+// newContentStream adds a content stream consisting of the given commands to the file and returns its reference
+func newContentStream(creator *pdffile.File, contents [][]byte, compressThreshold int) (types.Reference, error) {
+	// join data
+	data := bytes.Join(contents, []byte{'\n'})
+
+	// create stream
+	var stream types.StreamObject
+	var err error
+	if len(data) >= compressThreshold {
+		stream, err = types.NewStream(data, types.Filter_FlateDecode)
+	} else {
+		stream, err = types.NewStream(data)
+	}
+	if err != nil {
+		return types.Reference{}, err
+	}
+	return creator.AddObject(stream), nil
+}
+
+// combineContents returns the content streams of a page with new content added below and above the existing content.
+// The existing content is enclosed in q/Q so that changes of the graphics state do not affect the new content.
+func combineContents(creator *pdffile.File, existing types.Object, under, over [][]byte, compressThreshold int) (types.Array, error) {
+	// existing content streams
+	obj, err := creator.ResolveReference(existing)
+	if err != nil {
+		return nil, err
+	}
+	var existingArr types.Array
+	if arr, ok := obj.(types.Array); ok {
+		existingArr = arr
+	} else {
+		existingArr = types.Array{existing}
+	}
+
+	var res types.Array
+	if len(under) != 0 {
+		ref, err := newContentStream(creator, append(append([][]byte{[]byte("q")}, under...), []byte("Q")), compressThreshold)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ref)
+	}
+	if len(over) == 0 {
+		return append(res, existingArr...), nil
+	}
+
+	ref, err := newContentStream(creator, [][]byte{[]byte("q")}, compressThreshold)
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, ref)
+	res = append(res, existingArr...)
+	ref, err = newContentStream(creator, append([][]byte{[]byte("Q")}, over...), compressThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return append(res, ref), nil
+}
@@ -31,6 +31,13 @@ func (q *Page) GraphicsState_Q() {
 // operands specify a matrix, they are written as six separate numbers, not as
 // an array.
 func (q *Page) GraphicsState_cm(a, b, c, d, e, f float64) {
+	m := q.graphicsState.ctm()
+	q.graphicsState.CTM = [6]float64{
+		a*m[0] + b*m[2], a*m[1] + b*m[3],
+		c*m[0] + d*m[2], c*m[1] + d*m[3],
+		e*m[0] + f*m[2] + m[4], e*m[1] + f*m[3] + m[5],
+	}
+
 	q.AddCommand("cm", types.Number(a), types.Number(b), types.Number(c), types.Number(d), types.Number(e), types.Number(f))
 }
 
@@ -0,0 +1,77 @@
+package pdf
+
+import (
+	"errors"
+	"math"
+
+	"github.com/raceresult/gopdf/pdffile"
+	"github.com/raceresult/gopdf/types"
+)
+
+// AddLink adds a link annotation opening the URI when the rectangle is clicked. The rectangle is given in the current
+// user space, so the current transformation matrix applies; if rotated or skewed, the bounding box of the rectangle
+// becomes the active area.
+func (q *Page) AddLink(x, y, width, height float64, uri string) {
+	m := q.graphicsState.ctm()
+	llx, lly := math.Inf(1), math.Inf(1)
+	urx, ury := math.Inf(-1), math.Inf(-1)
+	for _, p := range [4][2]float64{{x, y}, {x + width, y}, {x, y + height}, {x + width, y + height}} {
+		px := p[0]*m[0] + p[1]*m[2] + m[4]
+		py := p[0]*m[1] + p[1]*m[3] + m[5]
+		llx, lly = math.Min(llx, px), math.Min(lly, py)
+		urx, ury = math.Max(urx, px), math.Max(ury, py)
+	}
+
+	q.links = append(q.links, types.Dictionary{
+		"Type":    types.Name("Annot"),
+		"Subtype": types.Name("Link"),
+		"Rect": types.Rectangle{
+			LLX: types.Number(llx), LLY: types.Number(lly),
+			URX: types.Number(urx), URY: types.Number(ury),
+		},
+		"Border": types.Array{types.Int(0), types.Int(0), types.Int(0)},
+		"A": types.Dictionary{
+			"S":   types.Name("URI"),
+			"URI": types.String(uri),
+		},
+	})
+}
+
+// addLinks adds the link annotations to the file and the Annots array of the page
+func (q *Page) addLinks(creator *pdffile.File) error {
+	if len(q.links) == 0 {
+		return nil
+	}
+	annots, err := appendAnnots(creator, q.Data.Annots, q.links)
+	if err != nil {
+		return err
+	}
+	q.Data.Annots = annots
+	q.links = nil
+	return nil
+}
+
+// appendAnnots adds the link annotations to the file and returns the existing annotations of a page, given as array or
+// as reference to an array (e.g. of copied pages), with the links appended
+func appendAnnots(creator *pdffile.File, existing types.Object, links []types.Dictionary) (types.Array, error) {
+	if ref, ok := existing.(types.Reference); ok {
+		obj, err := creator.GetObject(ref)
+		if err != nil {
+			return nil, err
+		}
+		existing = obj
+	}
+
+	var annots types.Array
+	switch v := existing.(type) {
+	case nil, types.Null:
+	case types.Array:
+		annots = append(annots, v...)
+	default:
+		return nil, errors.New("page field Annots invalid")
+	}
+	for _, link := range links {
+		annots = append(annots, creator.AddObject(link))
+	}
+	return annots, nil
+}
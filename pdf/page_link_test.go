@@ -0,0 +1,86 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/raceresult/gopdf/parser"
+	"github.com/raceresult/gopdf/types"
+)
+
+// pageAnnots parses the file and returns the annotations of the first page
+func pageAnnots(t *testing.T, bts []byte) types.Array {
+	t.Helper()
+	p, err := parser.New(bts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStamper(p.File(), bts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := s.GetPage(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	annots, err := appendAnnots(p.File(), page.Annots, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return annots
+}
+
+func TestAddLinksIndirectAnnots(t *testing.T) {
+	f := NewFile()
+	annot := f.creator.AddObject(types.Dictionary{"Type": types.Name("Annot"), "Subtype": types.Name("Text")})
+	page := f.NewPage(595, 842)
+	page.Data.Annots = f.creator.AddObject(types.Array{annot})
+	page.AddLink(10, 10, 100, 20, "https://www.raceresult.com")
+	bts, err := f.Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if annots := pageAnnots(t, bts); len(annots) != 2 {
+		t.Errorf("got %d annotations, want 2", len(annots))
+	}
+}
+
+func TestStamperLinks(t *testing.T) {
+	f := NewFile()
+	f.NewPage(595, 842)
+	source, err := f.Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, incremental := range []bool{false, true} {
+		p, err := parser.New(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := NewStamper(p.File(), source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, err := s.Foreground(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page.AddLink(10, 10, 100, 20, "https://www.raceresult.com")
+		bts, err := s.Write(incremental)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if annots := pageAnnots(t, bts); len(annots) != 1 {
+			t.Errorf("incremental %v: got %d annotations, want 1", incremental, len(annots))
+		}
+	}
+}
+
+func TestNewFormFromPageLinks(t *testing.T) {
+	f := NewFile()
+	page := NewPage(595, 842)
+	page.AddLink(10, 10, 100, 20, "https://www.raceresult.com")
+	if _, err := f.NewFormFromPage(page); err == nil {
+		t.Error("expected error for links in form")
+	}
+}
@@ -0,0 +1,119 @@
+package pdf
+
+import (
+	"errors"
+
+	"github.com/raceresult/gopdf/pdf/unitype"
+	"github.com/raceresult/gopdf/types"
+)
+
+// outlineFont is implemented by fonts providing the outlines of their glyphs, so that text can be drawn as paths, see
+// Page.TextOutline
+type outlineFont interface {
+	glyphOutlines(text string) ([]glyphOutline, error)
+}
+
+// glyphOutline is a glyph of a text with its outline
+type glyphOutline struct {
+	segments         []unitype.PathSegment // outline in font units
+	scale            float64               // factor from font units to 1000 units per em
+	advance          float64               // advance in 1000 units per em including positioning adjustments
+	xOffset, yOffset float64               // offset from the regular position in 1000 units per em
+	space            bool                  // glyph of a space character, word spacing applies
+}
+
+// HasOutlines checks if the glyph outlines of the font are available, which is required to draw text as outlines by
+// TextOutline. Standard fonts, Type 1 fonts, Type 3 fonts and color fonts do not provide outlines.
+func HasOutlines(font FontHandler) bool {
+	_, ok := font.(outlineFont)
+	return ok
+}
+
+// TextOutline draws the glyph outlines of the text as path instead of showing the text by the font, so that no font
+// is needed in the document, e.g. for print-ready logos. The path is painted according to the text rendering mode
+// (clipping is not supported) with the current colors and line width. Character spacing, word spacing, horizontal
+// scaling and text rise of the current text state apply, the text matrix [a b c d e f] positions the text like the
+// Tm operator. Must not be called within a text object.
+func (q *Page) TextOutline(font FontHandler, fontSize float64, s string, a, b, c, d, e, f float64) error {
+	of, ok := font.(outlineFont)
+	if !ok {
+		return errors.New("glyph outlines of font are not available")
+	}
+	glyphs, err := of.glyphOutlines(s)
+	if err != nil {
+		return err
+	}
+
+	ts := q.graphicsState.TextState
+	th := 1.0
+	if ts.Th != 0 {
+		th = ts.Th / 100
+	}
+
+	// glyph space to text space, then text space to user space by the text matrix
+	var x float64
+	var path bool
+	for _, g := range glyphs {
+		if len(g.segments) != 0 {
+			k := g.scale * fontSize / 1000
+			gx := x + g.xOffset*fontSize/1000*th
+			gy := g.yOffset*fontSize/1000 + ts.Trise
+			drawOutline(q, g.segments, [6]float64{
+				k * th * a, k * th * b,
+				k * c, k * d,
+				gx*a + gy*c + e, gx*b + gy*d + f,
+			})
+			path = true
+		}
+		x += g.advance * fontSize / 1000 * th
+		x += ts.Tc * th
+		if g.space {
+			x += ts.Tw * th
+		}
+	}
+	if !path {
+		return nil
+	}
+
+	switch ts.Tmode % 4 {
+	case types.RenderingModeFill:
+		q.Path_f()
+	case types.RenderingModeStroke:
+		q.Path_S()
+	case types.RenderingModeFillAndStroke:
+		q.Path_B()
+	default:
+		q.Path_n()
+	}
+	return nil
+}
+
+// unitypeGlyphOutlines returns the outlines of the shaped glyphs of a font parsed by unitype
+func unitypeGlyphOutlines(fnt *unitype.Font, glyphs []unitype.ShapedGlyph) ([]glyphOutline, error) {
+	scale := 1000 / float64(fnt.UnitsPerEm())
+	res := make([]glyphOutline, 0, len(glyphs))
+	for _, g := range glyphs {
+		segments, err := fnt.GlyphOutline(g.Index)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, glyphOutline{
+			segments: segments,
+			scale:    scale,
+			advance:  float64(g.Advance),
+			xOffset:  float64(g.XOffset),
+			yOffset:  float64(g.YOffset),
+			space:    len(g.Runes) == 1 && g.Runes[0] == ' ',
+		})
+	}
+	return res, nil
+}
+
+// unshapedGlyphs returns the glyphs of the runes by the cmap of the font without shaping
+func unshapedGlyphs(fnt *unitype.Font, runes []rune) []unitype.ShapedGlyph {
+	res := make([]unitype.ShapedGlyph, len(runes))
+	for i, ind := range fnt.LookupRunes(runes) {
+		res[i] = unitype.ShapedGlyph{Index: ind, Runes: runes[i : i+1], Advance: fnt.GetGlyphAdvance(ind)}
+	}
+	return res
+}
@@ -1,17 +1,100 @@
 package pdf
 
-import "github.com/raceresult/gopdf/types"
+import (
+	"strings"
+
+	"github.com/raceresult/gopdf/types"
+)
 
 // PDF Reference 1.4, Table 5.6 Text-showing operators
 
-// TextShowing_Tj shows a text string.
+// shapingFont is implemented by fonts positioning glyphs individually, their text is shown by the TJ operator
+type shapingFont interface {
+	shaping() bool
+	encodeShaped(text string) []shapedRun
+}
+
+// shapedRun is a part of a shaped text shown by one TJ operator. yOffset is the vertical offset of its glyphs in
+// thousandths of the font size, it is applied by the text rise.
+type shapedRun struct {
+	yOffset int
+	array   types.Array
+}
+
+// TextShowing_Tj shows a text string. Fonts with OpenType shaping enabled show the text by TJ to apply the glyph
+// positioning. Composite fonts show the text by TJ if word spacing is set, since Tw does not apply to multi-byte
+// codes.
 func (q *Page) TextShowing_Tj(s string) {
+	if q.multiByteFont() && q.graphicsState.TextState.Tw != 0 && q.graphicsState.TextState.Tfs != 0 &&
+		strings.Contains(s, " ") {
+		q.showShaped(q.wordSpacedRuns(s))
+		return
+	}
+	if sf, ok := q.currFont.(shapingFont); ok && sf.shaping() {
+		q.showShaped(sf.encodeShaped(s))
+		return
+	}
 	if q.currFont != nil {
 		s = q.currFont.Encode(s)
 	}
 	q.AddCommand("Tj", types.String(s))
 }
 
+// multiByteFont checks if the current font uses multi-byte codes
+func (q *Page) multiByteFont() bool {
+	switch q.currFont.(type) {
+	case *CompositeFont, *CompositeFontOTF:
+		return true
+	}
+	return false
+}
+
+// wordSpacedRuns builds the runs for the TJ operator with the current word spacing applied after each space
+func (q *Page) wordSpacedRuns(s string) []shapedRun {
+	var res []shapedRun
+	adjust := -q.graphicsState.TextState.Tw * 1000 / q.graphicsState.TextState.Tfs
+	for _, part := range strings.SplitAfter(s, " ") {
+		if part == "" {
+			continue
+		}
+		var runs []shapedRun
+		if sf, ok := q.currFont.(shapingFont); ok && sf.shaping() {
+			runs = sf.encodeShaped(part)
+		} else {
+			runs = []shapedRun{{array: types.Array{types.String(q.currFont.Encode(part))}}}
+		}
+		for _, run := range runs {
+			if n := len(res); n != 0 && res[n-1].yOffset == run.yOffset {
+				res[n-1].array = append(res[n-1].array, run.array...)
+			} else {
+				res = append(res, run)
+			}
+		}
+		if strings.HasSuffix(part, " ") && len(res) != 0 {
+			res[len(res)-1].array = append(res[len(res)-1].array, types.Number(adjust))
+		}
+	}
+	return res
+}
+
+// showShaped shows the runs by the TJ operator. The vertical offsets of the runs are applied by the text rise, which
+// is restored afterwards.
+func (q *Page) showShaped(runs []shapedRun) {
+	rise := q.graphicsState.TextState.Trise
+	curr := rise
+	for _, run := range runs {
+		ts := rise + float64(run.yOffset)*q.graphicsState.TextState.Tfs/1000
+		if ts != curr {
+			q.AddCommand("Ts", types.Number(ts))
+			curr = ts
+		}
+		q.AddCommand("TJ", run.array)
+	}
+	if curr != rise {
+		q.AddCommand("Ts", types.Number(rise))
+	}
+}
+
 // TextShowing_Pos moves to the next line and show a text string. This operator has the same effect as
 // the code
 // T*
@@ -0,0 +1,69 @@
+package pdf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/raceresult/gopdf/types"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestShowShapedRise(t *testing.T) {
+	f := NewFile()
+	p := f.NewPage(595, 842)
+	p.graphicsState.TextState.Tfs = 10
+	p.TextState_Ts(1)
+	p.contents = nil
+
+	p.showShaped([]shapedRun{
+		{array: types.Array{types.String("a")}},
+		{yOffset: 200, array: types.Array{types.String("b")}},
+		{array: types.Array{types.String("c")}},
+	})
+	got := string(bytes.Join(p.contents, []byte{'\n'}))
+	want := "[(a)] TJ\n3 Ts\n[(b)] TJ\n1 Ts\n[(c)] TJ"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCompositeFontShapeCache(t *testing.T) {
+	f := NewFile()
+	font, err := f.NewCompositeFontFromTTF(goregular.TTF, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	font.SetFeatures(FeatureKerning)
+
+	w := font.GetWidth("AVA", 10)
+	runs := font.encodeShaped("AVA")
+	if len(font.shapeCache) != 1 {
+		t.Errorf("text shaped %d times, expected cached result", len(font.shapeCache))
+	}
+	if len(runs) != 1 || w <= 0 {
+		t.Errorf("unexpected result: width %v, runs %v", w, runs)
+	}
+}
+
+func TestCompositeFontWordSpacing(t *testing.T) {
+	f := NewFile()
+	font, err := f.NewCompositeFontFromTTF(goregular.TTF, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := f.NewPage(595, 842)
+	p.TextState_Tf(font, 10)
+	p.TextState_Tw(2)
+	p.contents = nil
+
+	// Tw does not apply to two-byte codes, the spacing is added to the TJ array instead: -2 * 1000 / 10
+	p.TextShowing_Tj("a b c")
+	want := string(types.Array{
+		types.String(font.Encode("a ")), types.Int(-200),
+		types.String(font.Encode("b ")), types.Int(-200),
+		types.String(font.Encode("c")),
+	}.ToRawBytes()) + " TJ"
+	if got := string(bytes.Join(p.contents, []byte{'\n'})); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
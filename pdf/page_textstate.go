@@ -27,7 +27,7 @@ func (q *Page) TextState_Tw(wordSpace float64) {
 	}
 	q.graphicsState.TextState.Tw = wordSpace
 
-	q.AddCommand("Tw", types.Int(wordSpace))
+	q.AddCommand("Tw", types.Number(wordSpace))
 }
 
 // TextState_Tz sets the horizontal scaling, Th , to (scale  ̃ 100). scale is a number specifying the
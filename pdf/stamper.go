@@ -0,0 +1,230 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/raceresult/gopdf/pdffile"
+	"github.com/raceresult/gopdf/types"
+)
+
+// Stamper adds content to the pages of an existing pdf, for example watermarks. In contrast to capturing pages into
+// forms, the page objects stay unchanged except for additional content streams and resources, so that annotations,
+// links and all other page properties are preserved.
+type Stamper struct {
+	file     *File
+	original []byte
+	pages    []sourcePage
+	stamps   map[int]*stamp
+	firstNew int
+}
+
+// stamp holds the content to be added below and above the existing content of one page
+type stamp struct {
+	under *Page
+	over  *Page
+}
+
+// NewStamper creates a new Stamper for the given parsed file. The original file bytes are needed to write an
+// incremental update.
+func NewStamper(source *pdffile.File, original []byte) (*Stamper, error) {
+	// read page tree
+	catalogObj, err := source.GetObject(source.Root)
+	if err != nil {
+		return nil, err
+	}
+	catalogDict, ok := catalogObj.(types.Dictionary)
+	if !ok {
+		return nil, errors.New("catalog invalid")
+	}
+	var catalog types.DocumentCatalog
+	if err := catalog.Read(catalogDict); err != nil {
+		return nil, err
+	}
+	pages, _, err := collectSourcePages(source, catalog.Pages, nil, make(map[types.Reference]struct{}))
+	if err != nil {
+		return nil, err
+	}
+
+	// new objects like fonts and images are added to the source file directly
+	f := &File{
+		creator:                  source,
+		Version:                  source.Version,
+		CompressStreamsThreshold: 500,
+	}
+	return &Stamper{
+		file:     f,
+		original: original,
+		pages:    pages,
+		stamps:   make(map[int]*stamp),
+		firstNew: source.NextNumber(),
+	}, nil
+}
+
+// File returns the File object used to add fonts and images
+func (q *Stamper) File() *File {
+	return q.file
+}
+
+// PageCount returns the number of pages of the pdf
+func (q *Stamper) PageCount() int {
+	return len(q.pages)
+}
+
+// GetPage returns the page data of the page with the given number (first page = 1) with inherited values resolved
+func (q *Stamper) GetPage(pageNo int) (types.Page, error) {
+	if pageNo < 1 || pageNo > len(q.pages) {
+		return types.Page{}, errors.New("page " + strconv.Itoa(pageNo) + " not found")
+	}
+	return q.pages[pageNo-1].page, nil
+}
+
+// Foreground returns a Page object for drawing content above the existing content of the page (first page = 1)
+func (q *Stamper) Foreground(pageNo int) (*Page, error) {
+	s, err := q.getStamp(pageNo)
+	if err != nil {
+		return nil, err
+	}
+	return s.over, nil
+}
+
+// Background returns a Page object for drawing content below the existing content of the page (first page = 1)
+func (q *Stamper) Background(pageNo int) (*Page, error) {
+	s, err := q.getStamp(pageNo)
+	if err != nil {
+		return nil, err
+	}
+	return s.under, nil
+}
+
+// getStamp returns the stamp for the given page, creates it if not existing yet
+func (q *Stamper) getStamp(pageNo int) (*stamp, error) {
+	if pageNo < 1 || pageNo > len(q.pages) {
+		return nil, errors.New("page " + strconv.Itoa(pageNo) + " not found")
+	}
+	if s, ok := q.stamps[pageNo-1]; ok {
+		return s, nil
+	}
+
+	// both pages share the resource dictionaries, so that resource names are unique
+	sp := q.pages[pageNo-1]
+	res, err := readResources(sp.page.Resources, q.file.creator)
+	if err != nil {
+		return nil, err
+	}
+	if res.Font == nil {
+		res.Font = types.Dictionary{}
+	}
+	if res.XObject == nil {
+		res.XObject = types.Dictionary{}
+	}
+	if res.ExtGState == nil {
+		res.ExtGState = types.Dictionary{}
+	}
+
+	s := &stamp{}
+	for _, p := range []**Page{&s.under, &s.over} {
+		*p = &Page{
+			Data: types.Page{
+				MediaBox:  sp.page.MediaBox,
+				Resources: res,
+			},
+			graphicsState: &graphicsState{},
+		}
+	}
+	q.stamps[pageNo-1] = s
+	return s, nil
+}
+
+// WriteTo writes the modified pdf to the given writer, either as incremental update appended to the original file or
+// by rewriting the entire file
+func (q *Stamper) WriteTo(w io.Writer, incremental bool) (int64, error) {
+	creator := q.file.creator
+
+	// finish fonts
+	if err := q.file.finishFonts(); err != nil {
+		return 0, err
+	}
+
+	// add content streams to pages, in page order so that object numbers do not depend on map iteration
+	indices := make([]int, 0, len(q.stamps))
+	for i := range q.stamps {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	var modified []types.Reference
+	for _, i := range indices {
+		s := q.stamps[i]
+		links := append(append([]types.Dictionary{}, s.under.links...), s.over.links...)
+		drawn := len(s.under.contents) != 0 || len(s.over.contents) != 0
+		if !drawn && len(links) == 0 {
+			continue
+		}
+		sp := q.pages[i]
+		obj, err := creator.GetObject(sp.ref)
+		if err != nil {
+			return 0, err
+		}
+		dict, ok := obj.(types.Dictionary)
+		if !ok {
+			return 0, errors.New("page " + strconv.Itoa(i+1) + " is not a dictionary")
+		}
+
+		if drawn {
+			var existing types.Object = types.Array{}
+			if sp.page.Contents != nil {
+				existing = sp.page.Contents
+			}
+			contents, err := combineContents(creator, existing, s.under.contents, s.over.contents, q.file.CompressStreamsThreshold)
+			if err != nil {
+				return 0, err
+			}
+			dict["Contents"] = contents
+
+			// merge procedure sets of both pages
+			if ps, ok := s.under.Data.Resources.(types.ResourceDictionary).ProcSet.(types.Array); ok {
+				for _, v := range ps {
+					if n, ok := v.(types.ProcedureSet); ok {
+						s.over.AddProcSets(n)
+					}
+				}
+			}
+			dict["Resources"] = s.over.Data.Resources
+		}
+
+		// link annotations of both pages
+		if len(links) != 0 {
+			annots, err := appendAnnots(creator, dict["Annots"], links)
+			if err != nil {
+				return 0, err
+			}
+			dict["Annots"] = annots
+			s.under.links, s.over.links = nil, nil
+		}
+		modified = append(modified, sp.ref)
+	}
+
+	// full rewrite
+	if !incremental {
+		creator.Clean()
+		return creator.WriteTo(w)
+	}
+
+	// incremental update: modified pages and all new objects
+	for _, obj := range creator.GetObjects() {
+		if obj.Number >= q.firstNew {
+			modified = append(modified, types.Reference{Number: obj.Number, Generation: obj.Generation})
+		}
+	}
+	return creator.WriteUpdateTo(w, q.original, modified)
+}
+
+// Write returns the modified pdf as byte slice
+func (q *Stamper) Write(incremental bool) ([]byte, error) {
+	var bts bytes.Buffer
+	_, err := q.WriteTo(&bts, incremental)
+	return bts.Bytes(), err
+}
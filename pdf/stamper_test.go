@@ -0,0 +1,65 @@
+package pdf
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/raceresult/gopdf/parser"
+)
+
+// xRefStreamFile returns a pdf with one page and a cross-reference stream instead of a cross-reference table
+func xRefStreamFile() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << >> >>",
+	}
+	var bts bytes.Buffer
+	bts.WriteString("%PDF-1.5\n")
+	entries := []byte{0, 0, 0, 255}
+	for i, obj := range objects {
+		pos := bts.Len()
+		entries = append(entries, 1, byte(pos>>8), byte(pos), 0)
+		bts.WriteString(strconv.Itoa(i+1) + " 0 obj\n" + obj + "\nendobj\n")
+	}
+	startXRef := bts.Len()
+	entries = append(entries, 1, byte(startXRef>>8), byte(startXRef), 0)
+	bts.WriteString("4 0 obj\n<< /Type /XRef /Size 5 /W [1 2 1] /Root 1 0 R /Length " + strconv.Itoa(len(entries)) +
+		" >>\nstream\n")
+	bts.Write(entries)
+	bts.WriteString("\nendstream\nendobj\nstartxref\n" + strconv.Itoa(startXRef) + "\n%%EOF\n")
+	return bts.Bytes()
+}
+
+func TestStamperIncrementalXRefStream(t *testing.T) {
+	source := xRefStreamFile()
+	p, err := parser.New(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStamper(p.File(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := s.Foreground(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page.AddLink(10, 10, 100, 20, "https://www.raceresult.com")
+	bts, err := s.Write(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(bts, source) {
+		t.Fatal("original file not preserved")
+	}
+	update := bts[len(source):]
+	if bytes.Contains(update, []byte("trailer")) || !bytes.Contains(update, []byte("/Type /XRef")) {
+		t.Error("expected update with cross-reference stream")
+	}
+	if annots := pageAnnots(t, bts); len(annots) != 1 {
+		t.Errorf("got %d annotations, want 1", len(annots))
+	}
+}
@@ -0,0 +1,355 @@
+package pdf
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+// SVG path data, see https://www.w3.org/TR/SVG11/paths.html#PathData
+
+// svgPathReader reads commands and numbers of SVG path data
+type svgPathReader struct {
+	data string
+	pos  int
+}
+
+// skip skips white space and commas
+func (q *svgPathReader) skip() {
+	for q.pos < len(q.data) {
+		switch q.data[q.pos] {
+		case ' ', '\t', '\r', '\n', '\f', ',':
+			q.pos++
+		default:
+			return
+		}
+	}
+}
+
+// command returns the next command letter, 0 if the next token is a number or the end was reached
+func (q *svgPathReader) command() byte {
+	q.skip()
+	if q.pos >= len(q.data) {
+		return 0
+	}
+	c := q.data[q.pos]
+	if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		q.pos++
+		return c
+	}
+	return 0
+}
+
+// more returns true if a number follows
+func (q *svgPathReader) more() bool {
+	q.skip()
+	if q.pos >= len(q.data) {
+		return false
+	}
+	c := q.data[q.pos]
+	return c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9')
+}
+
+// number reads the next number
+func (q *svgPathReader) number() (float64, error) {
+	q.skip()
+	start := q.pos
+	if q.pos < len(q.data) && (q.data[q.pos] == '-' || q.data[q.pos] == '+') {
+		q.pos++
+	}
+	var dot, digits bool
+	for q.pos < len(q.data) {
+		c := q.data[q.pos]
+		if c >= '0' && c <= '9' {
+			digits = true
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+		q.pos++
+	}
+	if digits && q.pos < len(q.data) && (q.data[q.pos] == 'e' || q.data[q.pos] == 'E') {
+		q.pos++
+		if q.pos < len(q.data) && (q.data[q.pos] == '-' || q.data[q.pos] == '+') {
+			q.pos++
+		}
+		for q.pos < len(q.data) && q.data[q.pos] >= '0' && q.data[q.pos] <= '9' {
+			q.pos++
+		}
+	}
+	if !digits {
+		return 0, errors.New("invalid number in SVG path data at position " + strconv.Itoa(start))
+	}
+	return strconv.ParseFloat(q.data[start:q.pos], 64)
+}
+
+// flag reads an arc flag, which may be written without separator
+func (q *svgPathReader) flag() (bool, error) {
+	q.skip()
+	if q.pos < len(q.data) {
+		switch q.data[q.pos] {
+		case '0':
+			q.pos++
+			return false, nil
+		case '1':
+			q.pos++
+			return true, nil
+		}
+	}
+	return false, errors.New("invalid flag in SVG path data at position " + strconv.Itoa(q.pos))
+}
+
+// numbers reads the given count of numbers
+func (q *svgPathReader) numbers(n int) ([]float64, error) {
+	res := make([]float64, n)
+	for i := range res {
+		v, err := q.number()
+		if err != nil {
+			return nil, err
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+// drawSVGPath adds the path given as SVG path data (the d attribute of a path element) to the page. Coordinates are
+// transformed by the matrix [a b c d e f], like by the cm operator. Quadratic curves and elliptical arcs are converted
+// to cubic Bézier curves.
+func drawSVGPath(p *Page, data string, m [6]float64) error {
+	tr := func(x, y float64) (float64, float64) {
+		return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+	}
+	moveTo := func(x, y float64) {
+		p.Path_m(tr(x, y))
+	}
+	lineTo := func(x, y float64) {
+		p.Path_l(tr(x, y))
+	}
+	curveTo := func(x1, y1, x2, y2, x3, y3 float64) {
+		tx1, ty1 := tr(x1, y1)
+		tx2, ty2 := tr(x2, y2)
+		tx3, ty3 := tr(x3, y3)
+		p.Path_c(tx1, ty1, tx2, ty2, tx3, ty3)
+	}
+
+	r := &svgPathReader{data: data}
+	var x, y, startX, startY float64 // current point and start of the subpath
+	var ctrlX, ctrlY float64         // last control point for smooth curves
+	var prev byte
+	cmd := r.command()
+	if cmd == 0 && r.more() {
+		return errors.New("SVG path data must start with a command")
+	}
+	for cmd != 0 {
+		rel := cmd >= 'a'
+		var dx, dy float64
+		if rel {
+			dx, dy = x, y
+		}
+
+		switch cmd {
+		case 'Z', 'z':
+			p.Path_h()
+			x, y = startX, startY
+
+		case 'M', 'm':
+			v, err := r.numbers(2)
+			if err != nil {
+				return err
+			}
+			x, y = v[0]+dx, v[1]+dy
+			startX, startY = x, y
+			moveTo(x, y)
+
+			// additional coordinate pairs are implicit lineto commands
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+
+		case 'L', 'l':
+			v, err := r.numbers(2)
+			if err != nil {
+				return err
+			}
+			x, y = v[0]+dx, v[1]+dy
+			lineTo(x, y)
+
+		case 'H', 'h':
+			v, err := r.number()
+			if err != nil {
+				return err
+			}
+			x = v + dx
+			lineTo(x, y)
+
+		case 'V', 'v':
+			v, err := r.number()
+			if err != nil {
+				return err
+			}
+			y = v + dy
+			lineTo(x, y)
+
+		case 'C', 'c', 'S', 's':
+			var x1, y1 float64
+			var v []float64
+			var err error
+			if cmd == 'C' || cmd == 'c' {
+				if v, err = r.numbers(6); err != nil {
+					return err
+				}
+				x1, y1 = v[0]+dx, v[1]+dy
+				v = v[2:]
+			} else {
+				if v, err = r.numbers(4); err != nil {
+					return err
+				}
+				x1, y1 = x, y
+				switch prev {
+				case 'C', 'c', 'S', 's':
+					x1, y1 = 2*x-ctrlX, 2*y-ctrlY
+				}
+			}
+			ctrlX, ctrlY = v[0]+dx, v[1]+dy
+			x, y = v[2]+dx, v[3]+dy
+			curveTo(x1, y1, ctrlX, ctrlY, x, y)
+
+		case 'Q', 'q', 'T', 't':
+			var v []float64
+			var err error
+			if cmd == 'Q' || cmd == 'q' {
+				if v, err = r.numbers(4); err != nil {
+					return err
+				}
+				ctrlX, ctrlY = v[0]+dx, v[1]+dy
+				v = v[2:]
+			} else {
+				if v, err = r.numbers(2); err != nil {
+					return err
+				}
+				switch prev {
+				case 'Q', 'q', 'T', 't':
+					ctrlX, ctrlY = 2*x-ctrlX, 2*y-ctrlY
+				default:
+					ctrlX, ctrlY = x, y
+				}
+			}
+			ex, ey := v[0]+dx, v[1]+dy
+			curveTo(x+2*(ctrlX-x)/3, y+2*(ctrlY-y)/3, ex+2*(ctrlX-ex)/3, ey+2*(ctrlY-ey)/3, ex, ey)
+			x, y = ex, ey
+
+		case 'A', 'a':
+			v, err := r.numbers(3)
+			if err != nil {
+				return err
+			}
+			large, err := r.flag()
+			if err != nil {
+				return err
+			}
+			sweep, err := r.flag()
+			if err != nil {
+				return err
+			}
+			end, err := r.numbers(2)
+			if err != nil {
+				return err
+			}
+			ex, ey := end[0]+dx, end[1]+dy
+			svgArc(x, y, v[0], v[1], v[2], large, sweep, ex, ey, curveTo, lineTo)
+			x, y = ex, ey
+
+		default:
+			return errors.New("unknown command " + string(cmd) + " in SVG path data")
+		}
+		prev = cmd
+
+		// commands may be repeated by giving further parameters
+		if cmd != 'Z' && cmd != 'z' && r.more() {
+			continue
+		}
+		cmd = r.command()
+		if cmd == 0 && r.pos < len(r.data) {
+			return errors.New("invalid SVG path data at position " + strconv.Itoa(r.pos))
+		}
+	}
+	return nil
+}
+
+// svgArc converts an elliptical arc from (x1, y1) to (x2, y2) to cubic Bézier curves of at most 90 degrees each, see
+// https://www.w3.org/TR/SVG11/implnote.html#ArcImplementationNotes
+func svgArc(x1, y1, rx, ry, angle float64, large, sweep bool, x2, y2 float64,
+	curveTo func(x1, y1, x2, y2, x3, y3 float64), lineTo func(x, y float64)) {
+	if x1 == x2 && y1 == y2 {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		lineTo(x2, y2)
+		return
+	}
+
+	// center parameterization
+	phi := angle * math.Pi / 180
+	sinPhi, cosPhi := math.Sin(phi), math.Cos(phi)
+	mx, my := (x1-x2)/2, (y1-y2)/2
+	px := cosPhi*mx + sinPhi*my
+	py := -sinPhi*mx + cosPhi*my
+
+	// scale up radii that are too small
+	if l := px*px/(rx*rx) + py*py/(ry*ry); l > 1 {
+		rx *= math.Sqrt(l)
+		ry *= math.Sqrt(l)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*py*py - ry*ry*px*px
+	den := rx*rx*py*py + ry*ry*px*px
+	coef := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		coef = -coef
+	}
+	cxp := coef * rx * py / ry
+	cyp := -coef * ry * px / rx
+	cx := cosPhi*cxp - sinPhi*cyp + (x1+x2)/2
+	cy := sinPhi*cxp + cosPhi*cyp + (y1+y2)/2
+
+	vecAngle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := vecAngle(1, 0, (px-cxp)/rx, (py-cyp)/ry)
+	delta := vecAngle((px-cxp)/rx, (py-cyp)/ry, (-px-cxp)/rx, (-py-cyp)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	// point on the ellipse and its derivative
+	point := func(t float64) (float64, float64) {
+		x, y := rx*math.Cos(t), ry*math.Sin(t)
+		return cosPhi*x - sinPhi*y + cx, sinPhi*x + cosPhi*y + cy
+	}
+	deriv := func(t float64) (float64, float64) {
+		x, y := -rx*math.Sin(t), ry*math.Cos(t)
+		return cosPhi*x - sinPhi*y, sinPhi*x + cosPhi*y
+	}
+
+	n := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+	for i := 0; i < n; i++ {
+		t1 := theta + float64(i)*step
+		t2 := t1 + step
+		ax, ay := point(t1)
+		adx, ady := deriv(t1)
+		bx, by := point(t2)
+		bdx, bdy := deriv(t2)
+		if i == n-1 {
+			bx, by = x2, y2
+		}
+		curveTo(ax+k*adx, ay+k*ady, bx-k*bdx, by-k*bdy, bx, by)
+	}
+}
@@ -0,0 +1,61 @@
+package pdf
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// equalContents compares content stream operations, numbers with the precision of the output
+func equalContents(got, want string) bool {
+	g, w := strings.Fields(got), strings.Fields(want)
+	if len(g) != len(w) {
+		return false
+	}
+	for i := range g {
+		a, errA := strconv.ParseFloat(g[i], 64)
+		b, errB := strconv.ParseFloat(w[i], 64)
+		if errA != nil || errB != nil {
+			if g[i] != w[i] {
+				return false
+			}
+		} else if math.Abs(a-b) > 0.001 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDrawSVGPath(t *testing.T) {
+	identity := [6]float64{1, 0, 0, 1, 0, 0}
+	for _, tc := range []struct {
+		data string
+		m    [6]float64
+		want string
+	}{
+		{"M10 20 L30 40 H50 V60 Z", identity, "10 20 m\n30 40 l\n50 40 l\n50 60 l\nh"},
+		{"m10,20 10,0 0,10z m5 5 h-5", identity, "10 20 m\n20 20 l\n20 30 l\nh\n15 25 m\n10 25 l"},
+		{"M0 0 Q30 30 60 0 T120 0", identity, "0 0 m\n20 20 40 20 60 0 c\n80 -20 100 -20 120 0 c"},
+		{"M0 0 C0 10 10 10 10 0 S20-10 20 0", identity, "0 0 m\n0 10 10 10 10 0 c\n10 -10 20 -10 20 0 c"},
+		{"M0 0 A10 10 0 0 1 20 0", identity, "0 0 m\n0 -5.523 4.477 -10 10 -10 c\n15.523 -10 20 -5.523 20 0 c"},
+		{"M0 0 A0 10 0 0 1 20 0", identity, "0 0 m\n20 0 l"},
+		{"M0 0 L10 0", [6]float64{2, 0, 0, -2, 5, 800}, "5 800 m\n25 800 l"},
+	} {
+		p := NewPage(0, 0)
+		if err := drawSVGPath(p, tc.data, tc.m); err != nil {
+			t.Errorf("%q: %v", tc.data, err)
+			continue
+		}
+		if got := string(bytes.Join(p.contents, []byte{'\n'})); !equalContents(got, tc.want) {
+			t.Errorf("%q: got\n%s\nwant\n%s", tc.data, got, tc.want)
+		}
+	}
+
+	for _, data := range []string{"10 10", "M10", "M0 0 X1 1", "M0 0 A10 10 0 2 1 20 0", "M0 0 L1 1 #"} {
+		if err := drawSVGPath(NewPage(0, 0), data, identity); err == nil {
+			t.Errorf("%q: no error", data)
+		}
+	}
+}
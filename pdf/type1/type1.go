@@ -0,0 +1,180 @@
+// Package type1 parses PostScript Type 1 font programs in PFB (binary) and PFA (ASCII) format and splits them into
+// the three portions required for embedding in PDF files.
+// https://adobe-type-tools.github.io/font-tech-notes/pdfs/T1_SPEC.pdf
+package type1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+)
+
+// Font is a parsed Type 1 font program
+type Font struct {
+	// FontName is the name of the font as defined in the clear-text portion
+	FontName string
+
+	// Clear is the clear-text portion up to and including the eexec operator
+	Clear []byte
+
+	// Encrypted is the binary encrypted portion
+	Encrypted []byte
+
+	// Trailer is the fixed-content portion: 512 zeros and the cleartomark operator
+	Trailer []byte
+}
+
+var errInvalid = errors.New("invalid Type 1 font program")
+
+// Parse parses a Type 1 font program in PFB or PFA format
+func Parse(data []byte) (*Font, error) {
+	var f *Font
+	var err error
+	switch {
+	case len(data) > 0 && data[0] == 0x80:
+		f, err = parsePFB(data)
+	case bytes.HasPrefix(data, []byte("%!")):
+		f, err = parsePFA(data)
+	default:
+		return nil, errors.New("font is neither in PFB nor in PFA format")
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.FontName = fontName(f.Clear)
+	if f.FontName == "" {
+		return nil, errors.New("Type 1 font program without FontName")
+	}
+	return f, nil
+}
+
+// Bytes returns the font program as embedded in PDF files: the portions in this order with binary encrypted portion
+func (q *Font) Bytes() []byte {
+	res := make([]byte, 0, len(q.Clear)+len(q.Encrypted)+len(q.Trailer))
+	res = append(res, q.Clear...)
+	res = append(res, q.Encrypted...)
+	return append(res, q.Trailer...)
+}
+
+// parsePFB parses the segments of a PFB file. Each segment starts with 0x80, the segment type (1: ASCII, 2: binary,
+// 3: end of file) and the little-endian length of the data.
+func parsePFB(data []byte) (*Font, error) {
+	var f Font
+	part := 0
+	for pos := 0; pos < len(data); {
+		if data[pos] != 0x80 || pos+2 > len(data) {
+			return nil, errInvalid
+		}
+		typ := data[pos+1]
+		if typ == 3 {
+			break
+		}
+		if pos+6 > len(data) {
+			return nil, errInvalid
+		}
+		length := int(binary.LittleEndian.Uint32(data[pos+2:]))
+		pos += 6
+		if length < 0 || pos+length > len(data) {
+			return nil, errInvalid
+		}
+		segment := data[pos : pos+length]
+		pos += length
+
+		switch {
+		case typ == 1 && part == 0:
+			f.Clear = append(f.Clear, segment...)
+		case typ == 2 && part <= 1:
+			part = 1
+			f.Encrypted = append(f.Encrypted, segment...)
+		case typ == 1 && part >= 1:
+			part = 2
+			f.Trailer = append(f.Trailer, segment...)
+		default:
+			return nil, errInvalid
+		}
+	}
+	if len(f.Clear) == 0 || len(f.Encrypted) == 0 {
+		return nil, errInvalid
+	}
+	return &f, nil
+}
+
+// parsePFA parses a PFA file: the clear-text portion ends after the eexec operator, the encrypted portion is hex
+// encoded and followed by 512 zeros and the cleartomark operator
+func parsePFA(data []byte) (*Font, error) {
+	// clear-text portion
+	pos := bytes.Index(data, []byte("eexec"))
+	if pos < 0 {
+		return nil, errInvalid
+	}
+	pos += len("eexec")
+	for pos < len(data) && isSpace(data[pos]) {
+		pos++
+	}
+
+	// trailer starts with the 512 zeros before cleartomark; further zeros belong to the encrypted portion
+	end := bytes.LastIndex(data, []byte("cleartomark"))
+	if end < pos {
+		end = len(data)
+	} else {
+		zeros := 0
+		for end > pos {
+			if c := data[end-1]; c == '0' && zeros < 512 {
+				zeros++
+			} else if !isSpace(c) {
+				break
+			}
+			end--
+		}
+	}
+
+	// decode encrypted portion
+	encrypted := make([]byte, 0, (end-pos)/2)
+	for _, c := range data[pos:end] {
+		if !isSpace(c) {
+			encrypted = append(encrypted, c)
+		}
+	}
+	if len(encrypted) == 0 {
+		return nil, errInvalid
+	}
+	bts := make([]byte, hex.DecodedLen(len(encrypted)))
+	if _, err := hex.Decode(bts, encrypted); err != nil {
+		return nil, errors.New("invalid encrypted portion of Type 1 font program: " + err.Error())
+	}
+
+	return &Font{
+		Clear:     data[:pos],
+		Encrypted: bts,
+		Trailer:   data[end:],
+	}, nil
+}
+
+// fontName returns the value of the FontName entry of the clear-text portion
+func fontName(clear []byte) string {
+	pos := bytes.Index(clear, []byte("/FontName"))
+	if pos < 0 {
+		return ""
+	}
+	s := bytes.TrimLeft(clear[pos+len("/FontName"):], " \t\r\n")
+	if len(s) == 0 || s[0] != '/' {
+		return ""
+	}
+	s = s[1:]
+	for i, c := range s {
+		if isSpace(c) || c == '/' || c == '{' || c == '[' || c == '(' {
+			return string(s[:i])
+		}
+	}
+	return string(s)
+}
+
+// isSpace checks if the byte is PostScript white space
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package type1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+const (
+	testClear   = "%!PS-AdobeFont-1.0: TestFont 001.000\n/FontName /TestFont def\ncurrentfile eexec\n"
+	testTrailer = "\ncleartomark\n"
+)
+
+var testEncrypted = []byte{0xD9, 0xD6, 0x6F, 0x63, 0x3B, 0x84, 0x6A, 0x98, 0x9B, 0x00, 0x80, 0xFF, 0x00}
+
+// pfbSegment returns a segment of a PFB file
+func pfbSegment(typ byte, data []byte) []byte {
+	res := []byte{0x80, typ, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(res[2:], uint32(len(data)))
+	return append(res, data...)
+}
+
+// testZeros returns the zeros of the trailer in lines of 64 characters
+func testZeros() string {
+	return strings.Repeat(strings.Repeat("0", 64)+"\n", 8)
+}
+
+func TestParsePFB(t *testing.T) {
+	// the encrypted portion is split into two binary segments
+	var pfb []byte
+	pfb = append(pfb, pfbSegment(1, []byte(testClear))...)
+	pfb = append(pfb, pfbSegment(2, testEncrypted[:5])...)
+	pfb = append(pfb, pfbSegment(2, testEncrypted[5:])...)
+	pfb = append(pfb, pfbSegment(1, []byte(testZeros()+testTrailer))...)
+	pfb = append(pfb, 0x80, 3)
+
+	f, err := Parse(pfb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.FontName != "TestFont" {
+		t.Errorf("got font name %q", f.FontName)
+	}
+	if string(f.Clear) != testClear || !bytes.Equal(f.Encrypted, testEncrypted) ||
+		string(f.Trailer) != testZeros()+testTrailer {
+		t.Errorf("got portions %q, %x, %q", f.Clear, f.Encrypted, f.Trailer)
+	}
+	if want := testClear + string(testEncrypted) + testZeros() + testTrailer; string(f.Bytes()) != want {
+		t.Errorf("got program %q, want %q", f.Bytes(), want)
+	}
+
+	for name, data := range map[string][]byte{
+		"truncated segment":       pfb[:len(pfbSegment(1, []byte(testClear)))+8],
+		"missing binary segment":  append(pfbSegment(1, []byte(testClear)), 0x80, 3),
+		"ASCII after trailer":     append(append(pfb[:len(pfb)-2], pfbSegment(2, testEncrypted)...), 0x80, 3),
+		"missing segment marker":  append(pfbSegment(1, []byte(testClear)), 0x00),
+		"missing font name":       append(append(pfbSegment(1, []byte("%!PS\ncurrentfile eexec\n")), pfbSegment(2, testEncrypted)...), 0x80, 3),
+		"neither PFB nor PFA":     []byte("/FontName /TestFont def"),
+		"font name without slash": append(append(pfbSegment(1, []byte("/FontName (TestFont) def\n")), pfbSegment(2, testEncrypted)...), 0x80, 3),
+	} {
+		if _, err := Parse(data); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestParsePFA(t *testing.T) {
+	encoded := strings.ToUpper(hex.EncodeToString(testEncrypted))
+	pfa := testClear + encoded[:10] + "\r\n" + encoded[10:] + "\n" + testZeros() + testTrailer
+
+	f, err := Parse([]byte(pfa))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.FontName != "TestFont" {
+		t.Errorf("got font name %q", f.FontName)
+	}
+
+	// the trailer starts with the line break before the zeros, zeros at the end of the encrypted portion are kept
+	if string(f.Clear) != testClear || !bytes.Equal(f.Encrypted, testEncrypted) ||
+		string(f.Trailer) != "\n"+testZeros()+testTrailer {
+		t.Errorf("got portions %q, %x, %q", f.Clear, f.Encrypted, f.Trailer)
+	}
+
+	if _, err := Parse([]byte(testClear + "D9D6XY" + testTrailer)); err == nil {
+		t.Error("invalid hex data: no error")
+	}
+	if _, err := Parse([]byte("%!PS-AdobeFont-1.0\n/FontName /TestFont def\n")); err == nil {
+		t.Error("missing eexec: no error")
+	}
+}
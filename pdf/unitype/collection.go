@@ -0,0 +1,231 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Font collections (ttc/otc) contain several fonts, which may share tables. The fonts are extracted to standalone
+// font files to be used like single fonts.
+// https://docs.microsoft.com/en-us/typography/opentype/spec/otff#font-collections
+
+// CollectionFace describes a font of a collection
+type CollectionFace struct {
+	Index          int
+	Family         string
+	Style          string
+	FullName       string
+	PostScriptName string
+
+	// weight class from the OS/2 table, e.g. 400 for regular and 700 for bold
+	Weight int
+
+	// italic or oblique style
+	Italic bool
+
+	// font has PostScript (CFF) outlines instead of TrueType outlines
+	PostScriptOutlines bool
+}
+
+// IsCollection checks if the data is a font collection
+func IsCollection(b []byte) bool {
+	return len(b) >= 12 && string(b[:4]) == "ttcf"
+}
+
+// ParseCollection returns the fonts contained in the collection
+func ParseCollection(b []byte) ([]CollectionFace, error) {
+	offsets, err := collectionOffsets(b)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]CollectionFace, 0, len(offsets))
+	for i := range offsets {
+		face, err := ExtractCollectionFont(b, i)
+		if err != nil {
+			return nil, err
+		}
+		cf, err := parseFace(face)
+		if err != nil {
+			return nil, err
+		}
+		cf.Index = i
+		res = append(res, cf)
+	}
+	return res, nil
+}
+
+// ParseFaces returns the description of the font, or of all fonts if the data is a font collection. WOFF files are
+// decoded first.
+func ParseFaces(b []byte) ([]CollectionFace, error) {
+	if IsWOFF(b) {
+		var err error
+		if b, err = DecodeWOFF(b); err != nil {
+			return nil, err
+		}
+	}
+	if IsCollection(b) {
+		return ParseCollection(b)
+	}
+	cf, err := parseFace(b)
+	if err != nil {
+		return nil, err
+	}
+	return []CollectionFace{cf}, nil
+}
+
+// parseFace returns the description of a single font. Only the name, OS/2 and head tables are read, not the entire
+// font, which may have PostScript outlines.
+func parseFace(b []byte) (CollectionFace, error) {
+	var err error
+	f := &font{}
+	r := newByteReader(bytes.NewReader(b))
+	if f.ot, err = f.parseOffsetTable(r); err != nil {
+		return CollectionFace{}, err
+	}
+	if f.trec, err = f.parseTableRecords(r); err != nil {
+		return CollectionFace{}, err
+	}
+	if f.name, err = f.parseNameTable(r); err != nil {
+		return CollectionFace{}, err
+	}
+	if f.os2, err = f.parseOS2Table(r); err != nil {
+		return CollectionFace{}, err
+	}
+	if f.head, err = f.parseHead(r); err != nil {
+		return CollectionFace{}, err
+	}
+
+	cf := CollectionFace{
+		Family:             f.GetNameByID(16),
+		Style:              f.GetNameByID(17),
+		FullName:           f.GetNameByID(4),
+		PostScriptName:     f.GetNameByID(6),
+		Weight:             400,
+		PostScriptOutlines: len(b) >= 4 && string(b[:4]) == "OTTO",
+	}
+	if cf.Family == "" {
+		cf.Family = f.GetNameByID(1)
+	}
+	if cf.Style == "" {
+		cf.Style = f.GetNameByID(2)
+	}
+
+	// fsSelection bits: 0 italic, 9 oblique; macStyle bits: 0 bold, 1 italic
+	switch {
+	case f.os2 != nil && f.os2.usWeightClass != 0:
+		cf.Weight = int(f.os2.usWeightClass)
+	case f.head != nil && f.head.macStyle&1 != 0:
+		cf.Weight = 700
+	}
+	if f.os2 != nil {
+		cf.Italic = f.os2.fsSelection&(1<<0|1<<9) != 0
+	} else if f.head != nil {
+		cf.Italic = f.head.macStyle&(1<<1) != 0
+	}
+	return cf, nil
+}
+
+// ExtractCollectionFont returns the font with the given index of the collection as standalone font file
+func ExtractCollectionFont(b []byte, index int) ([]byte, error) {
+	offsets, err := collectionOffsets(b)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(offsets) {
+		return nil, errors.New("font collection has no font " + strconv.Itoa(index))
+	}
+
+	// offset table and table records
+	start := int(offsets[index])
+	if start+12 > len(b) {
+		return nil, errRangeCheck
+	}
+	numTables := int(binary.BigEndian.Uint16(b[start+4:]))
+	headerSize := 12 + 16*numTables
+	if start+headerSize > len(b) {
+		return nil, errRangeCheck
+	}
+	res := make([]byte, headerSize)
+	copy(res, b[start:start+headerSize])
+
+	// copy tables, 4-byte aligned
+	for i := 0; i < numTables; i++ {
+		rec := res[12+16*i:]
+		offset := int(binary.BigEndian.Uint32(rec[8:]))
+		length := int(binary.BigEndian.Uint32(rec[12:]))
+		if offset < 0 || length < 0 || offset+length > len(b) {
+			return nil, errRangeCheck
+		}
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(res)))
+		res = append(res, b[offset:offset+length]...)
+		for len(res)%4 != 0 {
+			res = append(res, 0)
+		}
+	}
+	return res, nil
+}
+
+// ExtractCollectionFontByName returns the font of the collection with the given name as standalone font file, see
+// CollectionFontIndex.
+func ExtractCollectionFontByName(b []byte, name string) ([]byte, error) {
+	index, err := CollectionFontIndex(b, name)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractCollectionFont(b, index)
+}
+
+// CollectionFontIndex returns the index of the font of the collection with the given name. The name is compared
+// case-insensitively to the full name, the PostScript name and family and style, e.g. "Noto Sans CJK JP Bold"; a
+// family name alone matches its regular style.
+func CollectionFontIndex(b []byte, name string) (int, error) {
+	faces, err := ParseCollection(b)
+	if err != nil {
+		return 0, err
+	}
+	for _, face := range faces {
+		if face.matches(name) {
+			return face.Index, nil
+		}
+	}
+	return 0, errors.New("font collection has no font " + name)
+}
+
+// matches checks if the face has the given name
+func (q CollectionFace) matches(name string) bool {
+	names := []string{q.FullName, q.PostScriptName, q.Family + " " + q.Style}
+	if strings.EqualFold(q.Style, "Regular") {
+		names = append(names, q.Family)
+	}
+	for _, n := range names {
+		if n != "" && strings.EqualFold(strings.TrimSpace(n), strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectionOffsets returns the offsets of the fonts of the collection
+func collectionOffsets(b []byte) ([]uint32, error) {
+	if !IsCollection(b) {
+		return nil, errors.New("not a font collection")
+	}
+	numFonts := int(binary.BigEndian.Uint32(b[8:]))
+	if numFonts <= 0 || 12+4*numFonts > len(b) {
+		return nil, errRangeCheck
+	}
+	offsets := make([]uint32, numFonts)
+	for i := range offsets {
+		offsets[i] = binary.BigEndian.Uint32(b[12+4*i:])
+	}
+	return offsets, nil
+}
@@ -0,0 +1,83 @@
+package unitype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// encodeCollection combines font files to a font collection, the tables are not shared
+func encodeCollection(fonts ...[]byte) []byte {
+	res := make([]byte, 12+4*len(fonts))
+	copy(res, "ttcf")
+	binary.BigEndian.PutUint32(res[4:], 0x00010000)
+	binary.BigEndian.PutUint32(res[8:], uint32(len(fonts)))
+	for i, f := range fonts {
+		binary.BigEndian.PutUint32(res[12+4*i:], uint32(len(res)))
+		numTables := int(binary.BigEndian.Uint16(f[4:]))
+		dir := len(res)
+		res = append(res, f[:12+16*numTables]...)
+		for j := 0; j < numTables; j++ {
+			rec := res[dir+12+16*j:]
+			offset := binary.BigEndian.Uint32(rec[8:])
+			length := binary.BigEndian.Uint32(rec[12:])
+			binary.BigEndian.PutUint32(rec[8:], uint32(len(res)))
+			res = append(res, f[offset:offset+length]...)
+			for len(res)%4 != 0 {
+				res = append(res, 0)
+			}
+		}
+	}
+	return res
+}
+
+func TestCollectionFontIndex(t *testing.T) {
+	ttc := encodeCollection(goregular.TTF, gomono.TTF)
+	tests := []struct {
+		name  string
+		index int
+	}{
+		{"Go", 0},
+		{"go regular", 0},
+		{"Go Mono", 1},
+		{"GoMono", 1},
+	}
+	for _, tt := range tests {
+		index, err := CollectionFontIndex(ttc, tt.name)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if index != tt.index {
+			t.Errorf("%s: got index %d, want %d", tt.name, index, tt.index)
+		}
+	}
+	if _, err := CollectionFontIndex(ttc, "Go Bold"); err == nil {
+		t.Error("expected error for font not in collection")
+	}
+}
+
+func TestExtractCollectionFont(t *testing.T) {
+	ttc := encodeCollection(goregular.TTF, gomono.TTF)
+	for index, want := range [][]byte{goregular.TTF, gomono.TTF} {
+		b, err := ExtractCollectionFont(ttc, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, wantTables := sfntTables(t, b), sfntTables(t, want)
+		if len(got) != len(wantTables) {
+			t.Fatalf("font %d: got %d tables, want %d", index, len(got), len(wantTables))
+		}
+		for tag, table := range wantTables {
+			if !bytes.Equal(got[tag], table) {
+				t.Errorf("font %d: table %s differs", index, tag)
+			}
+		}
+	}
+	if _, err := ExtractCollectionFont(ttc, 2); err == nil {
+		t.Error("expected error for index out of range")
+	}
+}
@@ -623,9 +623,42 @@ func (f *Font) GetMetrics() Metrics {
 	return q
 }
 
+// UnitsPerEm returns the size of the em square in font units, the unit of glyph outlines
+func (f *Font) UnitsPerEm() int {
+	return int(f.head.unitsPerEm)
+}
+
 func (f *Font) GetGlyphAdvance(gid GlyphIndex) int {
 	if gid < 0 || int(gid) >= len(f.hmtx.hMetrics) {
 		return int(f.hhea.advanceWidthMax) * 1000 / int(f.head.unitsPerEm)
 	}
 	return int(f.hmtx.hMetrics[gid].advanceWidth) * 1000 / int(f.head.unitsPerEm)
 }
+
+// GetGlyphVerticalMetrics returns the vertical advance of the glyph and the distance from the vertical origin to the
+// top of the glyph in thousandths of the font size. ok is false if the font has no vertical metrics (vhea/vmtx), in
+// that case the advance is the font's height and the origin is at the ascender.
+func (f *Font) GetGlyphVerticalMetrics(gid GlyphIndex) (advance, originY int, ok bool) {
+	unitsPerEm := int(f.head.unitsPerEm)
+	adv, tsb, ok := f.vmtx.verticalMetrics(gid)
+	if !ok {
+		return (int(f.hhea.ascender) - int(f.hhea.descender)) * 1000 / unitsPerEm,
+			int(f.hhea.ascender) * 1000 / unitsPerEm, false
+	}
+
+	// the origin is the top side bearing above the top of the glyph
+	yMax := int(f.head.yMax)
+	if f.glyf != nil && int(gid) < len(f.glyf.descs) {
+		if desc := f.glyf.descs[gid]; len(desc.raw) == 0 {
+			yMax = 0
+		} else if desc.parse() == nil {
+			yMax = int(desc.header.yMax)
+		}
+	}
+	return adv * 1000 / unitsPerEm, (yMax + tsb) * 1000 / unitsPerEm, true
+}
+
+// HasVerticalMetrics checks if the font contains vertical metrics (vhea/vmtx tables)
+func (f *Font) HasVerticalMetrics() bool {
+	return f.vmtx != nil && len(f.vmtx.vMetrics) != 0
+}
@@ -54,6 +54,21 @@ type font struct {
 	os2  *os2Table
 	post *postTable
 	cmap *cmapTable
+	gdef *gdefTable
+	gsub *layoutTable
+	gpos *layoutTable
+	kern *kernTable
+	vhea *vheaTable
+	vmtx *vmtxTable
+	fvar *fvarTable
+	gvar layoutData
+	hvar layoutData
+	mvar layoutData
+	colr layoutData
+	cpal layoutData
+	sbix layoutData
+	cblc layoutData
+	cbdt layoutData
 }
 
 // Returns an error in strict mode, otherwise adds the incompatibility to a list of noted incompatibilities.
@@ -151,6 +166,52 @@ func parseFont(r *byteReader) (*font, error) {
 		return nil, err
 	}
 
+	// layout tables are only needed for shaping, invalid tables are ignored
+	f.gdef, err = f.parseGdef(r)
+	if err != nil {
+		f.gdef = nil
+	}
+	f.gsub, err = f.parseGsub(r)
+	if err != nil {
+		f.gsub = nil
+	}
+	f.gpos, err = f.parseGpos(r)
+	if err != nil {
+		f.gpos = nil
+	}
+	f.kern, err = f.parseKern(r)
+	if err != nil {
+		f.kern = nil
+	}
+
+	// vertical metrics are only needed for vertical writing, invalid tables are ignored
+	f.vhea, err = f.parseVhea(r)
+	if err != nil {
+		f.vhea = nil
+	}
+	f.vmtx, err = f.parseVmtx(r)
+	if err != nil {
+		f.vmtx = nil
+	}
+
+	// variations are only needed for instancing, invalid tables are ignored
+	f.fvar, err = f.parseFvar(r)
+	if err != nil {
+		f.fvar = nil
+	}
+	if f.fvar != nil {
+		f.gvar, _ = f.readTableData(r, "gvar")
+		f.hvar, _ = f.readTableData(r, "HVAR")
+		f.mvar, _ = f.readTableData(r, "MVAR")
+	}
+
+	// color glyphs are only needed for color fonts, invalid tables are ignored
+	f.colr, _ = f.readTableData(r, "COLR")
+	f.cpal, _ = f.readTableData(r, "CPAL")
+	f.sbix, _ = f.readTableData(r, "sbix")
+	f.cblc, _ = f.readTableData(r, "CBLC")
+	f.cbdt, _ = f.readTableData(r, "CBDT")
+
 	return f, nil
 }
 
@@ -0,0 +1,461 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// flags of points of simple glyphs
+const (
+	pointOnCurve     = 0x01
+	pointXShort      = 0x02
+	pointYShort      = 0x04
+	pointRepeat      = 0x08
+	pointXSameOrPos  = 0x10
+	pointYSameOrPos  = 0x20
+	pointOverlapping = 0x40
+)
+
+// glyphOutline is the decoded description of a glyph whose coordinates can be changed: the points of a simple
+// glyph or the offsets of the components of a composite glyph
+type glyphOutline struct {
+	simple       bool
+	endPts       []int   // last point of each contour of simple glyphs
+	flags        []uint8 // on curve and overlap flags of the points of simple glyphs
+	x, y         []float64
+	components   []outlineComponent
+	instructions []byte
+}
+
+// outlineComponent is a component of a composite glyph
+type outlineComponent struct {
+	flags     compositeGlyphFlag
+	gid       GlyphIndex
+	arg1      int
+	arg2      int
+	transform []byte
+}
+
+// decodeOutline decodes the raw data of a glyph description, nil for empty glyphs
+func decodeOutline(raw []byte) (*glyphOutline, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	d := layoutData(raw)
+	if len(d) < 10 {
+		return nil, errRangeCheck
+	}
+	numberOfContours := int(d.i16(0))
+	if numberOfContours < 0 {
+		return decodeCompositeOutline(d)
+	}
+
+	o := &glyphOutline{simple: true}
+	pos := 10
+	for i := 0; i < numberOfContours; i++ {
+		o.endPts = append(o.endPts, int(d.u16(pos)))
+		pos += 2
+	}
+	numPoints := 0
+	if numberOfContours > 0 {
+		numPoints = o.endPts[numberOfContours-1] + 1
+	}
+	instructionLength := int(d.u16(pos))
+	pos += 2
+	if pos+instructionLength > len(d) {
+		return nil, errRangeCheck
+	}
+	o.instructions = append([]byte(nil), d[pos:pos+instructionLength]...)
+	pos += instructionLength
+
+	// flags
+	flags := make([]uint8, 0, numPoints)
+	for len(flags) < numPoints {
+		if pos >= len(d) {
+			return nil, errRangeCheck
+		}
+		flag := d[pos]
+		pos++
+		flags = append(flags, flag)
+		if flag&pointRepeat != 0 {
+			if pos >= len(d) {
+				return nil, errRangeCheck
+			}
+			for n := int(d[pos]); n > 0 && len(flags) < numPoints; n-- {
+				flags = append(flags, flag)
+			}
+			pos++
+		}
+	}
+
+	// coordinates
+	readCoords := func(short, sameOrPos uint8) ([]float64, error) {
+		res := make([]float64, numPoints)
+		var v int
+		for i, flag := range flags {
+			switch {
+			case flag&short != 0:
+				if pos >= len(d) {
+					return nil, errRangeCheck
+				}
+				if flag&sameOrPos != 0 {
+					v += int(d[pos])
+				} else {
+					v -= int(d[pos])
+				}
+				pos++
+			case flag&sameOrPos == 0:
+				if pos+2 > len(d) {
+					return nil, errRangeCheck
+				}
+				v += int(d.i16(pos))
+				pos += 2
+			}
+			res[i] = float64(v)
+		}
+		return res, nil
+	}
+	var err error
+	if o.x, err = readCoords(pointXShort, pointXSameOrPos); err != nil {
+		return nil, err
+	}
+	if o.y, err = readCoords(pointYShort, pointYSameOrPos); err != nil {
+		return nil, err
+	}
+	for _, flag := range flags {
+		o.flags = append(o.flags, flag&(pointOnCurve|pointOverlapping))
+	}
+	return o, nil
+}
+
+// decodeCompositeOutline decodes the components of a composite glyph
+func decodeCompositeOutline(d layoutData) (*glyphOutline, error) {
+	o := &glyphOutline{}
+	pos := 10
+	var instructions bool
+	for {
+		if pos+4 > len(d) {
+			return nil, errRangeCheck
+		}
+		c := outlineComponent{flags: compositeGlyphFlag(d.u16(pos)), gid: GlyphIndex(d.u16(pos + 2))}
+		pos += 4
+		switch {
+		case c.flags.IsSet(arg1And2AreWords) && c.flags.IsSet(argsAreXYValues):
+			c.arg1, c.arg2 = int(d.i16(pos)), int(d.i16(pos+2))
+			pos += 4
+		case c.flags.IsSet(arg1And2AreWords):
+			c.arg1, c.arg2 = int(d.u16(pos)), int(d.u16(pos+2))
+			pos += 4
+		case c.flags.IsSet(argsAreXYValues):
+			if pos+2 > len(d) {
+				return nil, errRangeCheck
+			}
+			c.arg1, c.arg2 = int(int8(d[pos])), int(int8(d[pos+1]))
+			pos += 2
+		default:
+			if pos+2 > len(d) {
+				return nil, errRangeCheck
+			}
+			c.arg1, c.arg2 = int(d[pos]), int(d[pos+1])
+			pos += 2
+		}
+
+		var n int
+		switch {
+		case c.flags.IsSet(weHaveAScale):
+			n = 2
+		case c.flags.IsSet(weHaveAnXAndYScale):
+			n = 4
+		case c.flags.IsSet(weHaveATwoByTwo):
+			n = 8
+		}
+		if pos+n > len(d) {
+			return nil, errRangeCheck
+		}
+		c.transform = append([]byte(nil), d[pos:pos+n]...)
+		pos += n
+
+		instructions = instructions || c.flags.IsSet(weHaveInstructions)
+		o.components = append(o.components, c)
+		o.x = append(o.x, float64(c.arg1))
+		o.y = append(o.y, float64(c.arg2))
+		if !c.flags.IsSet(moreComponents) {
+			break
+		}
+	}
+	if instructions {
+		n := int(d.u16(pos))
+		if pos+2+n > len(d) {
+			return nil, errRangeCheck
+		}
+		o.instructions = append([]byte(nil), d[pos+2:pos+2+n]...)
+	}
+	return o, nil
+}
+
+// numPoints returns the number of points of the glyph, excluding phantom points
+func (o *glyphOutline) numPoints() int {
+	return len(o.x)
+}
+
+// encode returns the raw glyph description with the bounding box given, padded to 4 bytes
+func (o *glyphOutline) encode(xMin, yMin, xMax, yMax int) []byte {
+	res := make([]byte, 10)
+	numberOfContours := -1
+	if o.simple {
+		numberOfContours = len(o.endPts)
+	}
+	binary.BigEndian.PutUint16(res[0:], uint16(int16(numberOfContours)))
+	binary.BigEndian.PutUint16(res[2:], uint16(int16(xMin)))
+	binary.BigEndian.PutUint16(res[4:], uint16(int16(yMin)))
+	binary.BigEndian.PutUint16(res[6:], uint16(int16(xMax)))
+	binary.BigEndian.PutUint16(res[8:], uint16(int16(yMax)))
+	if o.simple {
+		res = o.encodeSimple(res)
+	} else {
+		res = o.encodeComposite(res)
+	}
+	for len(res)%4 != 0 {
+		res = append(res, 0)
+	}
+	return res
+}
+
+// encodeSimple appends contours, instructions, flags and coordinates of a simple glyph
+func (o *glyphOutline) encodeSimple(res []byte) []byte {
+	for _, e := range o.endPts {
+		res = append(res, byte(e>>8), byte(e))
+	}
+	res = append(res, byte(len(o.instructions)>>8), byte(len(o.instructions)))
+	res = append(res, o.instructions...)
+
+	// flags and coordinates as deltas
+	flags := make([]uint8, len(o.x))
+	var xs, ys []byte
+	var px, py int
+	for i := range o.x {
+		flags[i] = o.flags[i]
+		x, y := roundInt(o.x[i]), roundInt(o.y[i])
+		xs = appendCoord(xs, x-px, &flags[i], pointXShort, pointXSameOrPos)
+		ys = appendCoord(ys, y-py, &flags[i], pointYShort, pointYSameOrPos)
+		px, py = x, y
+	}
+	for i := 0; i < len(flags); {
+		n := 1
+		for i+n < len(flags) && flags[i+n] == flags[i] && n < 256 {
+			n++
+		}
+		if n > 1 {
+			res = append(res, flags[i]|pointRepeat, byte(n-1))
+		} else {
+			res = append(res, flags[i])
+		}
+		i += n
+	}
+	res = append(res, xs...)
+	return append(res, ys...)
+}
+
+// appendCoord appends a coordinate delta in the shortest form and sets the flags
+func appendCoord(b []byte, v int, flag *uint8, short, sameOrPos uint8) []byte {
+	switch {
+	case v == 0:
+		*flag |= sameOrPos
+		return b
+	case v > 0 && v < 256:
+		*flag |= short | sameOrPos
+		return append(b, byte(v))
+	case v < 0 && v > -256:
+		*flag |= short
+		return append(b, byte(-v))
+	}
+	return append(b, byte(uint16(int16(v))>>8), byte(uint16(int16(v))))
+}
+
+// encodeComposite appends the components of a composite glyph
+func (o *glyphOutline) encodeComposite(res []byte) []byte {
+	for i, c := range o.components {
+		flags := c.flags
+		arg1, arg2 := c.arg1, c.arg2
+		if flags.IsSet(argsAreXYValues) {
+			arg1, arg2 = roundInt(o.x[i]), roundInt(o.y[i])
+			if arg1 < -128 || arg1 > 127 || arg2 < -128 || arg2 > 127 {
+				flags |= arg1And2AreWords
+			}
+		}
+		res = append(res, byte(flags>>8), byte(flags), byte(c.gid>>8), byte(c.gid))
+		if flags.IsSet(arg1And2AreWords) {
+			res = append(res, byte(arg1>>8), byte(arg1), byte(arg2>>8), byte(arg2))
+		} else {
+			res = append(res, byte(arg1), byte(arg2))
+		}
+		res = append(res, c.transform...)
+	}
+	if len(o.instructions) != 0 {
+		res = append(res, byte(len(o.instructions)>>8), byte(len(o.instructions)))
+		res = append(res, o.instructions...)
+	}
+	return res
+}
+
+// bounds returns the bounding box of the points of a simple glyph
+func (o *glyphOutline) bounds() (int, int, int, int) {
+	if len(o.x) == 0 {
+		return 0, 0, 0, 0
+	}
+	xMin, yMin, xMax, yMax := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for i := range o.x {
+		xMin = math.Min(xMin, o.x[i])
+		yMin = math.Min(yMin, o.y[i])
+		xMax = math.Max(xMax, o.x[i])
+		yMax = math.Max(yMax, o.y[i])
+	}
+	return roundInt(xMin), roundInt(yMin), roundInt(xMax), roundInt(yMax)
+}
+
+// roundInt rounds to the nearest integer
+func roundInt(v float64) int {
+	return int(math.Floor(v + 0.5))
+}
+
+// PathOp is the operation of a segment of a glyph outline
+type PathOp int
+
+const (
+	PathMoveTo PathOp = iota // start of a contour at Points[0]
+	PathLineTo               // line to Points[0]
+	PathQuadTo               // quadratic Bézier curve with control point Points[0] to Points[1]
+	PathClose                // end of the contour
+	PathCubeTo               // cubic Bézier curve with control points Points[0] and Points[1] to Points[2], CFF outlines
+)
+
+// OutlinePoint is a point of a glyph outline in font units
+type OutlinePoint struct {
+	X, Y float64
+}
+
+// PathSegment is a segment of a glyph outline
+type PathSegment struct {
+	Op     PathOp
+	Points []OutlinePoint
+}
+
+// GlyphOutline returns the outline of the glyph in font units. Components of composite glyphs are resolved. Nil is
+// returned for empty glyphs and fonts without TrueType outlines.
+func (f *Font) GlyphOutline(gid GlyphIndex) ([]PathSegment, error) {
+	return f.glyphOutline(gid, 0)
+}
+
+func (f *Font) glyphOutline(gid GlyphIndex, depth int) ([]PathSegment, error) {
+	if f.glyf == nil || int(gid) >= len(f.glyf.descs) || depth > 10 {
+		return nil, nil
+	}
+	o, err := decodeOutline(f.glyf.descs[gid].raw)
+	if err != nil || o == nil {
+		return nil, err
+	}
+	if o.simple {
+		return o.contours(), nil
+	}
+
+	var res []PathSegment
+	for i, c := range o.components {
+		segments, err := f.glyphOutline(c.gid, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		// transformation: x' = a*x + c*y + dx, y' = b*x + d*y + dy
+		a, b, cc, d := 1.0, 0.0, 0.0, 1.0
+		t := layoutData(c.transform)
+		switch len(t) {
+		case 2:
+			a = f2dot14(t.i16(0)).Float64()
+			d = a
+		case 4:
+			a, d = f2dot14(t.i16(0)).Float64(), f2dot14(t.i16(2)).Float64()
+		case 8:
+			a, b = f2dot14(t.i16(0)).Float64(), f2dot14(t.i16(2)).Float64()
+			cc, d = f2dot14(t.i16(4)).Float64(), f2dot14(t.i16(6)).Float64()
+		}
+		var dx, dy float64
+		if c.flags.IsSet(argsAreXYValues) {
+			dx, dy = o.x[i], o.y[i]
+		}
+		for _, s := range segments {
+			points := make([]OutlinePoint, len(s.Points))
+			for j, p := range s.Points {
+				points[j] = OutlinePoint{X: a*p.X + cc*p.Y + dx, Y: b*p.X + d*p.Y + dy}
+			}
+			res = append(res, PathSegment{Op: s.Op, Points: points})
+		}
+	}
+	return res, nil
+}
+
+// contours returns the path segments of the contours of a simple glyph. Between two off curve points, an on curve
+// point in the middle is implied.
+func (o *glyphOutline) contours() []PathSegment {
+	var res []PathSegment
+	first := 0
+	for _, last := range o.endPts {
+		if last >= len(o.x) || last < first {
+			break
+		}
+		n := last - first + 1
+		point := func(i int) OutlinePoint {
+			i = first + (i+n)%n
+			return OutlinePoint{X: o.x[i], Y: o.y[i]}
+		}
+		onCurve := func(i int) bool {
+			return o.flags[first+(i+n)%n]&pointOnCurve != 0
+		}
+		mid := func(p1, p2 OutlinePoint) OutlinePoint {
+			return OutlinePoint{X: (p1.X + p2.X) / 2, Y: (p1.Y + p2.Y) / 2}
+		}
+
+		// start at an on curve point, or between two off curve points
+		from, to := 1, n
+		var startPoint OutlinePoint
+		switch {
+		case onCurve(0):
+			startPoint = point(0)
+		case onCurve(n - 1):
+			startPoint = point(n - 1)
+			from, to = 0, n-1
+		default:
+			startPoint = mid(point(n-1), point(0))
+			from = 0
+		}
+		res = append(res, PathSegment{Op: PathMoveTo, Points: []OutlinePoint{startPoint}})
+
+		var control *OutlinePoint
+		for i := from; i <= to; i++ {
+			closing := i == to
+			p := startPoint
+			if !closing {
+				p = point(i)
+			}
+			switch {
+			case !closing && !onCurve(i) && control != nil:
+				res = append(res, PathSegment{Op: PathQuadTo, Points: []OutlinePoint{*control, mid(*control, p)}})
+				control = &p
+			case !closing && !onCurve(i):
+				control = &p
+			case control != nil:
+				res = append(res, PathSegment{Op: PathQuadTo, Points: []OutlinePoint{*control, p}})
+				control = nil
+			default:
+				res = append(res, PathSegment{Op: PathLineTo, Points: []OutlinePoint{p}})
+			}
+		}
+		res = append(res, PathSegment{Op: PathClose})
+		first = last + 1
+	}
+	return res
+}
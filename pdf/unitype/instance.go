@@ -0,0 +1,334 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+import (
+	"errors"
+	"math"
+)
+
+// IsVariable checks if the font is a variable font with design axes (fvar table)
+func (f *Font) IsVariable() bool {
+	return f.fvar != nil && len(f.fvar.axes) != 0
+}
+
+// VariationAxes returns the design axes of a variable font, nil for static fonts
+func (f *Font) VariationAxes() []VariationAxis {
+	if f.fvar == nil {
+		return nil
+	}
+	res := make([]VariationAxis, 0, len(f.fvar.axes))
+	for _, axis := range f.fvar.axes {
+		if axis.Name == "" {
+			axis.Name = f.GetNameByID(int(axis.nameID))
+		}
+		res = append(res, axis)
+	}
+	return res
+}
+
+// instancedGlyph is a glyph of an instance of a variable font
+type instancedGlyph struct {
+	outline  *glyphOutline
+	advance  float64
+	box      [4]int
+	origBox  [4]int
+	resolved bool
+}
+
+// Instance creates a static font from a variable font with the given user coordinates of the axes by tag, e.g.
+// {"wght": 700, "wdth": 75}. Axes not given keep their default value, values outside of the range of an axis are
+// clamped. The glyph outlines (gvar) and metrics (HVAR, MVAR) are varied, the variation tables are not written.
+// Only fonts with TrueType outlines are supported.
+func (f *Font) Instance(coords map[string]float64) (*Font, error) {
+	if !f.IsVariable() {
+		return nil, errors.New("font is not a variable font")
+	}
+	if f.glyf == nil || f.loca == nil || f.hmtx == nil {
+		return nil, errors.New("variable font without TrueType outlines not supported")
+	}
+	norm := f.fvar.normalize(coords)
+
+	// vary outlines and advance widths
+	glyphs := make([]*instancedGlyph, len(f.glyf.descs))
+	for i, desc := range f.glyf.descs {
+		gid := GlyphIndex(i)
+		o, err := decodeOutline(desc.raw)
+		if err != nil {
+			return nil, err
+		}
+		advance, lsb := f.horizontalMetrics(gid)
+		g := &instancedGlyph{outline: o, advance: float64(advance)}
+		glyphs[i] = g
+
+		var x, y []float64
+		var endPts []int
+		var xMin float64
+		if o != nil {
+			d := layoutData(desc.raw)
+			g.origBox = [4]int{int(d.i16(2)), int(d.i16(4)), int(d.i16(6)), int(d.i16(8))}
+			xMin = float64(g.origBox[0])
+			x, y, endPts = append(x, o.x...), append(y, o.y...), o.endPts
+		}
+
+		// phantom points: origin, advance, top and bottom
+		origin := xMin - float64(lsb)
+		x = append(x, origin, origin+float64(advance), 0, 0)
+		y = append(y, 0, 0, 0, 0)
+		dx, dy := glyphDeltas(f.gvar, gid, x, y, endPts, norm)
+		if dx != nil {
+			n := len(x) - 4
+			shift := dx[n]
+			g.advance += dx[n+1] - shift
+			if o != nil {
+				for p := 0; p < n; p++ {
+					o.x[p] += dx[p] - shift
+					o.y[p] += dy[p]
+				}
+			}
+		}
+		if f.hvar != nil {
+			g.advance = float64(advance) + advanceDelta(f.hvar, gid, norm)
+		}
+	}
+
+	newfnt := f.font.copyStatic()
+
+	// glyph data, metrics and locations
+	newfnt.glyf = &glyfTable{}
+	newfnt.hmtx = &hmtxTable{}
+	newfnt.loca = &locaTable{offsetsLong: make([]offset32, 1, len(glyphs)+1)}
+	newfnt.head.indexToLocFormat = 1
+	newfnt.hhea.numberOfHMetrics = uint16(len(glyphs))
+	bbox := [4]int{math.MaxInt16, math.MaxInt16, math.MinInt16, math.MinInt16}
+	var advanceWidthMax, minLSB, minRSB, xMaxExtent int
+	first := true
+	for i, g := range glyphs {
+		desc := &glyphDescription{}
+		advance := roundInt(g.advance)
+		if advance < 0 {
+			advance = 0
+		}
+		lsb := 0
+		if g.outline != nil {
+			box := instanceBounds(glyphs, GlyphIndex(i), 0)
+			desc.raw = g.outline.encode(box[0], box[1], box[2], box[3])
+			lsb = box[0]
+			if len(g.outline.x) != 0 {
+				bbox = [4]int{minInt(bbox[0], box[0]), minInt(bbox[1], box[1]), maxInt(bbox[2], box[2]),
+					maxInt(bbox[3], box[3])}
+				if first || lsb < minLSB {
+					minLSB = lsb
+				}
+				if first || advance-box[2] < minRSB {
+					minRSB = advance - box[2]
+				}
+				if first || box[2] > xMaxExtent {
+					xMaxExtent = box[2]
+				}
+				first = false
+			}
+		}
+		if advance > advanceWidthMax {
+			advanceWidthMax = advance
+		}
+		newfnt.glyf.descs = append(newfnt.glyf.descs, desc)
+		newfnt.hmtx.hMetrics = append(newfnt.hmtx.hMetrics, longHorMetric{advanceWidth: uint16(advance),
+			lsb: int16(lsb)})
+		newfnt.loca.offsetsLong = append(newfnt.loca.offsetsLong, newfnt.loca.offsetsLong[i]+offset32(len(desc.raw)))
+	}
+	if !first {
+		newfnt.head.xMin, newfnt.head.yMin = int16(bbox[0]), int16(bbox[1])
+		newfnt.head.xMax, newfnt.head.yMax = int16(bbox[2]), int16(bbox[3])
+		newfnt.hhea.minLeftSideBearing = fword(minLSB)
+		newfnt.hhea.minRightSideBearing = fword(minRSB)
+		newfnt.hhea.xMaxExtent = fword(xMaxExtent)
+	}
+	newfnt.hhea.advanceWidthMax = ufword(advanceWidthMax)
+	newfnt.optimizeHmtx()
+
+	newfnt.applyMetricsDeltas(metricsDeltas(f.mvar, norm))
+	newfnt.applyAxisValues(f.fvar, coords)
+	return &Font{font: newfnt}, nil
+}
+
+// copyStatic returns a copy of the font without variation tables. The tables that are changed by instancing (head,
+// hhea, os2, post) are copied, the others are shared.
+func (f *font) copyStatic() *font {
+	newfnt := &font{
+		ot:   f.ot,
+		trec: f.trec,
+		maxp: f.maxp,
+		cvt:  f.cvt,
+		fpgm: f.fpgm,
+		prep: f.prep,
+		name: f.name,
+		cmap: f.cmap,
+		gdef: f.gdef,
+		gsub: f.gsub,
+		gpos: f.gpos,
+		kern: f.kern,
+		vhea: f.vhea,
+		vmtx: f.vmtx,
+	}
+	if f.head != nil {
+		newfnt.head = &headTable{}
+		*newfnt.head = *f.head
+	}
+	if f.hhea != nil {
+		newfnt.hhea = &hheaTable{}
+		*newfnt.hhea = *f.hhea
+	}
+	if f.os2 != nil {
+		newfnt.os2 = &os2Table{}
+		*newfnt.os2 = *f.os2
+	}
+	if f.post != nil {
+		newfnt.post = &postTable{}
+		*newfnt.post = *f.post
+	}
+	return newfnt
+}
+
+// horizontalMetrics returns the advance width and left side bearing of the glyph from the hmtx table
+func (f *font) horizontalMetrics(gid GlyphIndex) (uint16, int16) {
+	hm := f.hmtx.hMetrics
+	switch {
+	case int(gid) < len(hm):
+		return hm[gid].advanceWidth, hm[gid].lsb
+	case len(hm) == 0:
+		return 0, 0
+	case int(gid)-len(hm) < len(f.hmtx.leftSideBearings):
+		return hm[len(hm)-1].advanceWidth, f.hmtx.leftSideBearings[int(gid)-len(hm)]
+	}
+	return hm[len(hm)-1].advanceWidth, 0
+}
+
+// instanceBounds returns the bounding box of an instanced glyph. The box of a composite glyph is computed from its
+// components if they are only moved, otherwise the original box is kept.
+func instanceBounds(glyphs []*instancedGlyph, gid GlyphIndex, depth int) [4]int {
+	if int(gid) >= len(glyphs) || glyphs[gid].outline == nil {
+		return [4]int{}
+	}
+	g := glyphs[gid]
+	if g.resolved {
+		return g.box
+	}
+	o := g.outline
+	g.resolved = true
+	if o.simple {
+		g.box[0], g.box[1], g.box[2], g.box[3] = o.bounds()
+		return g.box
+	}
+
+	g.box = g.origBox
+	if depth > 10 {
+		return g.box
+	}
+	box := [4]int{math.MaxInt16, math.MaxInt16, math.MinInt16, math.MinInt16}
+	for i, c := range o.components {
+		if !c.flags.IsSet(argsAreXYValues) || len(c.transform) != 0 {
+			return g.box
+		}
+		cb := instanceBounds(glyphs, c.gid, depth+1)
+		if cb == ([4]int{}) {
+			continue
+		}
+		dx, dy := roundInt(o.x[i]), roundInt(o.y[i])
+		box = [4]int{minInt(box[0], cb[0]+dx), minInt(box[1], cb[1]+dy), maxInt(box[2], cb[2]+dx),
+			maxInt(box[3], cb[3]+dy)}
+	}
+	if box[0] <= box[2] {
+		g.box = box
+	}
+	return g.box
+}
+
+// applyMetricsDeltas applies the deltas of the font wide metrics by MVAR value tag
+func (f *font) applyMetricsDeltas(deltas map[string]float64) {
+	add := func(v int16, tag string) int16 {
+		return int16(int(v) + roundInt(deltas[tag]))
+	}
+	if f.hhea != nil {
+		f.hhea.ascender = fword(add(int16(f.hhea.ascender), "hasc"))
+		f.hhea.descender = fword(add(int16(f.hhea.descender), "hdsc"))
+		f.hhea.lineGap = fword(add(int16(f.hhea.lineGap), "hlgp"))
+		f.hhea.caretSlopeRise = add(f.hhea.caretSlopeRise, "hcrs")
+		f.hhea.caretSlopeRun = add(f.hhea.caretSlopeRun, "hcrn")
+		f.hhea.caretOffset = add(f.hhea.caretOffset, "hcof")
+	}
+	if f.os2 != nil {
+		f.os2.sTypoAscender = add(f.os2.sTypoAscender, "hasc")
+		f.os2.sTypoDescender = add(f.os2.sTypoDescender, "hdsc")
+		f.os2.sTypoLineGap = add(f.os2.sTypoLineGap, "hlgp")
+		f.os2.usWinAscent = uint16(add(int16(f.os2.usWinAscent), "hcla"))
+		f.os2.usWinDescent = uint16(add(int16(f.os2.usWinDescent), "hcld"))
+		f.os2.sxHeight = add(f.os2.sxHeight, "xhgt")
+		f.os2.sCapHeight = add(f.os2.sCapHeight, "cpht")
+		f.os2.ySubscriptXSize = add(f.os2.ySubscriptXSize, "sbxs")
+		f.os2.ySubscriptYSize = add(f.os2.ySubscriptYSize, "sbys")
+		f.os2.ySubscriptXOffset = add(f.os2.ySubscriptXOffset, "sbxo")
+		f.os2.ySubscriptYOffset = add(f.os2.ySubscriptYOffset, "sbyo")
+		f.os2.ySuperscriptXSize = add(f.os2.ySuperscriptXSize, "spxs")
+		f.os2.ySuperscriptYSize = add(f.os2.ySuperscriptYSize, "spys")
+		f.os2.ySuperscriptXOffset = add(f.os2.ySuperscriptXOffset, "spxo")
+		f.os2.ySuperscriptYOffset = add(f.os2.ySuperscriptYOffset, "spyo")
+		f.os2.yStrikeoutSize = add(f.os2.yStrikeoutSize, "strs")
+		f.os2.yStrikeoutPosition = add(f.os2.yStrikeoutPosition, "stro")
+	}
+	if f.post != nil {
+		f.post.underlinePosition = fword(add(int16(f.post.underlinePosition), "undo"))
+		f.post.underlineThickness = fword(add(int16(f.post.underlineThickness), "unds"))
+	}
+}
+
+// widthClasses are the widths in percent of the OS/2 width classes 1 to 9
+var widthClasses = []float64{50, 62.5, 75, 87.5, 100, 112.5, 125, 150, 200}
+
+// applyAxisValues sets weight class, width class and italic angle from the values of the registered axes
+func (f *font) applyAxisValues(fvar *fvarTable, coords map[string]float64) {
+	for _, axis := range fvar.axes {
+		v, ok := coords[axis.Tag]
+		if !ok {
+			v = axis.Default
+		}
+		v = math.Max(axis.Min, math.Min(axis.Max, v))
+		switch axis.Tag {
+		case "wght":
+			if f.os2 != nil {
+				f.os2.usWeightClass = uint16(math.Max(1, math.Min(1000, math.Round(v))))
+			}
+		case "wdth":
+			if f.os2 != nil {
+				class := 0
+				for i, w := range widthClasses {
+					if math.Abs(w-v) < math.Abs(widthClasses[class]-v) {
+						class = i
+					}
+				}
+				f.os2.usWidthClass = uint16(class + 1)
+			}
+		case "slnt":
+			if f.post != nil {
+				f.post.italicAngle = fixed(math.Round(v * 65536))
+			}
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,157 @@
+package unitype
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// fvarTableData returns an 'fvar' table with a weight axis from 100 to 900 and the default 400
+func fvarTableData() []byte {
+	return tableData(
+		1, 0, 16, 2, 1, 20, 0, 8, // header
+		'w'<<8|'g', 'h'<<8|'t', 100, 0, 400, 0, 900, 0, 0, 256, // axis
+	)
+}
+
+// packWordDeltas returns the deltas packed as runs of 16 bit values
+func packWordDeltas(values []int) []byte {
+	var res []byte
+	for len(values) != 0 {
+		n := len(values)
+		if n > 64 {
+			n = 64
+		}
+		res = append(res, byte(0x40|(n-1)))
+		res = append(res, tableData(values[:n]...)...)
+		values = values[n:]
+	}
+	return res
+}
+
+// gvarTableData returns a 'gvar' table with one tuple at the maximum of the axis that moves all points of the glyph,
+// including the phantom points, by the deltas
+func gvarTableData(gid GlyphIndex, dx, dy []int) []byte {
+	serialized := append(packWordDeltas(dx), packWordDeltas(dy)...)
+	glyphData := append(tableData(1, 10, len(serialized), embeddedPeakTuple, 0x4000), serialized...)
+	if len(glyphData)%2 != 0 {
+		glyphData = append(glyphData, 0)
+	}
+
+	offsets := make([]int, int(gid)+2)
+	offsets[gid+1] = len(glyphData) / 2
+	header := []int{1, 0, 1, 0, 0, 0, int(gid) + 1, 0, 0, 20 + 2*len(offsets)}
+	return append(tableData(append(header, offsets...)...), glyphData...)
+}
+
+// hvarTableData returns an 'HVAR' table with the advance width deltas of the glyphs at the maximum of the axis
+func hvarTableData(deltas []int) []byte {
+	return tableData(append([]int{
+		1, 0, 0, 20, 0, 0, 0, 0, 0, 0, // header without delta set index maps
+		1, 0, 12, 1, 0, 22, // item variation store
+		1, 1, 0, 0x4000, 0x4000, // region list
+		len(deltas), 1, 1, 0, // item variation data
+	}, deltas...)...)
+}
+
+// advanceWidth returns the advance width of the glyph in font units
+func advanceWidth(f *Font, gid GlyphIndex) int {
+	advance, _ := f.horizontalMetrics(gid)
+	return int(advance)
+}
+
+func TestInstance(t *testing.T) {
+	plain, err := Parse(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gid := plain.LookupRunes([]rune("l"))[0]
+	o, err := decodeOutline(plain.glyf.descs[gid].raw)
+	if err != nil || o == nil || !o.simple {
+		t.Fatalf("no simple outline of glyph %d: %v", gid, err)
+	}
+	numPoints := len(o.x)
+	advance := advanceWidth(plain, gid)
+	outline, err := plain.GlyphOutline(gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the points move up by 20, the advance width grows by 40
+	dx, dy := make([]int, numPoints+4), make([]int, numPoints+4)
+	for i := 0; i < numPoints; i++ {
+		dy[i] = 20
+	}
+	dx[numPoints+1] = 40
+	ttf := addTable(t, goregular.TTF, "fvar", fvarTableData())
+	ttf = addTable(t, ttf, "gvar", gvarTableData(gid, dx, dy))
+
+	variable, err := Parse(bytes.NewReader(ttf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !variable.IsVariable() || len(variable.VariationAxes()) != 1 || variable.VariationAxes()[0].Tag != "wght" {
+		t.Fatalf("axes = %v", variable.VariationAxes())
+	}
+
+	for _, tc := range []struct {
+		weight float64
+		delta  float64
+	}{
+		{400, 0},
+		{100, 0},
+		{650, 0.5},
+		{900, 1},
+		{1000, 1},
+	} {
+		inst, err := variable.Instance(map[string]float64{"wght": tc.weight})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inst.IsVariable() {
+			t.Errorf("weight %v: instance is variable", tc.weight)
+		}
+		if got, want := advanceWidth(inst, gid), advance+int(40*tc.delta); got != want {
+			t.Errorf("weight %v: advance = %d, want %d", tc.weight, got, want)
+		}
+		got, err := inst.GlyphOutline(gid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(outline) {
+			t.Fatalf("weight %v: %d segments, want %d", tc.weight, len(got), len(outline))
+		}
+		for i, seg := range outline {
+			for j, p := range seg.Points {
+				if q := got[i].Points[j]; q.X != p.X || q.Y != p.Y+20*tc.delta {
+					t.Errorf("weight %v: point %v, want %v", tc.weight, q, OutlinePoint{p.X, p.Y + 20*tc.delta})
+				}
+			}
+		}
+	}
+
+	// HVAR takes precedence over the phantom points
+	hdeltas := make([]int, plain.maxp.numGlyphs)
+	hdeltas[gid] = -100
+	ttf = addTable(t, ttf, "HVAR", hvarTableData(hdeltas))
+	variable, err = Parse(bytes.NewReader(ttf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst, err := variable.Instance(map[string]float64{"wght": 900})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := advanceWidth(inst, gid); got != advance-100 {
+		t.Errorf("advance with HVAR = %d, want %d", got, advance-100)
+	}
+	if other := gid + 1; advanceWidth(inst, other) != advanceWidth(plain, other) {
+		t.Errorf("advance of glyph %d = %d, want %d", other, advanceWidth(inst, other), advanceWidth(plain, other))
+	}
+
+	// static fonts cannot be instanced
+	if _, err := plain.Instance(map[string]float64{"wght": 700}); err == nil {
+		t.Error("no error for static font")
+	}
+}
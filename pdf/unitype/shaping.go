@@ -0,0 +1,128 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+import "unicode"
+
+// ShapedGlyph is a glyph of a shaped text. Advance and offsets are given in thousandths of the font size, like
+// the values returned by GetGlyphAdvance.
+type ShapedGlyph struct {
+	Index GlyphIndex
+
+	// characters represented by the glyph: several for ligatures, none for additional glyphs of multiple
+	// substitutions
+	Runes []rune
+
+	// advance including positioning adjustments
+	Advance int
+
+	// offset of the glyph from its regular position
+	XOffset, YOffset int
+}
+
+// Features returns the tags of the OpenType features defined in the GSUB and GPOS tables of the font
+func (f *Font) Features() []string {
+	unique := make(map[string]struct{})
+	var res []string
+	for _, t := range []*layoutTable{f.gsub, f.gpos} {
+		for _, tag := range t.featureTags() {
+			if _, ok := unique[tag]; ok {
+				continue
+			}
+			unique[tag] = struct{}{}
+			res = append(res, tag)
+		}
+	}
+	return res
+}
+
+// Shape converts the runes to glyphs and applies the given OpenType features, for example "liga" (ligatures),
+// "kern" (kerning), "tnum" (tabular numbers) or "smcp" (small capitals). The script is detected from the runes;
+// the lookups of the default language system are used. Kerning falls back to the 'kern' table if GPOS defines none.
+//
+// Supported are single, multiple, alternate and ligature substitutions and single and pair positioning, e.g.
+// ligatures, kerning and glyph variants. The lookup flags for skipping base glyphs, ligatures and marks are applied
+// using the glyph classes of the GDEF table. Contextual substitutions and positioning, mark attachment and cursive
+// attachment are not supported, so the result is not suitable for scripts requiring complex shaping.
+func (f *Font) Shape(runes []rune, features ...string) []ShapedGlyph {
+	// initial glyphs from cmap
+	indices := f.LookupRunes(runes)
+	glyphs := make([]ShapedGlyph, len(runes))
+	for i, r := range runes {
+		glyphs[i] = ShapedGlyph{Index: indices[i], Runes: []rune{r}}
+	}
+
+	featureSet := make(map[string]struct{}, len(features))
+	for _, feature := range features {
+		featureSet[feature] = struct{}{}
+	}
+	script := detectScript(runes)
+
+	// substitutions
+	for _, li := range f.gsub.selectLookups(script, featureSet) {
+		glyphs = f.gsub.applyGsubLookup(f.gsub.lookups[li], glyphs, f.gdef)
+	}
+
+	// positioning
+	adj := make([]valueRecord, len(glyphs))
+	for _, li := range f.gpos.selectLookups(script, featureSet) {
+		f.gpos.applyGposLookup(f.gpos.lookups[li], glyphs, adj, f.gdef)
+	}
+
+	// convert to thousandths of the font size
+	unitsPerEm := 1000
+	if f.head != nil && f.head.unitsPerEm != 0 {
+		unitsPerEm = int(f.head.unitsPerEm)
+	}
+
+	// fonts without kerning in GPOS may have a 'kern' table
+	_, kerning := featureSet["kern"]
+	kerning = kerning && f.kern != nil && len(f.gpos.selectLookups(script, map[string]struct{}{"kern": {}})) == 0
+
+	for i := range glyphs {
+		if kerning && i+1 < len(glyphs) {
+			adj[i].xAdvance += int(f.kern.pairs[uint32(glyphs[i].Index)<<16|uint32(glyphs[i+1].Index)])
+		}
+		glyphs[i].Advance = f.GetGlyphAdvance(glyphs[i].Index) + adj[i].xAdvance*1000/unitsPerEm
+		glyphs[i].XOffset = adj[i].xPlacement * 1000 / unitsPerEm
+		glyphs[i].YOffset = adj[i].yPlacement * 1000 / unitsPerEm
+	}
+	return glyphs
+}
+
+// scriptTags maps unicode scripts to OpenType script tags
+var scriptTags = []struct {
+	table *unicode.RangeTable
+	tag   string
+}{
+	{unicode.Latin, "latn"},
+	{unicode.Greek, "grek"},
+	{unicode.Cyrillic, "cyrl"},
+	{unicode.Arabic, "arab"},
+	{unicode.Hebrew, "hebr"},
+	{unicode.Thai, "thai"},
+	{unicode.Devanagari, "dev2"},
+	{unicode.Han, "hani"},
+	{unicode.Hiragana, "kana"},
+	{unicode.Katakana, "kana"},
+	{unicode.Hangul, "hang"},
+}
+
+// detectScript returns the OpenType script tag of the first letter of the runes
+func detectScript(runes []rune) string {
+	for _, r := range runes {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for _, st := range scriptTags {
+			if unicode.Is(st.table, r) {
+				return st.tag
+			}
+		}
+		return "DFLT"
+	}
+	return "DFLT"
+}
@@ -0,0 +1,134 @@
+package unitype
+
+import (
+	"reflect"
+	"testing"
+)
+
+// tableData encodes the values as big endian uint16
+func tableData(values ...int) layoutData {
+	d := make(layoutData, 0, 2*len(values))
+	for _, v := range values {
+		d = append(d, byte(uint16(v)>>8), byte(v))
+	}
+	return d
+}
+
+// testGdef defines glyph 5 as mark of attachment class 1 and glyph 6 as mark of attachment class 2
+func testGdef() *gdefTable {
+	return &gdefTable{
+		data: tableData(
+			1, 0, 12, 0, 0, 22, // header
+			1, 5, 2, 3, 3, // glyph classes
+			1, 5, 2, 1, 2, // mark attachment classes
+		),
+		glyphClassDef:      12,
+		markAttachClassDef: 22,
+	}
+}
+
+func testGlyphs(indices ...GlyphIndex) []ShapedGlyph {
+	res := make([]ShapedGlyph, len(indices))
+	for i, gid := range indices {
+		res[i] = ShapedGlyph{Index: gid, Runes: []rune{'a' + rune(i)}}
+	}
+	return res
+}
+
+func glyphIndices(glyphs []ShapedGlyph) []GlyphIndex {
+	res := make([]GlyphIndex, len(glyphs))
+	for i, g := range glyphs {
+		res[i] = g.Index
+	}
+	return res
+}
+
+func TestGsubLigature(t *testing.T) {
+	// ligature 1 2 -> 10
+	table := &layoutTable{data: tableData(
+		1, 8, 1, 14, // subtable header
+		1, 1, 1, // coverage
+		1, 4, // ligature set
+		10, 2, 2, // ligature
+	)}
+
+	tests := []struct {
+		flag   uint16
+		glyphs []GlyphIndex
+		want   []GlyphIndex
+		runes  string
+	}{
+		{0, []GlyphIndex{1, 2, 3}, []GlyphIndex{10, 3}, "ab"},
+		{0, []GlyphIndex{1, 5, 2}, []GlyphIndex{1, 5, 2}, "a"},
+		{lookupIgnoreMarks, []GlyphIndex{1, 5, 2}, []GlyphIndex{10, 5}, "ac"},
+		{lookupIgnoreMarks, []GlyphIndex{1, 5, 6, 2, 3}, []GlyphIndex{10, 5, 6, 3}, "ad"},
+		{2 << 8, []GlyphIndex{1, 5, 2}, []GlyphIndex{10, 5}, "ac"},
+		{1 << 8, []GlyphIndex{1, 5, 2}, []GlyphIndex{1, 5, 2}, "a"},
+	}
+	for _, tt := range tests {
+		lookup := layoutLookup{lookupType: 4, flag: tt.flag, subtables: []int{0}}
+		glyphs := table.applyGsubLookup(lookup, testGlyphs(tt.glyphs...), testGdef())
+		if got := glyphIndices(glyphs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("flag %#x, glyphs %v: got %v, want %v", tt.flag, tt.glyphs, got, tt.want)
+			continue
+		}
+		if got := string(glyphs[0].Runes); got != tt.runes {
+			t.Errorf("flag %#x, glyphs %v: got runes %q, want %q", tt.flag, tt.glyphs, got, tt.runes)
+		}
+	}
+}
+
+func TestGsubSingle(t *testing.T) {
+	// 1 -> 11, 5 -> 15
+	table := &layoutTable{data: tableData(
+		1, 6, 10, // subtable header
+		1, 2, 1, 5, // coverage
+	)}
+	lookup := layoutLookup{lookupType: 1, flag: lookupIgnoreMarks, subtables: []int{0}}
+	glyphs := table.applyGsubLookup(lookup, testGlyphs(1, 5, 2), testGdef())
+	if got, want := glyphIndices(glyphs), []GlyphIndex{11, 5, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGposPair(t *testing.T) {
+	// pair 1 2: x advance of first glyph -50
+	table := &layoutTable{data: tableData(
+		1, 12, 4, 0, 1, 18, // subtable header
+		1, 1, 1, // coverage
+		1, 2, -50, // pair set
+	)}
+
+	tests := []struct {
+		flag   uint16
+		glyphs []GlyphIndex
+		want   int
+	}{
+		{0, []GlyphIndex{1, 2}, -50},
+		{0, []GlyphIndex{1, 5, 2}, 0},
+		{lookupIgnoreMarks, []GlyphIndex{1, 5, 2}, -50},
+		{lookupIgnoreMarks, []GlyphIndex{1, 5}, 0},
+	}
+	for _, tt := range tests {
+		lookup := layoutLookup{lookupType: 2, flag: tt.flag, subtables: []int{0}}
+		adj := make([]valueRecord, len(tt.glyphs))
+		table.applyGposLookup(lookup, testGlyphs(tt.glyphs...), adj, testGdef())
+		if adj[0].xAdvance != tt.want {
+			t.Errorf("flag %#x, glyphs %v: got %d, want %d", tt.flag, tt.glyphs, adj[0].xAdvance, tt.want)
+		}
+	}
+}
+
+func TestGposSingleYPlacement(t *testing.T) {
+	// glyph 5: y placement 120
+	table := &layoutTable{data: tableData(
+		1, 8, 2, 120, // subtable header
+		1, 1, 5, // coverage
+	)}
+	lookup := layoutLookup{lookupType: 1, subtables: []int{0}}
+	adj := make([]valueRecord, 2)
+	table.applyGposLookup(lookup, testGlyphs(1, 5), adj, nil)
+	if adj[0].yPlacement != 0 || adj[1].yPlacement != 120 {
+		t.Errorf("got %+v", adj)
+	}
+}
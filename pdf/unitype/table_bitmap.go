@@ -0,0 +1,205 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+import (
+	"bytes"
+	"image/png"
+)
+
+// Color bitmaps of glyphs are stored as PNG images in strikes of several sizes, either in the sbix table (Apple) or
+// in the CBDT table located by the CBLC table (Google).
+// https://docs.microsoft.com/en-us/typography/opentype/spec/sbix
+// https://docs.microsoft.com/en-us/typography/opentype/spec/cbdt
+// https://docs.microsoft.com/en-us/typography/opentype/spec/cblc
+
+// GlyphBitmap is the color bitmap of a glyph
+type GlyphBitmap struct {
+	PNG []byte
+
+	// size of the strike in pixels per em
+	PixelsPerEm int
+
+	// position of the left and bottom edge of the image relative to the glyph origin in pixels
+	Left, Bottom int
+
+	// size of the image in pixels
+	Width, Height int
+}
+
+// HasColorGlyphs checks if the font contains color glyphs (COLR, sbix or CBDT table)
+func (f *Font) HasColorGlyphs() bool {
+	return len(f.colr) != 0 || len(f.sbix) != 0 || len(f.cbdt) != 0
+}
+
+// GlyphBitmap returns the color bitmap of the glyph of the largest strike, nil if there is none
+func (f *Font) GlyphBitmap(gid GlyphIndex) *GlyphBitmap {
+	b := f.sbixBitmap(gid, 0)
+	if b == nil {
+		b = f.cbdtBitmap(gid)
+	}
+	if b == nil {
+		return nil
+	}
+	if b.Width == 0 || b.Height == 0 {
+		conf, err := png.DecodeConfig(bytes.NewReader(b.PNG))
+		if err != nil {
+			return nil
+		}
+		b.Width, b.Height = conf.Width, conf.Height
+	}
+	return b
+}
+
+// sbixBitmap returns the bitmap of the glyph from the sbix table
+func (f *Font) sbixBitmap(gid GlyphIndex, depth int) *GlyphBitmap {
+	sbix := f.sbix
+	if len(sbix) < 8 || f.maxp == nil || depth > 4 {
+		return nil
+	}
+	numGlyphs := int(f.maxp.numGlyphs)
+	if int(gid) >= numGlyphs {
+		return nil
+	}
+
+	var res *GlyphBitmap
+	for i := 0; i < int(sbix.u32(4)); i++ {
+		strike := int(sbix.u32(8 + 4*i))
+		ppem := int(sbix.u16(strike))
+		if res != nil && res.PixelsPerEm >= ppem {
+			continue
+		}
+		start := strike + int(sbix.u32(strike+4+4*int(gid)))
+		end := strike + int(sbix.u32(strike+8+4*int(gid)))
+		if end-start < 8 || end > len(sbix) {
+			continue
+		}
+		switch sbix.tag(start + 4) {
+		case "png":
+			res = &GlyphBitmap{
+				PNG:         sbix[start+8 : end],
+				PixelsPerEm: ppem,
+				Left:        int(sbix.i16(start)),
+				Bottom:      int(sbix.i16(start + 2)),
+			}
+		case "dupe":
+			if dupe := f.sbixBitmap(GlyphIndex(sbix.u16(start+8)), depth+1); dupe != nil {
+				res = dupe
+			}
+		}
+	}
+	return res
+}
+
+// cbdtBitmap returns the bitmap of the glyph from the CBDT table
+func (f *Font) cbdtBitmap(gid GlyphIndex) *GlyphBitmap {
+	cblc, cbdt := f.cblc, f.cbdt
+	if len(cblc) < 8 || len(cbdt) < 4 {
+		return nil
+	}
+
+	var res *GlyphBitmap
+	for i := 0; i < int(cblc.u32(4)); i++ {
+		size := 8 + 48*i
+		if size+48 > len(cblc) {
+			break
+		}
+		ppem := int(cblc[size+45])
+		if GlyphIndex(cblc.u16(size+40)) > gid || GlyphIndex(cblc.u16(size+42)) < gid ||
+			res != nil && res.PixelsPerEm >= ppem {
+			continue
+		}
+
+		// index subtable containing the glyph
+		array := int(cblc.u32(size))
+		for j := 0; j < int(cblc.u32(size+8)); j++ {
+			entry := array + 8*j
+			first, last := GlyphIndex(cblc.u16(entry)), GlyphIndex(cblc.u16(entry+2))
+			if gid < first || gid > last {
+				continue
+			}
+			if b := cbdtGlyph(cblc, cbdt, array+int(cblc.u32(entry+4)), gid, first, last); b != nil {
+				b.PixelsPerEm = ppem
+				res = b
+			}
+			break
+		}
+	}
+	return res
+}
+
+// cbdtGlyph returns the bitmap of the glyph by the index subtable
+func cbdtGlyph(cblc, cbdt layoutData, sub int, gid, first, last GlyphIndex) *GlyphBitmap {
+	indexFormat := cblc.u16(sub)
+	imageFormat := cblc.u16(sub + 2)
+	imageData := int(cblc.u32(sub + 4))
+	i := int(gid - first)
+
+	// location of the glyph data and metrics of index formats with constant size
+	var start, end int
+	var metrics layoutData
+	switch indexFormat {
+	case 1:
+		start, end = imageData+int(cblc.u32(sub+8+4*i)), imageData+int(cblc.u32(sub+12+4*i))
+	case 2:
+		size := int(cblc.u32(sub + 8))
+		start, end = imageData+i*size, imageData+(i+1)*size
+		metrics = cblc[sub+12 : sub+20]
+	case 3:
+		start, end = imageData+int(cblc.u16(sub+8+2*i)), imageData+int(cblc.u16(sub+10+2*i))
+	case 4:
+		n := int(cblc.u32(sub + 8))
+		for j := 0; j < n; j++ {
+			if GlyphIndex(cblc.u16(sub+12+4*j)) == gid {
+				start, end = imageData+int(cblc.u16(sub+14+4*j)), imageData+int(cblc.u16(sub+18+4*j))
+				break
+			}
+		}
+	case 5:
+		size := int(cblc.u32(sub + 8))
+		n := int(cblc.u32(sub + 20))
+		for j := 0; j < n; j++ {
+			if GlyphIndex(cblc.u16(sub+24+2*j)) == gid {
+				start, end = imageData+j*size, imageData+(j+1)*size
+				metrics = cblc[sub+12 : sub+20]
+				break
+			}
+		}
+	default:
+		return nil
+	}
+	if end <= start || end > len(cbdt) || metrics != nil && len(metrics) < 8 {
+		return nil
+	}
+	d := cbdt[start:end]
+
+	// image formats: 17 small metrics, 18 big metrics, 19 metrics of the index subtable, followed by the PNG data
+	var offset int
+	switch imageFormat {
+	case 17:
+		metrics, offset = d, 5
+	case 18:
+		metrics, offset = d, 8
+	case 19:
+	default:
+		return nil
+	}
+	if metrics == nil || len(d) < offset+4 {
+		return nil
+	}
+	n := int(d.u32(offset))
+	if offset+4+n > len(d) {
+		return nil
+	}
+	height, width := int(metrics[0]), int(metrics[1])
+	return &GlyphBitmap{
+		PNG:    d[offset+4 : offset+4+n],
+		Left:   int(int8(metrics[2])),
+		Bottom: int(int8(metrics[3])) - height,
+		Width:  width,
+		Height: height,
+	}
+}
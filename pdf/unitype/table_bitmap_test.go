@@ -0,0 +1,109 @@
+package unitype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"reflect"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// sbixGlyph returns the data of a glyph of an sbix strike
+func sbixGlyph(left, bottom int, graphicType string, data []byte) []byte {
+	return append(append(tableData(left, bottom), graphicType...), data...)
+}
+
+// sbixTableData returns an 'sbix' table with one strike per size in pixels per em containing the glyph data by glyph
+func sbixTableData(numGlyphs int, ppems []int, glyphs []map[GlyphIndex][]byte) []byte {
+	res := make([]byte, 8+4*len(ppems))
+	binary.BigEndian.PutUint16(res, 1)
+	binary.BigEndian.PutUint16(res[2:], 1)
+	binary.BigEndian.PutUint32(res[4:], uint32(len(ppems)))
+	for i, ppem := range ppems {
+		binary.BigEndian.PutUint32(res[8+4*i:], uint32(len(res)))
+		strike := make([]byte, 4+4*(numGlyphs+1))
+		binary.BigEndian.PutUint16(strike, uint16(ppem))
+		binary.BigEndian.PutUint16(strike[2:], 72)
+		for gid := 0; gid < numGlyphs; gid++ {
+			binary.BigEndian.PutUint32(strike[4+4*gid:], uint32(len(strike)))
+			strike = append(strike, glyphs[i][GlyphIndex(gid)]...)
+		}
+		binary.BigEndian.PutUint32(strike[4+4*numGlyphs:], uint32(len(strike)))
+		res = append(res, strike...)
+	}
+	return res
+}
+
+// cbdtTableData returns the 'CBLC' and 'CBDT' tables with one strike containing the glyph as image format 17 (small
+// metrics and PNG data) located by an index subtable of format 1
+func cbdtTableData(gid GlyphIndex, ppem int, width, height, left, top int, data []byte) ([]byte, []byte) {
+	cbdt := append(tableData(3, 0), byte(height), byte(width), byte(left), byte(top), byte(width))
+	cbdt = append(append(cbdt, tableData(0, len(data))...), data...)
+
+	size := make([]byte, 48)
+	binary.BigEndian.PutUint32(size, 56)
+	binary.BigEndian.PutUint32(size[4:], 28)
+	binary.BigEndian.PutUint32(size[8:], 1)
+	binary.BigEndian.PutUint16(size[40:], uint16(gid))
+	binary.BigEndian.PutUint16(size[42:], uint16(gid))
+	size[44], size[45], size[46] = byte(ppem), byte(ppem), 32
+	cblc := append(tableData(3, 0, 0, 1), size...)
+	cblc = append(cblc, tableData(int(gid), int(gid), 0, 8)...)
+	cblc = append(cblc, tableData(1, 17, 0, 4, 0, 0, 0, len(cbdt)-4)...)
+	return cblc, cbdt
+}
+
+func TestGlyphBitmap(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatal(err)
+	}
+	img := buf.Bytes()
+
+	plain, err := Parse(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := plain.LookupRunes([]rune("ABC"))
+
+	// sbix: the largest strike is used, B is a duplicate of A
+	ttf := addTable(t, goregular.TTF, "sbix", sbixTableData(int(plain.maxp.numGlyphs), []int{20, 40}, []map[GlyphIndex][]byte{
+		{g[0]: sbixGlyph(0, 0, "png ", img), g[1]: sbixGlyph(0, 0, "png ", img)},
+		{g[0]: sbixGlyph(1, -2, "png ", img), g[1]: sbixGlyph(0, 0, "dupe", tableData(int(g[0])))},
+	}))
+	fnt, err := Parse(bytes.NewReader(ttf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fnt.HasColorGlyphs() {
+		t.Error("no color glyphs in font with sbix table")
+	}
+	want := &GlyphBitmap{PNG: img, PixelsPerEm: 40, Left: 1, Bottom: -2, Width: 3, Height: 2}
+	for _, gid := range g[:2] {
+		if got := fnt.GlyphBitmap(gid); !reflect.DeepEqual(got, want) {
+			t.Errorf("sbix glyph %d: got %+v, want %+v", gid, got, want)
+		}
+	}
+	if got := fnt.GlyphBitmap(g[2]); got != nil {
+		t.Errorf("sbix glyph %d: got %+v, want nil", g[2], got)
+	}
+
+	// CBDT: the bottom is derived from the top bearing and the height
+	cblc, cbdt := cbdtTableData(g[2], 109, 3, 2, 1, 5, img)
+	ttf = addTable(t, goregular.TTF, "CBLC", cblc)
+	ttf = addTable(t, ttf, "CBDT", cbdt)
+	fnt, err = Parse(bytes.NewReader(ttf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &GlyphBitmap{PNG: img, PixelsPerEm: 109, Left: 1, Bottom: 3, Width: 3, Height: 2}
+	if got := fnt.GlyphBitmap(g[2]); !reflect.DeepEqual(got, want) {
+		t.Errorf("CBDT glyph %d: got %+v, want %+v", g[2], got, want)
+	}
+	if got := fnt.GlyphBitmap(g[0]); got != nil {
+		t.Errorf("CBDT glyph %d: got %+v, want nil", g[0], got)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+import "image/color"
+
+// Color glyphs of the COLR table (version 0) are drawn as layers of regular glyphs, each filled with a color of the
+// color palette of the CPAL table. The paint graphs of COLR version 1 are not supported.
+// https://docs.microsoft.com/en-us/typography/opentype/spec/colr
+// https://docs.microsoft.com/en-us/typography/opentype/spec/cpal
+
+// ColorLayer is a layer of a color glyph: the outline of Glyph filled with Color
+type ColorLayer struct {
+	Glyph GlyphIndex
+	Color color.NRGBA
+
+	// the layer is drawn in the text color instead of a palette color
+	Foreground bool
+}
+
+// ColorLayers returns the layers of a color glyph from bottom to top using the first color palette, nil if the glyph
+// is no color glyph
+func (f *Font) ColorLayers(gid GlyphIndex) []ColorLayer {
+	colr := f.colr
+	if len(colr) < 14 {
+		return nil
+	}
+	numBaseGlyphs := int(colr.u16(2))
+	baseGlyphs := int(colr.u32(4))
+	layers := int(colr.u32(8))
+	numLayers := int(colr.u16(12))
+
+	// binary search of base glyph record
+	lo, hi := 0, numBaseGlyphs
+	for lo < hi {
+		m := (lo + hi) / 2
+		switch g := GlyphIndex(colr.u16(baseGlyphs + 6*m)); {
+		case g < gid:
+			lo = m + 1
+		case g > gid:
+			hi = m
+		default:
+			lo, hi = m, m
+		}
+	}
+	rec := baseGlyphs + 6*lo
+	if lo >= numBaseGlyphs || GlyphIndex(colr.u16(rec)) != gid {
+		return nil
+	}
+	first := int(colr.u16(rec + 2))
+	count := int(colr.u16(rec + 4))
+
+	var res []ColorLayer
+	for i := first; i < first+count && i < numLayers; i++ {
+		layer := ColorLayer{Glyph: GlyphIndex(colr.u16(layers + 4*i))}
+		if c, ok := f.paletteColor(int(colr.u16(layers + 4*i + 2))); ok {
+			layer.Color = c
+		} else {
+			layer.Foreground = true
+		}
+		res = append(res, layer)
+	}
+	return res
+}
+
+// paletteColor returns the color of the first palette, false for the foreground color (index 0xFFFF)
+func (f *Font) paletteColor(index int) (color.NRGBA, bool) {
+	cpal := f.cpal
+	if index == 0xFFFF || len(cpal) < 14 || index >= int(cpal.u16(2)) || cpal.u16(4) == 0 {
+		return color.NRGBA{}, false
+	}
+	records := int(cpal.u32(8))
+	pos := records + 4*(int(cpal.u16(12))+index)
+	if pos+4 > len(cpal) {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{B: cpal[pos], G: cpal[pos+1], R: cpal[pos+2], A: cpal[pos+3]}, true
+}
@@ -0,0 +1,76 @@
+package unitype
+
+import (
+	"bytes"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// colrTableData returns a 'COLR' table of version 0 with the layers of the base glyphs, each layer given as glyph and
+// palette index. The base glyphs must be sorted.
+func colrTableData(base []GlyphIndex, layers [][][2]int) []byte {
+	d := []int{0, len(base), 0, 14, 0, 14 + 6*len(base), 0}
+	var records []int
+	for i, g := range base {
+		d = append(d, int(g), len(records)/2, len(layers[i]))
+		for _, layer := range layers[i] {
+			records = append(records, layer[0], layer[1])
+		}
+	}
+	d[6] = len(records) / 2
+	return tableData(append(d, records...)...)
+}
+
+// cpalTableData returns a 'CPAL' table of version 0 with one palette of the colors
+func cpalTableData(colors ...color.NRGBA) []byte {
+	d := tableData(0, len(colors), 1, len(colors), 0, 14, 0)
+	for _, c := range colors {
+		d = append(d, c.B, c.G, c.R, c.A)
+	}
+	return d
+}
+
+func TestColorLayers(t *testing.T) {
+	plain, err := Parse(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.HasColorGlyphs() || plain.ColorLayers(plain.LookupRunes([]rune("A"))[0]) != nil {
+		t.Error("color glyphs in font without COLR table")
+	}
+
+	g := plain.LookupRunes([]rune("ABOIl"))
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 128}
+	ttf := addTable(t, goregular.TTF, "COLR", colrTableData(
+		[]GlyphIndex{g[0], g[1]},
+		[][][2]int{
+			{{int(g[2]), 0}, {int(g[3]), 0xFFFF}, {int(g[4]), 1}},
+			{{int(g[2]), 1}},
+		},
+	))
+	ttf = addTable(t, ttf, "CPAL", cpalTableData(red, blue))
+	fnt, err := Parse(bytes.NewReader(ttf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fnt.HasColorGlyphs() {
+		t.Error("no color glyphs in font with COLR table")
+	}
+
+	for _, tc := range []struct {
+		gid  GlyphIndex
+		want []ColorLayer
+	}{
+		{g[0], []ColorLayer{{Glyph: g[2], Color: red}, {Glyph: g[3], Foreground: true}, {Glyph: g[4], Color: blue}}},
+		{g[1], []ColorLayer{{Glyph: g[2], Color: blue}}},
+		{g[2], nil},
+	} {
+		if got := fnt.ColorLayers(tc.gid); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("glyph %d: got layers %v, want %v", tc.gid, got, tc.want)
+		}
+	}
+}
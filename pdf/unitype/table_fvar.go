@@ -0,0 +1,152 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+// fvarTable represents the variation axes of a variable font (fvar), including the axis mappings of the 'avar'
+// table.
+// https://docs.microsoft.com/en-us/typography/opentype/spec/fvar
+// https://docs.microsoft.com/en-us/typography/opentype/spec/avar
+type fvarTable struct {
+	axes []VariationAxis
+
+	// avar segment maps per axis: pairs of normalized input and output values
+	segmentMaps [][][2]float64
+}
+
+// VariationAxis is a design axis of a variable font, e.g. "wght" (weight), "wdth" (width) or "slnt" (slant)
+type VariationAxis struct {
+	Tag               string
+	Min, Default, Max float64
+	Name              string
+	nameID            uint16
+	hidden            bool
+}
+
+func (f *font) parseFvar(r *byteReader) (*fvarTable, error) {
+	d, err := f.readTableData(r, "fvar")
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	axesOffset := int(d.u16(4))
+	axisCount := int(d.u16(8))
+	axisSize := int(d.u16(10))
+	if d.u16(0) != 1 || axisSize < 20 || axesOffset+axisCount*axisSize > len(d) {
+		return nil, errRangeCheck
+	}
+
+	t := &fvarTable{}
+	for i := 0; i < axisCount; i++ {
+		a := axesOffset + i*axisSize
+		axis := VariationAxis{
+			Tag:     d.tag(a),
+			Min:     fixed(d.u32(a + 4)).Float64(),
+			Default: fixed(d.u32(a + 8)).Float64(),
+			Max:     fixed(d.u32(a + 12)).Float64(),
+			hidden:  d.u16(a+16)&1 != 0,
+			nameID:  d.u16(a + 18),
+		}
+		t.axes = append(t.axes, axis)
+	}
+
+	// optional axis mappings
+	avar, err := f.readTableData(r, "avar")
+	if err != nil || len(avar) < 8 || int(avar.u16(6)) != axisCount {
+		return t, nil
+	}
+	pos := 8
+	for i := 0; i < axisCount; i++ {
+		n := int(avar.u16(pos))
+		pos += 2
+		if pos+4*n > len(avar) {
+			t.segmentMaps = nil
+			break
+		}
+		var m [][2]float64
+		for j := 0; j < n; j++ {
+			m = append(m, [2]float64{f2dot14(avar.i16(pos)).Float64(), f2dot14(avar.i16(pos + 2)).Float64()})
+			pos += 4
+		}
+		t.segmentMaps = append(t.segmentMaps, m)
+	}
+	return t, nil
+}
+
+// normalize converts the user coordinates of the axes to normalized coordinates in the range -1 to 1
+func (t *fvarTable) normalize(coords map[string]float64) []float64 {
+	res := make([]float64, len(t.axes))
+	for i, axis := range t.axes {
+		v, ok := coords[axis.Tag]
+		if !ok {
+			continue
+		}
+		switch {
+		case v < axis.Min:
+			v = axis.Min
+		case v > axis.Max:
+			v = axis.Max
+		}
+		switch {
+		case v < axis.Default && axis.Default > axis.Min:
+			res[i] = (v - axis.Default) / (axis.Default - axis.Min)
+		case v > axis.Default && axis.Max > axis.Default:
+			res[i] = (v - axis.Default) / (axis.Max - axis.Default)
+		}
+
+		// avar mapping
+		if i < len(t.segmentMaps) {
+			res[i] = mapSegments(t.segmentMaps[i], res[i])
+		}
+
+		// round to F2DOT14 precision
+		res[i] = float64(int(res[i]*16384+0.5*sign(res[i]))) / 16384
+	}
+	return res
+}
+
+// mapSegments maps the value by the piecewise linear function given by the segment map
+func mapSegments(m [][2]float64, v float64) float64 {
+	if len(m) == 0 {
+		return v
+	}
+	for i, p := range m {
+		if v == p[0] {
+			return p[1]
+		}
+		if v < p[0] {
+			if i == 0 {
+				return p[1]
+			}
+			prev := m[i-1]
+			if p[0] == prev[0] {
+				return prev[1]
+			}
+			return prev[1] + (v-prev[0])*(p[1]-prev[1])/(p[0]-prev[0])
+		}
+	}
+	return m[len(m)-1][1]
+}
+
+// sign returns -1 for negative values, 1 otherwise
+func sign(v float64) float64 {
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
+
+// readTableData reads the raw data of a table, nil if the font does not contain it
+func (f *font) readTableData(r *byteReader, name string) (layoutData, error) {
+	tr, has, err := f.seekToTable(r, name)
+	if err != nil || !has || tr == nil {
+		return nil, err
+	}
+	var data []byte
+	if err := r.readBytes(&data, int(tr.length)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
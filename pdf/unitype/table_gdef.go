@@ -0,0 +1,102 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+// GDEF: glyph definition table, only the glyph classes and mark attachment classes are used
+// https://docs.microsoft.com/en-us/typography/opentype/spec/gdef
+
+// glyph classes of the glyph class definition table
+const (
+	gdefBase      = 1
+	gdefLigature  = 2
+	gdefMark      = 3
+	gdefComponent = 4
+)
+
+// lookup flags
+const (
+	lookupIgnoreBaseGlyphs   = 0x0002
+	lookupIgnoreLigatures    = 0x0004
+	lookupIgnoreMarks        = 0x0008
+	lookupMarkAttachmentType = 0xFF00
+)
+
+type gdefTable struct {
+	data               layoutData
+	glyphClassDef      int // offset of the glyph class definition table, 0 if none
+	markAttachClassDef int // offset of the mark attachment class definition table, 0 if none
+}
+
+func (f *font) parseGdef(r *byteReader) (*gdefTable, error) {
+	tr, has, err := f.seekToTable(r, "GDEF")
+	if err != nil {
+		return nil, err
+	}
+	if !has || tr == nil {
+		return nil, nil
+	}
+
+	var data []byte
+	if err := r.readBytes(&data, int(tr.length)); err != nil {
+		return nil, err
+	}
+	d := layoutData(data)
+	if len(d) < 12 || d.u16(0) != 1 {
+		return nil, errLayoutTable
+	}
+	return &gdefTable{
+		data:               d,
+		glyphClassDef:      int(d.u16(4)),
+		markAttachClassDef: int(d.u16(10)),
+	}, nil
+}
+
+// glyphClass returns the class of the glyph (gdefBase, gdefLigature, gdefMark, gdefComponent) or 0 if undefined
+func (t *gdefTable) glyphClass(gid GlyphIndex) int {
+	if t == nil || t.glyphClassDef == 0 {
+		return 0
+	}
+	return t.data.glyphClass(t.glyphClassDef, gid)
+}
+
+// markAttachClass returns the mark attachment class of the glyph
+func (t *gdefTable) markAttachClass(gid GlyphIndex) int {
+	if t == nil || t.markAttachClassDef == 0 {
+		return 0
+	}
+	return t.data.glyphClass(t.markAttachClassDef, gid)
+}
+
+// ignores checks if the glyph is skipped by a lookup with the given flag
+func (t *gdefTable) ignores(flag uint16, gid GlyphIndex) bool {
+	if flag&(lookupIgnoreBaseGlyphs|lookupIgnoreLigatures|lookupIgnoreMarks|lookupMarkAttachmentType) == 0 {
+		return false
+	}
+	switch t.glyphClass(gid) {
+	case gdefBase:
+		return flag&lookupIgnoreBaseGlyphs != 0
+	case gdefLigature:
+		return flag&lookupIgnoreLigatures != 0
+	case gdefMark:
+		if flag&lookupIgnoreMarks != 0 {
+			return true
+		}
+		if at := int(flag >> 8); at != 0 {
+			return t.markAttachClass(gid) != at
+		}
+	}
+	return false
+}
+
+// next returns the index of the next glyph after i not skipped by a lookup with the given flag, or -1
+func (t *gdefTable) next(flag uint16, glyphs []ShapedGlyph, i int) int {
+	for j := i + 1; j < len(glyphs); j++ {
+		if !t.ignores(flag, glyphs[j].Index) {
+			return j
+		}
+	}
+	return -1
+}
@@ -29,18 +29,18 @@ type glyfTable struct {
 }
 
 func (f *font) parseGlyf(r *byteReader) (*glyfTable, error) {
-	if f.maxp == nil || f.loca == nil {
-		// logrus.Debug("required field missing (glyf)")
-		return nil, errRequiredField
-	}
-
 	tr, has, err := f.seekToTable(r, "glyf")
 	if err != nil {
 		// logrus.Debugf("ERROR: %v", err)
 		return nil, err
 	}
 	if !has {
-		return nil, nil // table not found.
+		return nil, nil // table not found, e.g. bitmap fonts.
+	}
+
+	if f.maxp == nil || f.loca == nil {
+		// logrus.Debug("required field missing (glyf)")
+		return nil, errRequiredField
 	}
 
 	glyf := &glyfTable{}
@@ -0,0 +1,173 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+import "math/bits"
+
+// GPOS: glyph positioning table
+// https://docs.microsoft.com/en-us/typography/opentype/spec/gpos
+
+func (f *font) parseGpos(r *byteReader) (*layoutTable, error) {
+	return f.parseLayoutTable(r, "GPOS", 9)
+}
+
+// valueRecord holds the adjustments of a GPOS value record in font units. Device tables are ignored.
+type valueRecord struct {
+	xPlacement, yPlacement int
+	xAdvance, yAdvance     int
+}
+
+// valueRecordSize returns the size of a value record with the given format in bytes
+func valueRecordSize(format uint16) int {
+	return bits.OnesCount16(format) * 2
+}
+
+// valueRecord reads a value record with the given format at the given offset
+func (d layoutData) valueRecord(offset int, format uint16) valueRecord {
+	var v valueRecord
+	for bit := uint16(1); bit <= 0x80; bit <<= 1 {
+		if format&bit == 0 {
+			continue
+		}
+		value := int(d.i16(offset))
+		offset += 2
+		switch bit {
+		case 0x01:
+			v.xPlacement = value
+		case 0x02:
+			v.yPlacement = value
+		case 0x04:
+			v.xAdvance = value
+		case 0x08:
+			v.yAdvance = value
+		}
+	}
+	return v
+}
+
+// applyGposLookup applies the lookup to all glyphs of the buffer. Adjustments are added in font units. Glyphs
+// skipped by the lookup flag according to the glyph classes of GDEF are neither adjusted nor paired.
+func (t *layoutTable) applyGposLookup(lookup layoutLookup, glyphs []ShapedGlyph, adj []valueRecord, gdef *gdefTable) {
+	d := layoutData(t.data)
+	for i := 0; i < len(glyphs); i++ {
+		if gdef.ignores(lookup.flag, glyphs[i].Index) {
+			continue
+		}
+		for _, st := range lookup.subtables {
+			var applied bool
+			switch lookup.lookupType {
+			case 1:
+				applied = d.gposSingle(st, glyphs, adj, i)
+			case 2:
+				second := gdef.next(lookup.flag, glyphs, i)
+				if second < 0 {
+					break
+				}
+				var skipSecond bool
+				applied, skipSecond = d.gposPair(st, glyphs, adj, i, second)
+				if applied && skipSecond {
+					i = second
+				}
+			}
+			if applied {
+				break
+			}
+		}
+	}
+}
+
+// gposSingle adjusts the position of a single glyph (lookup type 1)
+func (d layoutData) gposSingle(st int, glyphs []ShapedGlyph, adj []valueRecord, i int) bool {
+	ci := d.coverageIndex(st+int(d.u16(st+2)), glyphs[i].Index)
+	if ci < 0 {
+		return false
+	}
+	format := d.u16(st + 4)
+	var v valueRecord
+	switch d.u16(st) {
+	case 1:
+		v = d.valueRecord(st+6, format)
+	case 2:
+		if ci >= int(d.u16(st+6)) {
+			return false
+		}
+		v = d.valueRecord(st+8+ci*valueRecordSize(format), format)
+	default:
+		return false
+	}
+	adj[i].add(v)
+	return true
+}
+
+// gposPair adjusts the positions of the glyphs at i and j (lookup type 2), e.g. kerning. The second return value
+// indicates that the second glyph was adjusted as well and must be skipped.
+func (d layoutData) gposPair(st int, glyphs []ShapedGlyph, adj []valueRecord, i, j int) (bool, bool) {
+	ci := d.coverageIndex(st+int(d.u16(st+2)), glyphs[i].Index)
+	if ci < 0 {
+		return false, false
+	}
+	format1 := d.u16(st + 4)
+	format2 := d.u16(st + 6)
+	size1 := valueRecordSize(format1)
+	size2 := valueRecordSize(format2)
+	second := glyphs[j].Index
+
+	var rec int
+	switch d.u16(st) {
+	case 1:
+		// pair sets with records sorted by second glyph
+		if ci >= int(d.u16(st+8)) {
+			return false, false
+		}
+		set := st + int(d.u16(st+10+ci*2))
+		count := int(d.u16(set))
+		recSize := 2 + size1 + size2
+		lo, hi := 0, count
+		rec = -1
+		for lo < hi {
+			m := (lo + hi) / 2
+			g := GlyphIndex(d.u16(set + 2 + m*recSize))
+			switch {
+			case g == second:
+				rec = set + 2 + m*recSize + 2
+				lo = hi
+			case g < second:
+				lo = m + 1
+			default:
+				hi = m
+			}
+		}
+		if rec < 0 {
+			return false, false
+		}
+
+	case 2:
+		// class based pairs
+		class1 := d.glyphClass(st+int(d.u16(st+8)), glyphs[i].Index)
+		class2 := d.glyphClass(st+int(d.u16(st+10)), second)
+		class1Count := int(d.u16(st + 12))
+		class2Count := int(d.u16(st + 14))
+		if class1 >= class1Count || class2 >= class2Count {
+			return false, false
+		}
+		rec = st + 16 + (class1*class2Count+class2)*(size1+size2)
+
+	default:
+		return false, false
+	}
+
+	adj[i].add(d.valueRecord(rec, format1))
+	adj[j].add(d.valueRecord(rec+size1, format2))
+	return true, format2 != 0
+}
+
+// add adds the adjustments of the given value record
+func (q *valueRecord) add(v valueRecord) {
+	q.xPlacement += v.xPlacement
+	q.yPlacement += v.yPlacement
+	q.xAdvance += v.xAdvance
+	q.yAdvance += v.yAdvance
+}
@@ -0,0 +1,169 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+// GSUB: glyph substitution table
+// https://docs.microsoft.com/en-us/typography/opentype/spec/gsub
+
+func (f *font) parseGsub(r *byteReader) (*layoutTable, error) {
+	return f.parseLayoutTable(r, "GSUB", 7)
+}
+
+// applyGsubLookup applies the lookup to all glyphs of the buffer. Glyphs skipped by the lookup flag according to
+// the glyph classes of GDEF are neither substituted nor matched as ligature components.
+func (t *layoutTable) applyGsubLookup(lookup layoutLookup, glyphs []ShapedGlyph, gdef *gdefTable) []ShapedGlyph {
+	d := layoutData(t.data)
+	for i := 0; i < len(glyphs); i++ {
+		if gdef.ignores(lookup.flag, glyphs[i].Index) {
+			continue
+		}
+		for _, st := range lookup.subtables {
+			var applied bool
+			switch lookup.lookupType {
+			case 1:
+				applied = d.gsubSingle(st, glyphs, i)
+			case 2:
+				var n int
+				glyphs, n = d.gsubMultiple(st, glyphs, i)
+				applied = n != 0
+
+				// continue after the inserted glyphs
+				if applied {
+					i += n - 1
+				}
+			case 3:
+				applied = d.gsubAlternate(st, glyphs, i)
+			case 4:
+				glyphs, applied = d.gsubLigature(st, glyphs, i, gdef, lookup.flag)
+			}
+			if applied {
+				break
+			}
+		}
+	}
+	return glyphs
+}
+
+// gsubSingle replaces a glyph by another glyph (lookup type 1)
+func (d layoutData) gsubSingle(st int, glyphs []ShapedGlyph, i int) bool {
+	ci := d.coverageIndex(st+int(d.u16(st+2)), glyphs[i].Index)
+	if ci < 0 {
+		return false
+	}
+	switch d.u16(st) {
+	case 1:
+		glyphs[i].Index = GlyphIndex(int(glyphs[i].Index) + int(d.i16(st+4)))
+		return true
+	case 2:
+		if ci >= int(d.u16(st+4)) {
+			return false
+		}
+		glyphs[i].Index = GlyphIndex(d.u16(st + 6 + ci*2))
+		return true
+	}
+	return false
+}
+
+// gsubMultiple replaces a glyph by a sequence of glyphs (lookup type 2) and returns the number of inserted glyphs.
+// The characters stay with the first glyph.
+func (d layoutData) gsubMultiple(st int, glyphs []ShapedGlyph, i int) ([]ShapedGlyph, int) {
+	if d.u16(st) != 1 {
+		return glyphs, 0
+	}
+	ci := d.coverageIndex(st+int(d.u16(st+2)), glyphs[i].Index)
+	if ci < 0 || ci >= int(d.u16(st+4)) {
+		return glyphs, 0
+	}
+	seq := st + int(d.u16(st+6+ci*2))
+	count := int(d.u16(seq))
+	if count == 0 {
+		return glyphs, 0
+	}
+
+	repl := make([]ShapedGlyph, count)
+	for j := range repl {
+		repl[j].Index = GlyphIndex(d.u16(seq + 2 + j*2))
+	}
+	repl[0].Runes = glyphs[i].Runes
+
+	res := make([]ShapedGlyph, 0, len(glyphs)+count-1)
+	res = append(res, glyphs[:i]...)
+	res = append(res, repl...)
+	res = append(res, glyphs[i+1:]...)
+	return res, count
+}
+
+// gsubAlternate replaces a glyph by the first of its alternates (lookup type 3)
+func (d layoutData) gsubAlternate(st int, glyphs []ShapedGlyph, i int) bool {
+	if d.u16(st) != 1 {
+		return false
+	}
+	ci := d.coverageIndex(st+int(d.u16(st+2)), glyphs[i].Index)
+	if ci < 0 || ci >= int(d.u16(st+4)) {
+		return false
+	}
+	set := st + int(d.u16(st+6+ci*2))
+	if d.u16(set) == 0 {
+		return false
+	}
+	glyphs[i].Index = GlyphIndex(d.u16(set + 2))
+	return true
+}
+
+// gsubLigature replaces a sequence of glyphs by a single glyph (lookup type 4). The ligature glyph represents the
+// characters of all components. Glyphs skipped by the lookup flag between the components, e.g. marks, are kept
+// after the ligature.
+func (d layoutData) gsubLigature(st int, glyphs []ShapedGlyph, i int, gdef *gdefTable, flag uint16) ([]ShapedGlyph, bool) {
+	if d.u16(st) != 1 {
+		return glyphs, false
+	}
+	ci := d.coverageIndex(st+int(d.u16(st+2)), glyphs[i].Index)
+	if ci < 0 || ci >= int(d.u16(st+4)) {
+		return glyphs, false
+	}
+	set := st + int(d.u16(st+6+ci*2))
+
+	// ligatures are ordered by preference
+	count := int(d.u16(set))
+	for j := 0; j < count; j++ {
+		lig := set + int(d.u16(set+2+j*2))
+		components := int(d.u16(lig + 2))
+		if components == 0 {
+			continue
+		}
+		positions := []int{i}
+		for k := 1; k < components; k++ {
+			p := gdef.next(flag, glyphs, positions[k-1])
+			if p < 0 || glyphs[p].Index != GlyphIndex(d.u16(lig+4+(k-1)*2)) {
+				positions = nil
+				break
+			}
+			positions = append(positions, p)
+		}
+		if positions == nil {
+			continue
+		}
+
+		var runes []rune
+		for _, p := range positions {
+			runes = append(runes, glyphs[p].Runes...)
+		}
+		last := positions[len(positions)-1]
+		res := make([]ShapedGlyph, 0, len(glyphs)-components+1)
+		res = append(res, glyphs[:i]...)
+		res = append(res, ShapedGlyph{Index: GlyphIndex(d.u16(lig)), Runes: runes})
+		for p, k := i+1, 1; p < last; p++ {
+			if p == positions[k] {
+				k++
+				continue
+			}
+			res = append(res, glyphs[p])
+		}
+		res = append(res, glyphs[last+1:]...)
+		return res, true
+	}
+	return glyphs, false
+}
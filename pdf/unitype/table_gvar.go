@@ -0,0 +1,267 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+// gvar contains the variations of the glyph outlines of a variable font: for every glyph a list of tuples, each with
+// deltas of the points that are scaled by the distance of the instance to the peak of the tuple. The deltas of the
+// points not referenced by a tuple are inferred by interpolation (IUP).
+// https://docs.microsoft.com/en-us/typography/opentype/spec/gvar
+// https://docs.microsoft.com/en-us/typography/opentype/spec/otvarcommonformats#tuple-variation-store
+
+// flags of tuple variation data
+const (
+	sharedPointNumbers  = 0x8000
+	embeddedPeakTuple   = 0x8000
+	intermediateRegion  = 0x4000
+	privatePointNumbers = 0x2000
+	tupleCountMask      = 0x0FFF
+)
+
+// glyphDeltas returns the deltas of the points of the glyph including the four phantom points at the end, nil if the
+// glyph has no variations. x and y are the original coordinates of the points as needed for interpolation, endPts the
+// last point of each contour of simple glyphs.
+func glyphDeltas(gvar layoutData, gid GlyphIndex, x, y []float64, endPts []int, coords []float64) ([]float64,
+	[]float64) {
+	if len(gvar) < 20 || int(gid) >= int(gvar.u16(12)) {
+		return nil, nil
+	}
+	axisCount := int(gvar.u16(4))
+	sharedTuples := int(gvar.u32(8))
+	var start, end int
+	if gvar.u16(14)&1 == 0 {
+		start, end = 2*int(gvar.u16(20+2*int(gid))), 2*int(gvar.u16(22+2*int(gid)))
+	} else {
+		start, end = int(gvar.u32(20+4*int(gid))), int(gvar.u32(24+4*int(gid)))
+	}
+	if start >= end {
+		return nil, nil
+	}
+	data := int(gvar.u32(16)) + start
+
+	numPoints := len(x)
+	dx, dy := make([]float64, numPoints), make([]float64, numPoints)
+	tupleCount := int(gvar.u16(data))
+	pos := data + int(gvar.u16(data+2))
+	var sharedPoints []int
+	if tupleCount&sharedPointNumbers != 0 {
+		sharedPoints, pos = readPointNumbers(gvar, pos)
+	}
+
+	header := data + 4
+	for i := 0; i < tupleCount&tupleCountMask; i++ {
+		size := int(gvar.u16(header))
+		index := int(gvar.u16(header + 2))
+		header += 4
+
+		// peak and intermediate region
+		peak := make([]float64, axisCount)
+		if index&embeddedPeakTuple != 0 {
+			for a := range peak {
+				peak[a] = f2dot14(gvar.i16(header + 2*a)).Float64()
+			}
+			header += 2 * axisCount
+		} else {
+			for a := range peak {
+				peak[a] = f2dot14(gvar.i16(sharedTuples + 2*(axisCount*(index&tupleCountMask)+a))).Float64()
+			}
+		}
+		var startTuple, endTuple []float64
+		if index&intermediateRegion != 0 {
+			for a := 0; a < axisCount; a++ {
+				startTuple = append(startTuple, f2dot14(gvar.i16(header+2*a)).Float64())
+				endTuple = append(endTuple, f2dot14(gvar.i16(header+2*(axisCount+a))).Float64())
+			}
+			header += 4 * axisCount
+		}
+
+		tupleData := pos
+		pos += size
+
+		// scalar of the tuple for the instance
+		scalar := 1.0
+		for a := 0; a < axisCount && scalar != 0; a++ {
+			var coord float64
+			if a < len(coords) {
+				coord = coords[a]
+			}
+			s, e := peak[a], 0.0
+			if s > 0 {
+				s, e = 0, peak[a]
+			}
+			if startTuple != nil {
+				s, e = startTuple[a], endTuple[a]
+			}
+			scalar *= axisScalar(coord, s, peak[a], e)
+		}
+		if scalar == 0 {
+			continue
+		}
+
+		// points and deltas
+		points := sharedPoints
+		if index&privatePointNumbers != 0 {
+			points, tupleData = readPointNumbers(gvar, tupleData)
+		}
+		n := numPoints
+		if points != nil {
+			n = len(points)
+		}
+		var tx, ty []float64
+		tx, tupleData = readDeltas(gvar, tupleData, n)
+		ty, _ = readDeltas(gvar, tupleData, n)
+
+		if points == nil {
+			for p := 0; p < numPoints; p++ {
+				dx[p] += scalar * tx[p]
+				dy[p] += scalar * ty[p]
+			}
+			continue
+		}
+		ax, ay := make([]float64, numPoints), make([]float64, numPoints)
+		touched := make([]bool, numPoints)
+		for j, p := range points {
+			if p < numPoints {
+				ax[p], ay[p] = tx[j], ty[j]
+				touched[p] = true
+			}
+		}
+		interpolateUntouched(ax, x, touched, endPts)
+		interpolateUntouched(ay, y, touched, endPts)
+		for p := 0; p < numPoints; p++ {
+			dx[p] += scalar * ax[p]
+			dy[p] += scalar * ay[p]
+		}
+	}
+	return dx, dy
+}
+
+// readPointNumbers reads packed point numbers, nil means all points
+func readPointNumbers(d layoutData, pos int) ([]int, int) {
+	if pos >= len(d) {
+		return nil, pos
+	}
+	count := int(d[pos])
+	pos++
+	if count&0x80 != 0 {
+		if pos >= len(d) {
+			return nil, pos
+		}
+		count = (count&0x7F)<<8 | int(d[pos])
+		pos++
+	}
+	if count == 0 {
+		return nil, pos
+	}
+
+	res := make([]int, 0, count)
+	var point int
+	for len(res) < count && pos < len(d) {
+		control := d[pos]
+		pos++
+		n := int(control&0x7F) + 1
+		for j := 0; j < n && len(res) < count; j++ {
+			if control&0x80 != 0 {
+				point += int(d.u16(pos))
+				pos += 2
+			} else {
+				if pos < len(d) {
+					point += int(d[pos])
+				}
+				pos++
+			}
+			res = append(res, point)
+		}
+	}
+	return res, pos
+}
+
+// readDeltas reads count packed deltas
+func readDeltas(d layoutData, pos int, count int) ([]float64, int) {
+	res := make([]float64, 0, count)
+	for len(res) < count && pos < len(d) {
+		control := d[pos]
+		pos++
+		n := int(control&0x3F) + 1
+		for j := 0; j < n && len(res) < count; j++ {
+			switch control & 0xC0 {
+			case 0x80:
+				res = append(res, 0)
+			case 0x40:
+				res = append(res, float64(d.i16(pos)))
+				pos += 2
+			case 0xC0:
+				res = append(res, float64(int32(d.u32(pos))))
+				pos += 4
+			default:
+				if pos < len(d) {
+					res = append(res, float64(int8(d[pos])))
+				}
+				pos++
+			}
+		}
+	}
+	for len(res) < count {
+		res = append(res, 0)
+	}
+	return res, pos
+}
+
+// interpolateUntouched infers the deltas of the points of each contour not referenced by a tuple from the deltas of
+// the neighbouring referenced points
+func interpolateUntouched(deltas, orig []float64, touched []bool, endPts []int) {
+	first := 0
+	for _, last := range endPts {
+		if last >= len(deltas) {
+			break
+		}
+		var refs []int
+		for p := first; p <= last; p++ {
+			if touched[p] {
+				refs = append(refs, p)
+			}
+		}
+		switch {
+		case len(refs) == 0:
+		case len(refs) == 1:
+			for p := first; p <= last; p++ {
+				deltas[p] = deltas[refs[0]]
+			}
+		default:
+			for i, p1 := range refs {
+				p2 := refs[(i+1)%len(refs)]
+				for p := p1 + 1; ; p++ {
+					if p > last {
+						p = first
+					}
+					if p == p2 {
+						break
+					}
+					deltas[p] = interpolateDelta(orig[p], orig[p1], orig[p2], deltas[p1], deltas[p2])
+				}
+			}
+		}
+		first = last + 1
+	}
+}
+
+// interpolateDelta returns the delta of a coordinate between two referenced points
+func interpolateDelta(c, c1, c2, d1, d2 float64) float64 {
+	if c1 > c2 {
+		c1, c2, d1, d2 = c2, c1, d2, d1
+	}
+	switch {
+	case c1 == c2:
+		if d1 == d2 {
+			return d1
+		}
+		return 0
+	case c <= c1:
+		return d1
+	case c >= c2:
+		return d2
+	}
+	return d1 + (c-c1)/(c2-c1)*(d2-d1)
+}
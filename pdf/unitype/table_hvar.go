@@ -0,0 +1,156 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+// Variation deltas of metrics are stored in item variation stores of the HVAR (horizontal metrics) and MVAR (font
+// wide metrics) tables.
+// https://docs.microsoft.com/en-us/typography/opentype/spec/otvarcommonformats#item-variation-store
+// https://docs.microsoft.com/en-us/typography/opentype/spec/hvar
+// https://docs.microsoft.com/en-us/typography/opentype/spec/mvar
+
+// itemVariationStore is an item variation store at offset of the table data
+type itemVariationStore struct {
+	d      layoutData
+	offset int
+}
+
+// delta returns the delta of the item for the normalized coordinates
+func (s itemVariationStore) delta(outer, inner int, coords []float64) float64 {
+	d, off := s.d, s.offset
+	if d.u16(off) != 1 || outer >= int(d.u16(off+6)) {
+		return 0
+	}
+	regionList := off + int(d.u32(off+2))
+	axisCount := int(d.u16(regionList))
+	regionCount := int(d.u16(regionList + 2))
+
+	data := off + int(d.u32(off+8+4*outer))
+	itemCount := int(d.u16(data))
+	wordDeltaCount := int(d.u16(data + 2))
+	regionIndexCount := int(d.u16(data + 4))
+	if inner >= itemCount {
+		return 0
+	}
+	longWords := wordDeltaCount&0x8000 != 0
+	wordCount := wordDeltaCount & 0x7FFF
+	rowSize := 2*wordCount + (regionIndexCount - wordCount)
+	if longWords {
+		rowSize *= 2
+	}
+	row := data + 6 + 2*regionIndexCount + inner*rowSize
+
+	var res float64
+	pos := row
+	for j := 0; j < regionIndexCount; j++ {
+		var v float64
+		switch {
+		case j < wordCount && longWords:
+			v = float64(int32(d.u32(pos)))
+			pos += 4
+		case j < wordCount || longWords:
+			v = float64(d.i16(pos))
+			pos += 2
+		default:
+			if pos < len(d) {
+				v = float64(int8(d[pos]))
+			}
+			pos++
+		}
+		region := int(d.u16(data + 6 + 2*j))
+		if v == 0 || region >= regionCount {
+			continue
+		}
+
+		// scalar of the region
+		scalar := 1.0
+		for a := 0; a < axisCount && scalar != 0; a++ {
+			r := regionList + 4 + region*axisCount*6 + a*6
+			var coord float64
+			if a < len(coords) {
+				coord = coords[a]
+			}
+			start, peak, end := f2dot14(d.i16(r)).Float64(), f2dot14(d.i16(r+2)).Float64(), f2dot14(d.i16(r+4)).Float64()
+			scalar *= axisScalar(coord, start, peak, end)
+		}
+		res += scalar * v
+	}
+	return res
+}
+
+// axisScalar returns the factor of a region for a coordinate of an axis
+func axisScalar(coord, start, peak, end float64) float64 {
+	switch {
+	case peak == 0 || start > peak || peak > end || start < 0 && end > 0:
+		return 1
+	case coord == peak:
+		return 1
+	case coord <= start || coord >= end:
+		return 0
+	case coord < peak:
+		return (coord - start) / (peak - start)
+	}
+	return (end - coord) / (end - peak)
+}
+
+// deltaSetIndex returns the outer and inner index of the item for the glyph by the delta set index map at offset,
+// the glyph ID itself is the inner index if there is no map
+func deltaSetIndex(d layoutData, offset int, gid GlyphIndex) (int, int) {
+	if offset == 0 || offset+2 > len(d) {
+		return 0, int(gid)
+	}
+	format := d[offset]
+	entryFormat := d[offset+1]
+	var mapCount, entries int
+	if format == 0 {
+		mapCount = int(d.u16(offset + 2))
+		entries = offset + 4
+	} else {
+		mapCount = int(d.u32(offset + 2))
+		entries = offset + 6
+	}
+	if mapCount == 0 {
+		return 0, int(gid)
+	}
+	i := int(gid)
+	if i >= mapCount {
+		i = mapCount - 1
+	}
+	entrySize := int(entryFormat>>4&3) + 1
+	var entry int
+	for pos := entries + i*entrySize; pos < entries+(i+1)*entrySize && pos < len(d); pos++ {
+		entry = entry<<8 | int(d[pos])
+	}
+	innerBits := int(entryFormat&0xF) + 1
+	return entry >> innerBits, entry & (1<<innerBits - 1)
+}
+
+// advanceDelta returns the delta of the advance width of the glyph from the HVAR table
+func advanceDelta(hvar layoutData, gid GlyphIndex, coords []float64) float64 {
+	if len(hvar) < 20 {
+		return 0
+	}
+	outer, inner := deltaSetIndex(hvar, int(hvar.u32(8)), gid)
+	return itemVariationStore{d: hvar, offset: int(hvar.u32(4))}.delta(outer, inner, coords)
+}
+
+// metricsDeltas returns the deltas of the font wide metrics from the MVAR table by value tag
+func metricsDeltas(mvar layoutData, coords []float64) map[string]float64 {
+	res := make(map[string]float64)
+	if len(mvar) < 12 {
+		return res
+	}
+	recordSize := int(mvar.u16(6))
+	recordCount := int(mvar.u16(8))
+	store := itemVariationStore{d: mvar, offset: int(mvar.u16(10))}
+	if store.offset == 0 {
+		return res
+	}
+	for i := 0; i < recordCount; i++ {
+		rec := 12 + i*recordSize
+		res[mvar.tag(rec)] = store.delta(int(mvar.u16(rec+4)), int(mvar.u16(rec+6)), coords)
+	}
+	return res
+}
@@ -0,0 +1,91 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+// kernTable represents the kerning pairs of horizontal format 0 subtables of the 'kern' table, both in the
+// Microsoft (version 0) and the Apple (version 1.0) variant.
+// https://docs.microsoft.com/en-us/typography/opentype/spec/kern
+type kernTable struct {
+	pairs map[uint32]int16 // left glyph << 16 | right glyph -> value in font units
+}
+
+func (f *font) parseKern(r *byteReader) (*kernTable, error) {
+	tr, has, err := f.seekToTable(r, "kern")
+	if err != nil {
+		return nil, err
+	}
+	if !has || tr == nil {
+		return nil, nil
+	}
+
+	var data []byte
+	if err := r.readBytes(&data, int(tr.length)); err != nil {
+		return nil, err
+	}
+	d := layoutData(data)
+
+	t := &kernTable{pairs: make(map[uint32]int16)}
+	var offset, nTables int
+	apple := d.u16(0) == 1
+	if apple {
+		nTables = int(d.u32(4))
+		offset = 8
+	} else {
+		nTables = int(d.u16(2))
+		offset = 4
+	}
+
+	for i := 0; i < nTables && offset < len(d); i++ {
+		// subtable header
+		var length, format, headerSize int
+		var horizontal, crossStream, minimum bool
+		if apple {
+			length = int(d.u32(offset))
+			coverage := d.u16(offset + 4)
+			format = int(coverage & 0xFF)
+			horizontal = coverage&0x8000 == 0
+			crossStream = coverage&0x4000 != 0
+			headerSize = 8
+		} else {
+			length = int(d.u16(offset + 2))
+			coverage := d.u16(offset + 4)
+			format = int(coverage >> 8)
+			horizontal = coverage&0x1 != 0
+			minimum = coverage&0x2 != 0
+			crossStream = coverage&0x4 != 0
+			headerSize = 6
+		}
+		if length <= 0 {
+			break
+		}
+
+		if format == 0 && horizontal && !minimum && !crossStream {
+			n := int(d.u16(offset + headerSize))
+			for j := 0; j < n; j++ {
+				rec := offset + headerSize + 8 + j*6
+				if rec+6 > len(d) {
+					break
+				}
+				t.pairs[d.u32(rec)] = d.i16(rec + 4)
+			}
+		}
+		offset += length
+	}
+
+	if len(t.pairs) == 0 {
+		return nil, nil
+	}
+	return t, nil
+}
+
+// GetKerning returns the kerning adjustment of the 'kern' table for the given pair of glyphs in thousandths of the
+// font size
+func (f *Font) GetKerning(left, right GlyphIndex) int {
+	if f.kern == nil || f.head == nil || f.head.unitsPerEm == 0 {
+		return 0
+	}
+	return int(f.kern.pairs[uint32(left)<<16|uint32(right)]) * 1000 / int(f.head.unitsPerEm)
+}
@@ -0,0 +1,85 @@
+package unitype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// addTable returns the font file with the table added
+func addTable(t *testing.T, ttf []byte, tag string, data []byte) []byte {
+	t.Helper()
+	tables := sfntTables(t, ttf)
+	tables[tag] = data
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	res := make([]byte, 12+16*len(tags))
+	copy(res, ttf[:4])
+	binary.BigEndian.PutUint16(res[4:], uint16(len(tags)))
+	for i, tag := range tags {
+		rec := res[12+16*i:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(res)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(tables[tag])))
+		res = append(res, tables[tag]...)
+		for len(res)%4 != 0 {
+			res = append(res, 0)
+		}
+	}
+	return res
+}
+
+// kernTableData returns a 'kern' table with one horizontal format 0 subtable in the Microsoft or Apple variant
+func kernTableData(apple bool, left, right GlyphIndex, value int16) []byte {
+	pairs := []uint16{1, 6, 0, 0, uint16(left), uint16(right), uint16(value)}
+	var d []uint16
+	if apple {
+		d = append([]uint16{1, 0, 0, 1, 0, uint16(8 + 2*len(pairs)), 0, 0}, pairs...)
+	} else {
+		d = append([]uint16{0, 1, 0, uint16(6 + 2*len(pairs)), 0x0001}, pairs...)
+	}
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, d)
+	return buf.Bytes()
+}
+
+func TestKernTable(t *testing.T) {
+	plain, err := Parse(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	indices := plain.LookupRunes([]rune("AV"))
+
+	for _, apple := range []bool{false, true} {
+		// -205 units at 2048 units per em
+		ttf := addTable(t, goregular.TTF, "kern", kernTableData(apple, indices[0], indices[1], -205))
+		fnt, err := Parse(bytes.NewReader(ttf))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fnt.GetKerning(indices[0], indices[1]); got != -100 {
+			t.Errorf("apple %v: got kerning %d, want -100", apple, got)
+		}
+		if got := fnt.GetKerning(indices[1], indices[0]); got != 0 {
+			t.Errorf("apple %v: got kerning %d for reversed pair, want 0", apple, got)
+		}
+
+		glyphs := fnt.Shape([]rune("AV"), "kern")
+		if len(glyphs) != 2 {
+			t.Fatalf("apple %v: got %d glyphs, want 2", apple, len(glyphs))
+		}
+		if got, want := glyphs[0].Advance, fnt.GetGlyphAdvance(indices[0])-100; got != want {
+			t.Errorf("apple %v: got advance %d, want %d", apple, got, want)
+		}
+		if got, want := fnt.Shape([]rune("AV"))[0].Advance, fnt.GetGlyphAdvance(indices[0]); got != want {
+			t.Errorf("apple %v: got advance %d without kerning, want %d", apple, got, want)
+		}
+	}
+}
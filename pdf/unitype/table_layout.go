@@ -0,0 +1,278 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+import (
+	"errors"
+	"sort"
+)
+
+// layoutTable represents the common structure of the GSUB and GPOS tables: script list, feature list and
+// lookup list. The table data is kept in memory, subtables are interpreted when the lookups are applied.
+// https://docs.microsoft.com/en-us/typography/opentype/spec/chapter2
+type layoutTable struct {
+	data     []byte
+	scripts  map[string][]uint16 // script tag -> feature indices of the default language system
+	features []layoutFeature
+	lookups  []layoutLookup
+}
+
+// layoutFeature is a feature record with the indices of the lookups of the feature
+type layoutFeature struct {
+	tag     string
+	lookups []uint16
+}
+
+// layoutLookup is a lookup table with the absolute offsets of its subtables. Extension subtables are resolved
+// already, so lookupType is the type of the actual subtables.
+type layoutLookup struct {
+	lookupType uint16
+	flag       uint16
+	subtables  []int
+}
+
+var errLayoutTable = errors.New("layout table invalid")
+
+// layoutData provides bounds checked reading of big endian values
+type layoutData []byte
+
+func (d layoutData) u16(offset int) uint16 {
+	if offset < 0 || offset+2 > len(d) {
+		return 0
+	}
+	return uint16(d[offset])<<8 | uint16(d[offset+1])
+}
+
+func (d layoutData) i16(offset int) int16 {
+	return int16(d.u16(offset))
+}
+
+func (d layoutData) u32(offset int) uint32 {
+	if offset < 0 || offset+4 > len(d) {
+		return 0
+	}
+	return uint32(d[offset])<<24 | uint32(d[offset+1])<<16 | uint32(d[offset+2])<<8 | uint32(d[offset+3])
+}
+
+func (d layoutData) tag(offset int) string {
+	if offset < 0 || offset+4 > len(d) {
+		return ""
+	}
+	return tag{d[offset], d[offset+1], d[offset+2], d[offset+3]}.String()
+}
+
+// parseLayoutTable reads the table with the given name. extensionType is the lookup type of extension subtables
+// (7 for GSUB, 9 for GPOS).
+func (f *font) parseLayoutTable(r *byteReader, name string, extensionType uint16) (*layoutTable, error) {
+	tr, has, err := f.seekToTable(r, name)
+	if err != nil {
+		return nil, err
+	}
+	if !has || tr == nil {
+		return nil, nil
+	}
+
+	t := &layoutTable{}
+	if err := r.readBytes(&t.data, int(tr.length)); err != nil {
+		return nil, err
+	}
+	d := layoutData(t.data)
+	if len(d) < 10 || d.u16(0) != 1 {
+		return nil, errLayoutTable
+	}
+	scriptListOffset := int(d.u16(4))
+	featureListOffset := int(d.u16(6))
+	lookupListOffset := int(d.u16(8))
+
+	// script list: only the default language system of each script is used
+	t.scripts = make(map[string][]uint16)
+	if scriptListOffset != 0 {
+		count := int(d.u16(scriptListOffset))
+		for i := 0; i < count; i++ {
+			rec := scriptListOffset + 2 + i*6
+			scriptOffset := scriptListOffset + int(d.u16(rec+4))
+			langSysOffset := int(d.u16(scriptOffset))
+			if langSysOffset == 0 {
+				// no default language system: use the first one
+				if d.u16(scriptOffset+2) == 0 {
+					continue
+				}
+				langSysOffset = int(d.u16(scriptOffset + 4 + 4))
+			}
+			langSysOffset += scriptOffset
+
+			var indices []uint16
+			if req := d.u16(langSysOffset + 2); req != 0xFFFF {
+				indices = append(indices, req)
+			}
+			n := int(d.u16(langSysOffset + 4))
+			for j := 0; j < n; j++ {
+				indices = append(indices, d.u16(langSysOffset+6+j*2))
+			}
+			t.scripts[d.tag(rec)] = indices
+		}
+	}
+
+	// feature list
+	if featureListOffset != 0 {
+		count := int(d.u16(featureListOffset))
+		for i := 0; i < count; i++ {
+			rec := featureListOffset + 2 + i*6
+			featureOffset := featureListOffset + int(d.u16(rec+4))
+			feature := layoutFeature{tag: d.tag(rec)}
+			n := int(d.u16(featureOffset + 2))
+			for j := 0; j < n; j++ {
+				feature.lookups = append(feature.lookups, d.u16(featureOffset+4+j*2))
+			}
+			t.features = append(t.features, feature)
+		}
+	}
+
+	// lookup list
+	if lookupListOffset != 0 {
+		count := int(d.u16(lookupListOffset))
+		for i := 0; i < count; i++ {
+			lookupOffset := lookupListOffset + int(d.u16(lookupListOffset+2+i*2))
+			lookup := layoutLookup{
+				lookupType: d.u16(lookupOffset),
+				flag:       d.u16(lookupOffset + 2),
+			}
+			n := int(d.u16(lookupOffset + 4))
+			for j := 0; j < n; j++ {
+				subtableOffset := lookupOffset + int(d.u16(lookupOffset+6+j*2))
+				if lookup.lookupType == extensionType {
+					// extension subtable: format, extensionLookupType, extensionOffset32
+					lookup.lookupType = d.u16(subtableOffset + 2)
+					subtableOffset += int(d.u32(subtableOffset + 4))
+				}
+				lookup.subtables = append(lookup.subtables, subtableOffset)
+			}
+			t.lookups = append(t.lookups, lookup)
+		}
+	}
+
+	return t, nil
+}
+
+// selectLookups returns the indices of the lookups of the given features for the given script in the order they
+// need to be applied
+func (t *layoutTable) selectLookups(script string, features map[string]struct{}) []int {
+	if t == nil {
+		return nil
+	}
+
+	// determine feature indices of the script, fall back to the default script
+	indices, ok := t.scripts[script]
+	if !ok {
+		indices, ok = t.scripts["DFLT"]
+	}
+	if !ok {
+		indices, ok = t.scripts["latn"]
+	}
+	if !ok {
+		var tags []string
+		for k := range t.scripts {
+			tags = append(tags, k)
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		sort.Strings(tags)
+		indices = t.scripts[tags[0]]
+	}
+
+	// collect lookups, lookups are applied in the order of the lookup list
+	unique := make(map[int]struct{})
+	var res []int
+	for _, fi := range indices {
+		if int(fi) >= len(t.features) {
+			continue
+		}
+		feature := t.features[fi]
+		if _, ok := features[feature.tag]; !ok {
+			continue
+		}
+		for _, li := range feature.lookups {
+			if int(li) >= len(t.lookups) {
+				continue
+			}
+			if _, ok := unique[int(li)]; ok {
+				continue
+			}
+			unique[int(li)] = struct{}{}
+			res = append(res, int(li))
+		}
+	}
+	sort.Ints(res)
+	return res
+}
+
+// featureTags returns the tags of all features of the table
+func (t *layoutTable) featureTags() []string {
+	if t == nil {
+		return nil
+	}
+	var res []string
+	for _, f := range t.features {
+		res = append(res, f.tag)
+	}
+	return res
+}
+
+// coverageIndex returns the coverage index of the glyph in the coverage table at the given offset, or -1 if the
+// glyph is not covered
+func (d layoutData) coverageIndex(offset int, gid GlyphIndex) int {
+	switch d.u16(offset) {
+	case 1:
+		// sorted glyph array
+		count := int(d.u16(offset + 2))
+		i := sort.Search(count, func(i int) bool {
+			return GlyphIndex(d.u16(offset+4+i*2)) >= gid
+		})
+		if i < count && GlyphIndex(d.u16(offset+4+i*2)) == gid {
+			return i
+		}
+	case 2:
+		// sorted range records: startGlyphID, endGlyphID, startCoverageIndex
+		count := int(d.u16(offset + 2))
+		i := sort.Search(count, func(i int) bool {
+			return GlyphIndex(d.u16(offset+4+i*6+2)) >= gid
+		})
+		if i < count {
+			rec := offset + 4 + i*6
+			start := GlyphIndex(d.u16(rec))
+			if gid >= start {
+				return int(d.u16(rec+4)) + int(gid-start)
+			}
+		}
+	}
+	return -1
+}
+
+// glyphClass returns the class of the glyph in the class definition table at the given offset
+func (d layoutData) glyphClass(offset int, gid GlyphIndex) int {
+	switch d.u16(offset) {
+	case 1:
+		start := GlyphIndex(d.u16(offset + 2))
+		count := int(d.u16(offset + 4))
+		if gid >= start && int(gid-start) < count {
+			return int(d.u16(offset + 6 + int(gid-start)*2))
+		}
+	case 2:
+		// sorted range records: startGlyphID, endGlyphID, class
+		count := int(d.u16(offset + 2))
+		i := sort.Search(count, func(i int) bool {
+			return GlyphIndex(d.u16(offset+4+i*6+2)) >= gid
+		})
+		if i < count {
+			rec := offset + 4 + i*6
+			if gid >= GlyphIndex(d.u16(rec)) {
+				return int(d.u16(rec + 4))
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+// vheaTable represents the vertical header table (vhea).
+// This table contains information for vertical layout.
+// https://docs.microsoft.com/en-us/typography/opentype/spec/vhea
+type vheaTable struct {
+	majorVersion         uint16
+	minorVersion         uint16
+	vertTypoAscender     fword
+	vertTypoDescender    fword
+	vertTypoLineGap      fword
+	advanceHeightMax     ufword
+	minTopSideBearing    fword
+	minBottomSideBearing fword
+	yMaxExtent           fword
+	caretSlopeRise       int16
+	caretSlopeRun        int16
+	caretOffset          int16
+	metricDataFormat     int16
+	numOfLongVerMetrics  uint16 // Number of vMetric entries in 'vmtx' table.
+}
+
+func (f *font) parseVhea(r *byteReader) (*vheaTable, error) {
+	_, has, err := f.seekToTable(r, "vhea")
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, nil
+	}
+
+	t := &vheaTable{}
+	err = r.read(&t.majorVersion, &t.minorVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.read(&t.vertTypoAscender, &t.vertTypoDescender, &t.vertTypoLineGap)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.read(&t.advanceHeightMax, &t.minTopSideBearing, &t.minBottomSideBearing, &t.yMaxExtent)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.read(&t.caretSlopeRise, &t.caretSlopeRun, &t.caretOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip over reserved bytes.
+	err = r.Skip(4 * 2)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, r.read(&t.metricDataFormat, &t.numOfLongVerMetrics)
+}
@@ -0,0 +1,71 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+// vmtxTable represents the vertical metrics table (vmtx).
+// https://docs.microsoft.com/en-us/typography/opentype/spec/vmtx
+type vmtxTable struct {
+	vMetrics        []longVerMetric // length is numOfLongVerMetrics from vhea table.
+	topSideBearings []int16         // length is (numGlyphs - numOfLongVerMetrics) from maxp and vhea tables.
+}
+
+type longVerMetric struct {
+	advanceHeight uint16
+	tsb           int16
+}
+
+func (f *font) parseVmtx(r *byteReader) (*vmtxTable, error) {
+	if f.maxp == nil || f.vhea == nil {
+		return nil, nil
+	}
+
+	_, has, err := f.seekToTable(r, "vmtx")
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, nil
+	}
+
+	t := &vmtxTable{}
+
+	numOfLongVerMetrics := int(f.vhea.numOfLongVerMetrics)
+	for i := 0; i < numOfLongVerMetrics; i++ {
+		var lvm longVerMetric
+		err := r.read(&lvm.advanceHeight, &lvm.tsb)
+		if err != nil {
+			return nil, err
+		}
+
+		t.vMetrics = append(t.vMetrics, lvm)
+	}
+
+	tsbLen := int(f.maxp.numGlyphs) - numOfLongVerMetrics
+	if tsbLen > 0 {
+		err = r.readSlice(&t.topSideBearings, tsbLen)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// verticalMetrics returns the advance height and top side bearing of the glyph in font units
+func (t *vmtxTable) verticalMetrics(gid GlyphIndex) (int, int, bool) {
+	if t == nil || len(t.vMetrics) == 0 || gid < 0 {
+		return 0, 0, false
+	}
+	if int(gid) < len(t.vMetrics) {
+		m := t.vMetrics[gid]
+		return int(m.advanceHeight), int(m.tsb), true
+	}
+	advance := int(t.vMetrics[len(t.vMetrics)-1].advanceHeight)
+	if i := int(gid) - len(t.vMetrics); i < len(t.topSideBearings) {
+		return advance, int(t.topSideBearings[i]), true
+	}
+	return advance, 0, true
+}
@@ -76,6 +76,11 @@ func (f fixed) Float64() float64 {
 	return integral + fraction
 }
 
+// Float64 returns `f` as a float64.
+func (f f2dot14) Float64() float64 {
+	return float64(f) / 16384
+}
+
 func makeTag(s string) tag {
 	bb := []byte(s[:])
 	if len(bb) > 4 {
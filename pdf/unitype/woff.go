@@ -0,0 +1,103 @@
+/*
+ * This pdffile is subject to the terms and conditions defined in
+ * pdffile 'LICENSE.md', which is part of this source code package.
+ */
+
+package unitype
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// WOFF files (web fonts) contain the tables of a TrueType or OpenType font, each compressed by zlib. They are decoded
+// to sfnt font files to be used like other fonts.
+// https://www.w3.org/TR/WOFF/
+
+// IsWOFF checks if the data is a WOFF file
+func IsWOFF(b []byte) bool {
+	return len(b) >= 4 && string(b[:4]) == "wOFF"
+}
+
+// DecodeWOFF decodes a WOFF file to a TrueType or OpenType font file
+func DecodeWOFF(b []byte) ([]byte, error) {
+	// header
+	if !IsWOFF(b) {
+		if len(b) >= 4 && string(b[:4]) == "wOF2" {
+			return nil, errors.New("WOFF2 fonts are not supported")
+		}
+		return nil, errors.New("not a WOFF file")
+	}
+	if len(b) < 44 {
+		return nil, errors.New("invalid WOFF file: header truncated")
+	}
+	flavor := binary.BigEndian.Uint32(b[4:])
+	length := int(binary.BigEndian.Uint32(b[8:]))
+	numTables := int(binary.BigEndian.Uint16(b[12:]))
+	if length != len(b) {
+		return nil, errors.New("invalid WOFF file: length does not match file size")
+	}
+	if binary.BigEndian.Uint16(b[14:]) != 0 {
+		return nil, errors.New("invalid WOFF file: reserved field not zero")
+	}
+	if numTables == 0 || 44+20*numTables > len(b) {
+		return nil, errors.New("invalid WOFF file: table directory truncated")
+	}
+
+	// offset table
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+	res := make([]byte, 12+16*numTables)
+	binary.BigEndian.PutUint32(res[0:], flavor)
+	binary.BigEndian.PutUint16(res[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(res[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(res[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(res[10:], uint16(16*numTables-searchRange))
+
+	// table records and decompressed tables, 4-byte aligned
+	for i := 0; i < numTables; i++ {
+		entry := b[44+20*i:]
+		tag := string(entry[:4])
+		offset := int(binary.BigEndian.Uint32(entry[4:]))
+		compLength := int(binary.BigEndian.Uint32(entry[8:]))
+		origLength := int(binary.BigEndian.Uint32(entry[12:]))
+		if offset < 0 || compLength < 0 || offset+compLength > len(b) {
+			return nil, errors.New("invalid WOFF file: table " + tag + " out of range")
+		}
+		if compLength > origLength {
+			return nil, errors.New("invalid WOFF file: compressed length of table " + tag + " exceeds original length")
+		}
+
+		data := b[offset : offset+compLength]
+		if compLength < origLength {
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, errors.New("invalid WOFF file: table " + tag + ": " + err.Error())
+			}
+			data, err = io.ReadAll(io.LimitReader(zr, int64(origLength)+1))
+			if err != nil {
+				return nil, errors.New("invalid WOFF file: table " + tag + ": " + err.Error())
+			}
+			if len(data) != origLength {
+				return nil, errors.New("invalid WOFF file: decompressed length of table " + tag + " does not match")
+			}
+		}
+
+		rec := res[12+16*i:]
+		copy(rec, entry[:4])
+		copy(rec[4:], entry[16:20])
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(res)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(origLength))
+		res = append(res, data...)
+		for len(res)%4 != 0 {
+			res = append(res, 0)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,190 @@
+package unitype
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// sfntTables returns the tables of a TrueType font file by tag
+func sfntTables(t *testing.T, b []byte) map[string][]byte {
+	t.Helper()
+	numTables := int(binary.BigEndian.Uint16(b[4:]))
+	res := make(map[string][]byte, numTables)
+	for i := 0; i < numTables; i++ {
+		rec := b[12+16*i:]
+		offset := binary.BigEndian.Uint32(rec[8:])
+		length := binary.BigEndian.Uint32(rec[12:])
+		if int(offset+length) > len(b) {
+			t.Fatalf("table %s out of range", rec[:4])
+		}
+		res[string(rec[:4])] = b[offset : offset+length]
+	}
+	return res
+}
+
+// encodeWOFF converts a TrueType font file to a WOFF file. Tables are stored compressed if this makes them smaller.
+func encodeWOFF(t *testing.T, ttf []byte) []byte {
+	t.Helper()
+	numTables := int(binary.BigEndian.Uint16(ttf[4:]))
+	dir := make([]byte, 44+20*numTables)
+	var data []byte
+	for i := 0; i < numTables; i++ {
+		rec := ttf[12+16*i:]
+		offset := binary.BigEndian.Uint32(rec[8:])
+		length := binary.BigEndian.Uint32(rec[12:])
+		table := ttf[offset : offset+length]
+
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		if _, err := zw.Write(table); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		stored := table
+		if buf.Len() < len(table) {
+			stored = buf.Bytes()
+		}
+
+		entry := dir[44+20*i:]
+		copy(entry, rec[:4])
+		binary.BigEndian.PutUint32(entry[4:], uint32(len(dir)+len(data)))
+		binary.BigEndian.PutUint32(entry[8:], uint32(len(stored)))
+		binary.BigEndian.PutUint32(entry[12:], length)
+		copy(entry[16:], rec[4:8])
+		data = append(data, stored...)
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+	}
+
+	copy(dir, "wOFF")
+	copy(dir[4:], ttf[:4])
+	binary.BigEndian.PutUint32(dir[8:], uint32(len(dir)+len(data)))
+	binary.BigEndian.PutUint16(dir[12:], uint16(numTables))
+	return append(dir, data...)
+}
+
+func TestDecodeWOFF(t *testing.T) {
+	woff := encodeWOFF(t, goregular.TTF)
+	if !IsWOFF(woff) {
+		t.Fatal("encoded font not detected as WOFF")
+	}
+	if IsWOFF(goregular.TTF) {
+		t.Fatal("TrueType font detected as WOFF")
+	}
+
+	ttf, err := DecodeWOFF(woff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ttf[:4], goregular.TTF[:4]) {
+		t.Errorf("flavor: got %x, want %x", ttf[:4], goregular.TTF[:4])
+	}
+	if len(ttf)%4 != 0 {
+		t.Errorf("length %d not 4-byte aligned", len(ttf))
+	}
+	got := sfntTables(t, ttf)
+	want := sfntTables(t, goregular.TTF)
+	if len(got) != len(want) {
+		t.Errorf("got %d tables, want %d", len(got), len(want))
+	}
+	for tag, w := range want {
+		if !bytes.Equal(got[tag], w) {
+			t.Errorf("table %s differs", tag)
+		}
+	}
+
+	fnt, err := Parse(bytes.NewReader(ttf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := Parse(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fnt.GetCmap(3, 1)['A'], orig.GetCmap(3, 1)['A']; g == 0 || g != w {
+		t.Errorf("glyph of A: got %d, want %d", g, w)
+	}
+
+	faces, err := ParseFaces(woff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(faces) != 1 {
+		t.Errorf("got %d faces, want 1", len(faces))
+	}
+}
+
+func TestDecodeWOFFErrors(t *testing.T) {
+	woff := encodeWOFF(t, goregular.TTF)
+	numTables := int(binary.BigEndian.Uint16(woff[12:]))
+
+	// index of a compressed table
+	compressed := -1
+	for i := 0; i < numTables; i++ {
+		entry := woff[44+20*i:]
+		if binary.BigEndian.Uint32(entry[8:]) < binary.BigEndian.Uint32(entry[12:]) {
+			compressed = i
+			break
+		}
+	}
+	if compressed < 0 {
+		t.Fatal("no compressed table")
+	}
+
+	modified := func(f func(b []byte) []byte) []byte {
+		return f(append([]byte(nil), woff...))
+	}
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"truetype", goregular.TTF, "not a WOFF file"},
+		{"woff2", []byte("wOF2\x00\x01\x00\x00"), "WOFF2 fonts are not supported"},
+		{"header", woff[:40], "header truncated"},
+		{"length", woff[:len(woff)-4], "length does not match"},
+		{"reserved", modified(func(b []byte) []byte {
+			b[15] = 1
+			return b
+		}), "reserved field not zero"},
+		{"directory", modified(func(b []byte) []byte {
+			binary.BigEndian.PutUint16(b[12:], 0xFFFF)
+			return b
+		}), "table directory truncated"},
+		{"range", modified(func(b []byte) []byte {
+			binary.BigEndian.PutUint32(b[44+4:], uint32(len(b)))
+			return b
+		}), "out of range"},
+		{"compressed length", modified(func(b []byte) []byte {
+			entry := b[44+20*compressed:]
+			binary.BigEndian.PutUint32(entry[12:], binary.BigEndian.Uint32(entry[8:])-1)
+			return b
+		}), "exceeds original length"},
+		{"zlib", modified(func(b []byte) []byte {
+			entry := b[44+20*compressed:]
+			b[binary.BigEndian.Uint32(entry[4:])] = 0
+			return b
+		}), "table " + string(woff[44+20*compressed:][:4])},
+		{"decompressed length", modified(func(b []byte) []byte {
+			entry := b[44+20*compressed:]
+			binary.BigEndian.PutUint32(entry[12:], binary.BigEndian.Uint32(entry[12:])+1)
+			return b
+		}), "decompressed length"},
+	}
+	for _, tt := range tests {
+		_, err := DecodeWOFF(tt.data)
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+		} else if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: got error %q, want %q", tt.name, err, tt.err)
+		}
+	}
+}
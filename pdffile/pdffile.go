@@ -3,6 +3,7 @@ package pdffile
 import (
 	"errors"
 	"io"
+	"sort"
 	"strconv"
 
 	"github.com/raceresult/gopdf/types"
@@ -18,6 +19,7 @@ type File struct {
 	ID              [2]types.String
 	objects         []types.IndirectObject
 	objectsIndexMap map[int][]int
+	nextNumber      int
 }
 
 // NewFile creates a new File object
@@ -29,12 +31,11 @@ func NewFile() *File {
 
 // AddObject adds an object to the file and returns its reference
 func (q *File) AddObject(obj types.Object) types.Reference {
-	var no int
-	if len(q.objects) != 0 {
-		no = q.objects[len(q.objects)-1].Number + 1
-	} else {
+	no := q.nextNumber
+	if no < 1 {
 		no = 1
 	}
+	q.nextNumber = no + 1
 
 	q.objects = append(q.objects, types.IndirectObject{
 		Number:     no,
@@ -42,7 +43,10 @@ func (q *File) AddObject(obj types.Object) types.Reference {
 		Data:       obj,
 	})
 
-	q.objectsIndexMap = nil
+	// the number is new, so the object map can be updated instead of rebuilt
+	if q.objectsIndexMap != nil {
+		q.objectsIndexMap[no] = []int{len(q.objects) - 1}
+	}
 	return types.Reference{
 		Number:     no,
 		Generation: 0,
@@ -51,6 +55,9 @@ func (q *File) AddObject(obj types.Object) types.Reference {
 
 // AddIndirectObject adds an indirect object (only used by pdf parser)
 func (q *File) AddIndirectObject(obj types.IndirectObject) {
+	if q.nextNumber <= obj.Number {
+		q.nextNumber = obj.Number + 1
+	}
 	q.objects = append(q.objects, obj)
 	q.objectsIndexMap = nil
 }
@@ -78,6 +85,77 @@ func (q *File) GetObject(ref types.Reference) (types.Object, error) {
 	return q.objects[items[ref.Generation]].Data, nil
 }
 
+// SetObject replaces the object with the given reference, e.g. an object added by AddObject as placeholder to reserve
+// its number
+func (q *File) SetObject(ref types.Reference, obj types.Object) error {
+	if _, err := q.GetObject(ref); err != nil {
+		return err
+	}
+	q.objects[q.objectsIndexMap[ref.Number][ref.Generation]].Data = obj
+	return nil
+}
+
+// NextNumber returns the object number that will be assigned to the next object added by AddObject
+func (q *File) NextNumber() int {
+	if q.nextNumber < 1 {
+		return 1
+	}
+	return q.nextNumber
+}
+
+// Clean prepares a parsed file for being written again: if an object occurs several times with the same number and
+// generation (e.g. in files with incremental updates), only the object returned by GetObject is kept; of several
+// generations of an object number, only the newest one is kept, since the cross-reference table has one entry per
+// number. Object streams and cross-reference streams are removed, since the parser has already unpacked the objects
+// contained in them. The objects are sorted by number.
+func (q *File) Clean() {
+	// determine which objects to keep
+	keep := make(map[types.Reference]types.IndirectObject)
+	newest := make(map[int]int)
+	for _, obj := range q.objects {
+		if s, ok := obj.Data.(types.StreamObject); ok {
+			if dict, ok := s.Dictionary.(types.Dictionary); ok {
+				if typ, _ := dict["Type"].(types.Name); typ == "ObjStm" || typ == "XRef" {
+					continue
+				}
+			}
+		}
+		keep[types.Reference{Number: obj.Number, Generation: obj.Generation}] = obj
+		if gen, ok := newest[obj.Number]; !ok || gen < obj.Generation {
+			newest[obj.Number] = obj.Generation
+		}
+	}
+
+	// rebuild list
+	q.objects = q.objects[:0]
+	for ref, obj := range keep {
+		if newest[ref.Number] == ref.Generation {
+			q.objects = append(q.objects, obj)
+		}
+	}
+	sort.Slice(q.objects, func(i, j int) bool { return q.objects[i].Number < q.objects[j].Number })
+	q.objectsIndexMap = nil
+}
+
+// RemoveObjects removes the objects with the given references, e.g. objects added but not used in the end
+func (q *File) RemoveObjects(refs []types.Reference) {
+	if len(refs) == 0 {
+		return
+	}
+	remove := make(map[types.Reference]struct{}, len(refs))
+	for _, ref := range refs {
+		remove[ref] = struct{}{}
+	}
+	objects := q.objects[:0]
+	for _, obj := range q.objects {
+		if _, ok := remove[types.Reference{Number: obj.Number, Generation: obj.Generation}]; !ok {
+			objects = append(objects, obj)
+		}
+	}
+	q.objects = objects
+	q.objectsIndexMap = nil
+}
+
 // GetObjects returns all objects
 func (q *File) GetObjects() []types.IndirectObject {
 	return q.objects
@@ -0,0 +1,36 @@
+package pdffile
+
+import (
+	"testing"
+
+	"github.com/raceresult/gopdf/types"
+)
+
+func TestClean(t *testing.T) {
+	f := NewFile()
+	f.AddIndirectObject(types.IndirectObject{Number: 1, Generation: 0, Data: types.Int(1)})
+	f.AddIndirectObject(types.IndirectObject{Number: 2, Generation: 1, Data: types.Int(3)})
+	f.AddIndirectObject(types.IndirectObject{Number: 2, Generation: 0, Data: types.Int(2)})
+	f.AddIndirectObject(types.IndirectObject{Number: 1, Generation: 0, Data: types.Int(4)}) // incremental update
+	f.Clean()
+
+	objects := f.GetObjects()
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objects))
+	}
+	for _, c := range []struct {
+		ref  types.Reference
+		data types.Int
+	}{
+		{types.Reference{Number: 1}, 4},
+		{types.Reference{Number: 2, Generation: 1}, 3},
+	} {
+		obj, err := f.GetObject(c.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if obj != c.data {
+			t.Errorf("object %d %d: expected %v, got %v", c.ref.Number, c.ref.Generation, c.data, obj)
+		}
+	}
+}
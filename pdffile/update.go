@@ -1,7 +1,11 @@
 package pdffile
 
 import (
+	"bytes"
+	"errors"
 	"io"
+	"sort"
+	"strconv"
 
 	"github.com/raceresult/gopdf/types"
 )
@@ -11,6 +15,9 @@ type Update struct {
 	Objects   []types.RawIndirectObject
 	xRefTable XRefTable
 	Trailer   types.Trailer
+
+	// object number of the cross-reference stream (PDF 1.5), 0 to write a cross-reference table and trailer
+	xRefStream int
 }
 
 func (q *Update) writeTo(w io.Writer, offset int64) (int64, int64, error) {
@@ -31,8 +38,15 @@ func (q *Update) writeTo(w io.Writer, offset int64) (int64, int64, error) {
 		}
 	}
 
-	// xref table
+	// xref stream
 	startXRef := n + offset
+	if q.xRefStream != 0 {
+		nn, err := q.writeXRefStreamTo(w, startXRef)
+		n += nn
+		return n, startXRef, err
+	}
+
+	// xref table
 	nn, err := w.Write(q.xRefTable.ToRawBytes())
 	if err != nil {
 		return n, startXRef, err
@@ -49,3 +63,136 @@ func (q *Update) writeTo(w io.Writer, offset int64) (int64, int64, error) {
 	// return without error
 	return n, startXRef, nil
 }
+
+// writeXRefStreamTo writes the cross-reference table and the trailer as cross-reference stream (PDF Reference 1.6,
+// 3.4.7 Cross-Reference Streams), the stream itself being the last entry
+func (q *Update) writeXRefStreamTo(w io.Writer, pos int64) (int64, error) {
+	q.xRefTable.add(q.xRefStream, 0, false, pos)
+	if q.xRefStream+1 > q.Trailer.Size {
+		q.Trailer.Size = q.xRefStream + 1
+	}
+
+	// field widths: type, offset or object stream number, generation or index
+	offsetWidth := 1
+	for _, section := range q.xRefTable {
+		for _, entry := range section.Entries {
+			for entry.Start>>(8*offsetWidth) != 0 {
+				offsetWidth++
+			}
+		}
+	}
+	widths := [3]int{1, offsetWidth, 2}
+
+	// entries
+	var data []byte
+	var index types.Array
+	for _, section := range q.xRefTable {
+		index = append(index, types.Int(section.Start), types.Int(section.Count))
+		for _, entry := range section.Entries {
+			entryType := int64(1)
+			if entry.Free {
+				entryType = 0
+			}
+			for i, v := range [3]int64{entryType, entry.Start, int64(entry.Generation)} {
+				for b := widths[i] - 1; b >= 0; b-- {
+					data = append(data, byte(v>>(8*b)))
+				}
+			}
+		}
+	}
+	stream, err := types.NewStream(data, types.Filter_FlateDecode)
+	if err != nil {
+		return 0, err
+	}
+
+	// dictionary
+	dict := q.Trailer.ToDictionary()
+	dict["Type"] = types.Name("XRef")
+	dict["W"] = types.Array{types.Int(widths[0]), types.Int(widths[1]), types.Int(widths[2])}
+	dict["Index"] = index
+	dict["Length"] = types.Int(len(stream.Stream))
+	dict["Filter"] = types.Filter_FlateDecode
+	stream.Dictionary = dict
+
+	nn, err := w.Write(types.RawIndirectObject{
+		Number: q.xRefStream,
+		Data:   stream.ToRawBytes(),
+	}.ToRawBytes())
+	return int64(nn), err
+}
+
+// WriteUpdateTo writes the original file bytes followed by an incremental update containing the objects with the
+// given references. The objects are taken from this file, which usually is the parsed original file with modified
+// and added objects.
+func (q *File) WriteUpdateTo(w io.Writer, original []byte, refs []types.Reference) (int64, error) {
+	// find start of last cross-reference section of original file
+	pos := bytes.LastIndex(original, []byte("startxref"))
+	if pos < 0 {
+		return 0, errors.New("startxref not found")
+	}
+	fields := bytes.Fields(original[pos+9:])
+	if len(fields) == 0 {
+		return 0, errors.New("startxref value invalid")
+	}
+	prev, err := strconv.ParseInt(string(fields[0]), 10, 64)
+	if err != nil || prev < 0 || prev >= int64(len(original)) {
+		return 0, errors.New("startxref value invalid")
+	}
+
+	// build raw objects
+	var u Update
+	if !bytes.HasPrefix(bytes.TrimLeft(original[prev:], " \t\r\n"), []byte("xref")) {
+		// the last section of the original is a cross-reference stream: write the update as stream as well, readers
+		// following the Prev chain may not accept a table pointing to a stream
+		u.xRefStream = q.NextNumber()
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Number < refs[j].Number })
+	for _, ref := range refs {
+		obj, err := q.GetObject(ref)
+		if err != nil {
+			return 0, err
+		}
+		u.Objects = append(u.Objects, types.RawIndirectObject{
+			Number:     ref.Number,
+			Generation: ref.Generation,
+			Data:       obj.ToRawBytes(),
+		})
+	}
+	u.Trailer = types.Trailer{
+		Size: q.NextNumber(),
+		Prev: types.Int(prev),
+		Root: q.Root,
+		Info: q.Info,
+		ID:   [2]types.String{q.ID[0], q.ID[1]},
+	}
+
+	// original file
+	var n int64
+	n1, err := w.Write(original)
+	if err != nil {
+		return n, err
+	}
+	n += int64(n1)
+	if len(original) != 0 && original[len(original)-1] != '\n' {
+		n1, err = w.Write([]byte{'\n'})
+		if err != nil {
+			return n, err
+		}
+		n += int64(n1)
+	}
+
+	// update
+	nn, startXRef, err := u.writeTo(w, n)
+	if err != nil {
+		return n, err
+	}
+	n += nn
+
+	// footer
+	n1, err = w.Write([]byte("startxref\n" + strconv.FormatInt(startXRef, 10) + "\n" + "%%EOF\n"))
+	if err != nil {
+		return n, err
+	}
+	n += int64(n1)
+	return n, nil
+}
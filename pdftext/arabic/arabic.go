@@ -239,7 +239,13 @@ func contains(s []string, str string) bool {
 	return false
 }
 
-// Shape will reconstruct arabic text to be connected correctly
+// GlyphChecker reports which runes can be displayed, e.g. by a pdf.FontHandler
+type GlyphChecker interface {
+	HasGylph(runes []rune) []bool
+}
+
+// Shape will reconstruct arabic text to be connected correctly. The words are returned in visual order (reversed),
+// for text in logical order to be reordered by the bidirectional algorithm see ShapeWith.
 func Shape(input string, font pdf.FontHandler) string {
 	var foundArabic bool
 	for _, letter := range input {
@@ -282,7 +288,7 @@ func Shape(input string, font pdf.FontHandler) string {
 	for _, section := range langSections {
 		if IsArabic(section) {
 			for _, word := range strings.Fields(section) {
-				shapedSentence = append(shapedSentence, shapeWord(word, font))
+				shapedSentence = append(shapedSentence, reverse(shapeWord(word, font)))
 			}
 		} else {
 			shapedSentence = append(shapedSentence, section)
@@ -296,8 +302,42 @@ func Shape(input string, font pdf.FontHandler) string {
 	return strings.Join(shapedSentence, " ")
 }
 
+// ShapeWith reconstructs arabic text to be connected correctly like Shape, but keeps the text in logical order, so
+// that it can be reordered by the bidirectional algorithm. Only letter forms the given glyph checker, e.g. a chain of
+// fallback fonts, has glyphs for are used.
+func ShapeWith(input string, font GlyphChecker) string {
+	var foundArabic bool
+	for _, letter := range input {
+		if IsArabicLetter(letter) {
+			foundArabic = true
+			break
+		}
+	}
+	if !foundArabic {
+		return input
+	}
+
+	var shapedSentence strings.Builder
+	var word strings.Builder
+	for _, letter := range input {
+		if IsArabicLetter(letter) {
+			word.WriteRune(letter)
+			continue
+		}
+		if word.Len() > 0 {
+			shapedSentence.WriteString(shapeWord(word.String(), font))
+			word.Reset()
+		}
+		shapedSentence.WriteRune(letter)
+	}
+	if word.Len() > 0 {
+		shapedSentence.WriteString(shapeWord(word.String(), font))
+	}
+	return shapedSentence.String()
+}
+
 // shapeWord will reconstruct an arabic word to be connected correctly
-func shapeWord(input string, font pdf.FontHandler) string {
+func shapeWord(input string, font GlyphChecker) string {
 	if !IsArabic(input) {
 		return input
 	}
@@ -330,7 +370,7 @@ func shapeWord(input string, font pdf.FontHandler) string {
 
 	//In case no Tashkeel deteted, same size of runes
 	if len([]rune(shapedInput.String())) == len([]rune(input)) {
-		return reverse(shapedInput.String())
+		return shapedInput.String()
 	}
 
 	var shapedInputTashkeel bytes.Buffer
@@ -352,7 +392,7 @@ func shapeWord(input string, font pdf.FontHandler) string {
 		}
 	}
 
-	return reverse(shapedInputTashkeel.String())
+	return shapedInputTashkeel.String()
 }
 
 // reverse the arabic string for RTL support in rendering
@@ -0,0 +1,61 @@
+package arabic
+
+import (
+	"testing"
+
+	"github.com/raceresult/gopdf/pdf"
+)
+
+// glyphs has glyphs for all runes except the given ones
+type glyphs map[rune]bool
+
+func (q glyphs) HasGylph(runes []rune) []bool {
+	res := make([]bool, len(runes))
+	for i, r := range runes {
+		res[i] = !q[r]
+	}
+	return res
+}
+
+func TestShapeWith(t *testing.T) {
+	tests := []struct {
+		input   string
+		missing glyphs
+		want    string
+	}{
+		{"abc", nil, "abc"},
+		{"سلام", nil, "ﺳﻠﺎﻡ"},
+		{"سلام abc", nil, "ﺳﻠﺎﻡ abc"},
+		{"سلام", glyphs{0xfee0: true}, "ﺳلﺎﻡ"},
+	}
+	for _, tt := range tests {
+		if got := ShapeWith(tt.input, tt.missing); got != tt.want {
+			t.Errorf("ShapeWith(%q): got %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// font is a font handler with glyphs for all runes
+type font struct {
+	pdf.FontHandler
+}
+
+func (font) HasGylph(runes []rune) []bool {
+	return glyphs(nil).HasGylph(runes)
+}
+
+func TestShape(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"abc", "abc"},
+		{"سلام", "ﻡﺎﻠﺳ"},
+		{"سلام abc", "abc ﻡﺎﻠﺳ"},
+		{"سلام عليكم", "ﻢﻜﻴﻠﻋ ﻡﺎﻠﺳ"},
+	}
+	for _, tt := range tests {
+		if got := Shape(tt.input, font{}); got != tt.want {
+			t.Errorf("Shape(%q): got %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,756 @@
+package pdftext
+
+import (
+	"sort"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+// Direction is the base direction of a paragraph
+type Direction int
+
+const (
+	// DirectionAuto determines the direction from the first strong character of the paragraph
+	DirectionAuto Direction = iota
+	DirectionLTR
+	DirectionRTL
+)
+
+// maxBidiDepth is the maximum explicit embedding level
+const maxBidiDepth = 125
+
+// maxBracketPairs is the size of the bracket stack of rule BD16
+const maxBracketPairs = 63
+
+// BidiParagraph holds the embedding levels of a paragraph resolved according to the Unicode Bidirectional
+// Algorithm (UAX #9). After line breaking, the runes of each line are reordered for display with VisualOrder.
+// https://www.unicode.org/reports/tr9/
+type BidiParagraph struct {
+	runes   []rune
+	classes []bidi.Class // original bidi classes
+	levels  []uint8      // resolved embedding levels
+	level   uint8        // paragraph embedding level
+}
+
+// Reorder applies the bidirectional algorithm to the given single line paragraph and returns the text in visual
+// order, i.e. left to right, with mirrored brackets in right-to-left runs.
+func Reorder(s string, dir Direction) string {
+	if dir != DirectionRTL && !hasRTL(s) {
+		return s
+	}
+	p := NewBidiParagraph(s, dir)
+	order := p.VisualOrder(0, len(p.runes))
+	res := make([]rune, len(order))
+	for i, idx := range order {
+		res[i] = p.Rune(idx)
+	}
+	return string(res)
+}
+
+// hasRTL checks if the text contains characters that may be displayed right-to-left
+func hasRTL(s string) bool {
+	for _, r := range s {
+		switch bidiClass(r) {
+		case bidi.R, bidi.AL, bidi.RLE, bidi.RLO, bidi.RLI:
+			return true
+		}
+	}
+	return false
+}
+
+// bidiClass returns the bidi class of the rune
+func bidiClass(r rune) bidi.Class {
+	p, _ := bidi.LookupRune(r)
+	return p.Class()
+}
+
+// NewBidiParagraph resolves the embedding levels of the given paragraph
+func NewBidiParagraph(s string, dir Direction) *BidiParagraph {
+	q := &BidiParagraph{runes: []rune(s)}
+	q.classes = make([]bidi.Class, len(q.runes))
+	q.levels = make([]uint8, len(q.runes))
+	for i, r := range q.runes {
+		q.classes[i] = bidiClass(r)
+	}
+	matchingPDI, matchingInitiator := q.matchIsolates()
+
+	// paragraph level (P2, P3)
+	switch dir {
+	case DirectionRTL:
+		q.level = 1
+	case DirectionAuto:
+		if q.firstStrong(0, len(q.runes), matchingPDI) == 1 {
+			q.level = 1
+		}
+	}
+
+	// explicit levels and directions (X1 - X8)
+	types := q.explicitLevels(matchingPDI)
+
+	// resolve isolating run sequences (X10, W1 - W7, N0 - N2, I1 - I2)
+	for _, seq := range q.isolatingRunSequences(matchingPDI, matchingInitiator) {
+		q.resolveSequence(seq, types)
+	}
+
+	// characters removed by X9 get the level of the preceding character
+	for i, c := range q.classes {
+		if !removedByX9(c) {
+			continue
+		}
+		if i == 0 {
+			q.levels[i] = q.level
+		} else {
+			q.levels[i] = q.levels[i-1]
+		}
+	}
+	return q
+}
+
+// IsRTL returns true if the paragraph direction is right-to-left
+func (q *BidiParagraph) IsRTL() bool {
+	return q.level&1 == 1
+}
+
+// Rune returns the rune at the given index, mirrored if it is displayed right-to-left (L4)
+func (q *BidiParagraph) Rune(i int) rune {
+	r := q.runes[i]
+	if q.levels[i]&1 == 1 {
+		if m, ok := mirrors[r]; ok {
+			return m
+		}
+	}
+	return r
+}
+
+// VisualOrder returns the indices of the runes of the line from start (inclusive) to end (exclusive) in the order
+// they are displayed from left to right (L1, L2). Explicit directional formatting characters are omitted.
+func (q *BidiParagraph) VisualOrder(start, end int) []int {
+	// reset whitespace at the end of the line and before separators to paragraph level (L1)
+	levels := make([]uint8, end-start)
+	copy(levels, q.levels[start:end])
+	trailing := true
+	for i := end - 1; i >= start; i-- {
+		switch c := q.classes[i]; {
+		case c == bidi.S || c == bidi.B:
+			levels[i-start] = q.level
+			trailing = true
+		case c == bidi.WS || isIsolateControl(c) || removedByX9(c):
+			if trailing {
+				levels[i-start] = q.level
+			}
+		default:
+			trailing = false
+		}
+	}
+
+	// omit formatting characters
+	order := make([]int, 0, end-start)
+	orderLevels := make([]uint8, 0, end-start)
+	var highest uint8
+	lowestOdd := uint8(maxBidiDepth + 2)
+	for i := start; i < end; i++ {
+		if isFormattingCharacter(q.runes[i], q.classes[i]) {
+			continue
+		}
+		l := levels[i-start]
+		order = append(order, i)
+		orderLevels = append(orderLevels, l)
+		if l > highest {
+			highest = l
+		}
+		if l&1 == 1 && l < lowestOdd {
+			lowestOdd = l
+		}
+	}
+
+	// reverse sequences from the highest level to the lowest odd level (L2)
+	for level := highest; level >= lowestOdd && level > 0; level-- {
+		for i := 0; i < len(order); {
+			if orderLevels[i] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(order) && orderLevels[j] >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+				orderLevels[a], orderLevels[b] = orderLevels[b], orderLevels[a]
+			}
+			i = j
+		}
+	}
+	return order
+}
+
+// matchIsolates returns the index of the matching PDI for every isolate initiator and the index of the matching
+// isolate initiator for every PDI, or -1 if there is none (BD9)
+func (q *BidiParagraph) matchIsolates() ([]int, []int) {
+	matchingPDI := make([]int, len(q.runes))
+	matchingInitiator := make([]int, len(q.runes))
+	var stack []int
+	for i, c := range q.classes {
+		matchingPDI[i] = -1
+		matchingInitiator[i] = -1
+		switch c {
+		case bidi.LRI, bidi.RLI, bidi.FSI:
+			stack = append(stack, i)
+		case bidi.PDI:
+			if len(stack) != 0 {
+				j := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				matchingPDI[j] = i
+				matchingInitiator[i] = j
+			}
+		case bidi.B:
+			stack = stack[:0]
+		}
+	}
+	return matchingPDI, matchingInitiator
+}
+
+// firstStrong returns the level indicated by the first strong character between start and end, skipping
+// isolates: 0 for L, 1 for R and AL, -1 if there is none (P2, P3)
+func (q *BidiParagraph) firstStrong(start, end int, matchingPDI []int) int {
+	for i := start; i < end; i++ {
+		switch q.classes[i] {
+		case bidi.L:
+			return 0
+		case bidi.R, bidi.AL:
+			return 1
+		case bidi.B:
+			return -1
+		case bidi.LRI, bidi.RLI, bidi.FSI:
+			if matchingPDI[i] < 0 {
+				return -1
+			}
+			i = matchingPDI[i]
+		}
+	}
+	return -1
+}
+
+// bidiStatus is an entry of the directional status stack
+type bidiStatus struct {
+	level    uint8
+	override bidi.Class // ON if neutral
+	isolate  bool
+}
+
+// explicitLevels determines the explicit embedding levels and returns the bidi classes after applying
+// directional overrides (X1 - X8)
+func (q *BidiParagraph) explicitLevels(matchingPDI []int) []bidi.Class {
+	types := make([]bidi.Class, len(q.classes))
+	copy(types, q.classes)
+
+	stack := []bidiStatus{{level: q.level, override: bidi.ON}}
+	var overflowIsolates, overflowEmbeddings, validIsolates int
+	for i, c := range q.classes {
+		top := stack[len(stack)-1]
+		switch c {
+		case bidi.RLE, bidi.LRE, bidi.RLO, bidi.LRO, bidi.RLI, bidi.LRI, bidi.FSI:
+			isolate := isIsolateInitiator(c)
+			rtl := c == bidi.RLE || c == bidi.RLO || c == bidi.RLI
+			if c == bidi.FSI {
+				end := matchingPDI[i]
+				if end < 0 {
+					end = len(q.runes)
+				}
+				rtl = q.firstStrong(i+1, end, matchingPDI) == 1
+			}
+
+			q.levels[i] = top.level
+			if isolate && top.override != bidi.ON {
+				types[i] = top.override
+			}
+
+			var level uint8
+			if rtl {
+				level = (top.level + 1) | 1
+			} else {
+				level = (top.level + 2) &^ 1
+			}
+			switch {
+			case level <= maxBidiDepth && overflowIsolates == 0 && overflowEmbeddings == 0:
+				status := bidiStatus{level: level, override: bidi.ON, isolate: isolate}
+				switch c {
+				case bidi.RLO:
+					status.override = bidi.R
+				case bidi.LRO:
+					status.override = bidi.L
+				}
+				if isolate {
+					validIsolates++
+				}
+				stack = append(stack, status)
+			case isolate:
+				overflowIsolates++
+			case overflowIsolates == 0:
+				overflowEmbeddings++
+			}
+
+		case bidi.PDI:
+			switch {
+			case overflowIsolates > 0:
+				overflowIsolates--
+			case validIsolates > 0:
+				overflowEmbeddings = 0
+				for !stack[len(stack)-1].isolate {
+					stack = stack[:len(stack)-1]
+				}
+				stack = stack[:len(stack)-1]
+				validIsolates--
+			}
+			top = stack[len(stack)-1]
+			q.levels[i] = top.level
+			if top.override != bidi.ON {
+				types[i] = top.override
+			}
+
+		case bidi.PDF:
+			switch {
+			case overflowIsolates > 0:
+			case overflowEmbeddings > 0:
+				overflowEmbeddings--
+			case !top.isolate && len(stack) >= 2:
+				stack = stack[:len(stack)-1]
+			}
+			q.levels[i] = top.level
+
+		case bidi.B:
+			q.levels[i] = q.level
+
+		default:
+			q.levels[i] = top.level
+			if top.override != bidi.ON && c != bidi.BN {
+				types[i] = top.override
+			}
+		}
+	}
+	return types
+}
+
+// isolatingRunSequences returns the indices of the characters of all isolating run sequences (BD13, X10)
+func (q *BidiParagraph) isolatingRunSequences(matchingPDI, matchingInitiator []int) [][]int {
+	// level runs, ignoring characters removed by X9
+	var runs [][]int
+	runAt := make([]int, len(q.runes))
+	for i, c := range q.classes {
+		if removedByX9(c) {
+			continue
+		}
+		if len(runs) == 0 {
+			runs = append(runs, nil)
+		} else if last := runs[len(runs)-1]; q.levels[last[len(last)-1]] != q.levels[i] {
+			runs = append(runs, nil)
+		}
+		runs[len(runs)-1] = append(runs[len(runs)-1], i)
+		runAt[i] = len(runs) - 1
+	}
+
+	// chain level runs connected by matching isolate initiators and PDIs
+	var sequences [][]int
+	for _, run := range runs {
+		if first := run[0]; q.classes[first] == bidi.PDI && matchingInitiator[first] >= 0 {
+			continue
+		}
+		seq := append([]int(nil), run...)
+		for {
+			last := seq[len(seq)-1]
+			if !isIsolateInitiator(q.classes[last]) || matchingPDI[last] < 0 {
+				break
+			}
+			next := runs[runAt[matchingPDI[last]]]
+			if next[0] != matchingPDI[last] {
+				break
+			}
+			seq = append(seq, next...)
+		}
+		sequences = append(sequences, seq)
+	}
+	return sequences
+}
+
+// resolveSequence resolves weak types, neutral types and implicit levels of an isolating run sequence
+func (q *BidiParagraph) resolveSequence(seq []int, types []bidi.Class) {
+	first, last := seq[0], seq[len(seq)-1]
+	level := q.levels[first]
+
+	// start and end of sequence types
+	prev := q.level
+	for i := first - 1; i >= 0; i-- {
+		if !removedByX9(q.classes[i]) {
+			prev = q.levels[i]
+			break
+		}
+	}
+	next := q.level
+	if !isIsolateInitiator(q.classes[last]) {
+		for i := last + 1; i < len(q.runes); i++ {
+			if !removedByX9(q.classes[i]) {
+				next = q.levels[i]
+				break
+			}
+		}
+	}
+	sos := directionOfLevel(maxLevel(prev, level))
+	eos := directionOfLevel(maxLevel(next, level))
+	e := directionOfLevel(level)
+
+	t := make([]bidi.Class, len(seq))
+	for k, i := range seq {
+		t[k] = types[i]
+	}
+
+	// W1: non-spacing marks
+	for k := range t {
+		if t[k] != bidi.NSM {
+			continue
+		}
+		switch {
+		case k == 0:
+			t[k] = sos
+		case isIsolateControl(t[k-1]):
+			t[k] = bidi.ON
+		default:
+			t[k] = t[k-1]
+		}
+	}
+
+	// W2: european numbers after arabic letters
+	strong := sos
+	for k, c := range t {
+		switch c {
+		case bidi.L, bidi.R, bidi.AL:
+			strong = c
+		case bidi.EN:
+			if strong == bidi.AL {
+				t[k] = bidi.AN
+			}
+		}
+	}
+
+	// W3: arabic letters
+	for k, c := range t {
+		if c == bidi.AL {
+			t[k] = bidi.R
+		}
+	}
+
+	// W4: single separators between numbers
+	for k := 1; k < len(t)-1; k++ {
+		switch {
+		case t[k] == bidi.ES && t[k-1] == bidi.EN && t[k+1] == bidi.EN:
+			t[k] = bidi.EN
+		case t[k] == bidi.CS && t[k-1] == bidi.EN && t[k+1] == bidi.EN:
+			t[k] = bidi.EN
+		case t[k] == bidi.CS && t[k-1] == bidi.AN && t[k+1] == bidi.AN:
+			t[k] = bidi.AN
+		}
+	}
+
+	// W5: terminators adjacent to european numbers
+	for k := 0; k < len(t); k++ {
+		if t[k] != bidi.ET {
+			continue
+		}
+		end := k
+		for end < len(t) && t[end] == bidi.ET {
+			end++
+		}
+		if k > 0 && t[k-1] == bidi.EN || end < len(t) && t[end] == bidi.EN {
+			for j := k; j < end; j++ {
+				t[j] = bidi.EN
+			}
+		}
+		k = end - 1
+	}
+
+	// W6: remaining separators and terminators
+	for k, c := range t {
+		if c == bidi.ES || c == bidi.ET || c == bidi.CS {
+			t[k] = bidi.ON
+		}
+	}
+
+	// W7: european numbers in left-to-right context
+	strong = sos
+	for k, c := range t {
+		switch c {
+		case bidi.L, bidi.R:
+			strong = c
+		case bidi.EN:
+			if strong == bidi.L {
+				t[k] = bidi.L
+			}
+		}
+	}
+
+	// N0: bracket pairs
+	q.resolveBrackets(seq, t, sos, e)
+
+	// N1, N2: sequences of neutrals
+	for k := 0; k < len(t); k++ {
+		if !isNeutralOrIsolate(t[k]) {
+			continue
+		}
+		end := k
+		for end < len(t) && isNeutralOrIsolate(t[end]) {
+			end++
+		}
+		before, after := sos, eos
+		if k > 0 {
+			before = strongDirection(t[k-1])
+		}
+		if end < len(t) {
+			after = strongDirection(t[end])
+		}
+		dir := e
+		if before == after {
+			dir = before
+		}
+		for j := k; j < end; j++ {
+			t[j] = dir
+		}
+		k = end - 1
+	}
+
+	// I1, I2: implicit levels
+	for k, i := range seq {
+		l := q.levels[i]
+		if l&1 == 0 {
+			switch t[k] {
+			case bidi.R:
+				l++
+			case bidi.AN, bidi.EN:
+				l += 2
+			}
+		} else {
+			switch t[k] {
+			case bidi.L, bidi.EN, bidi.AN:
+				l++
+			}
+		}
+		q.levels[i] = l
+	}
+}
+
+// bracketPair holds the positions of paired brackets within an isolating run sequence
+type bracketPair struct {
+	open, close int
+}
+
+// resolveBrackets resolves the types of paired brackets (BD16, N0)
+func (q *BidiParagraph) resolveBrackets(seq []int, t []bidi.Class, sos, e bidi.Class) {
+	// identify bracket pairs
+	type openBracket struct {
+		closing rune
+		pos     int
+	}
+	var pairs []bracketPair
+	var stack []openBracket
+loop:
+	for k, i := range seq {
+		if t[k] != bidi.ON {
+			continue
+		}
+		r := q.runes[i]
+		p, _ := bidi.LookupRune(r)
+		if !p.IsBracket() {
+			continue
+		}
+		if p.IsOpeningBracket() {
+			closing, ok := mirrors[r]
+			if !ok {
+				continue
+			}
+			if len(stack) == maxBracketPairs {
+				break loop
+			}
+			stack = append(stack, openBracket{closing: canonicalBracket(closing), pos: k})
+			continue
+		}
+		r = canonicalBracket(r)
+		for j := len(stack) - 1; j >= 0; j-- {
+			if stack[j].closing == r {
+				pairs = append(pairs, bracketPair{open: stack[j].pos, close: k})
+				stack = stack[:j]
+				break
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].open < pairs[j].open })
+
+	// resolve pairs in order of the opening brackets
+	for _, pair := range pairs {
+		found := bidi.ON
+		for k := pair.open + 1; k < pair.close; k++ {
+			d := strongOrNumberDirection(t[k])
+			if d == bidi.ON {
+				continue
+			}
+			found = d
+			if d == e {
+				break
+			}
+		}
+
+		dir := bidi.ON
+		switch found {
+		case bidi.ON:
+		case e:
+			dir = e
+		default:
+			// opposite direction inside brackets: check context before the opening bracket
+			context := sos
+			for k := pair.open - 1; k >= 0; k-- {
+				if d := strongOrNumberDirection(t[k]); d != bidi.ON {
+					context = d
+					break
+				}
+			}
+			if context == found {
+				dir = found
+			} else {
+				dir = e
+			}
+		}
+		if dir == bidi.ON {
+			continue
+		}
+
+		// set brackets and following non-spacing marks
+		for _, pos := range []int{pair.open, pair.close} {
+			t[pos] = dir
+			for k := pos + 1; k < len(t) && q.classes[seq[k]] == bidi.NSM; k++ {
+				t[k] = dir
+			}
+		}
+	}
+}
+
+// removedByX9 checks if characters of the given class are ignored by the algorithm (X9)
+func removedByX9(c bidi.Class) bool {
+	switch c {
+	case bidi.RLE, bidi.LRE, bidi.RLO, bidi.LRO, bidi.PDF, bidi.BN:
+		return true
+	}
+	return false
+}
+
+// isIsolateInitiator checks if the class is LRI, RLI or FSI
+func isIsolateInitiator(c bidi.Class) bool {
+	return c == bidi.LRI || c == bidi.RLI || c == bidi.FSI
+}
+
+// isIsolateControl checks if the class is an isolate initiator or PDI
+func isIsolateControl(c bidi.Class) bool {
+	return isIsolateInitiator(c) || c == bidi.PDI
+}
+
+// isNeutralOrIsolate checks if the class is treated as neutral by rules N1 and N2
+func isNeutralOrIsolate(c bidi.Class) bool {
+	switch c {
+	case bidi.B, bidi.S, bidi.WS, bidi.ON:
+		return true
+	}
+	return isIsolateControl(c)
+}
+
+// isFormattingCharacter checks if the rune is an invisible directional formatting character
+func isFormattingCharacter(r rune, c bidi.Class) bool {
+	switch c {
+	case bidi.RLE, bidi.LRE, bidi.RLO, bidi.LRO, bidi.PDF, bidi.LRI, bidi.RLI, bidi.FSI, bidi.PDI:
+		return true
+	}
+	// left-to-right, right-to-left and arabic letter marks
+	return r == 0x200E || r == 0x200F || r == 0x061C
+}
+
+// strongDirection returns the direction of a resolved strong type, treating numbers as R (N1)
+func strongDirection(c bidi.Class) bidi.Class {
+	if c == bidi.L {
+		return bidi.L
+	}
+	return bidi.R
+}
+
+// strongOrNumberDirection returns L or R for strong types and numbers, ON otherwise (N0)
+func strongOrNumberDirection(c bidi.Class) bidi.Class {
+	switch c {
+	case bidi.L:
+		return bidi.L
+	case bidi.R, bidi.AL, bidi.EN, bidi.AN:
+		return bidi.R
+	}
+	return bidi.ON
+}
+
+// directionOfLevel returns L for even and R for odd levels
+func directionOfLevel(level uint8) bidi.Class {
+	if level&1 == 1 {
+		return bidi.R
+	}
+	return bidi.L
+}
+
+func maxLevel(a, b uint8) uint8 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// canonicalBracket maps brackets to their canonical equivalent for bracket pair matching
+func canonicalBracket(r rune) rune {
+	switch r {
+	case 0x2329:
+		return 0x3008
+	case 0x232A:
+		return 0x3009
+	}
+	return r
+}
+
+// mirrors maps characters with the Bidi_Mirrored property to their mirrored glyph (BidiMirroring.txt)
+var mirrors = func() map[rune]rune {
+	pairs := [][2]rune{
+		{'(', ')'}, {'<', '>'}, {'[', ']'}, {'{', '}'}, {0x00AB, 0x00BB}, {0x0F3A, 0x0F3B}, {0x0F3C, 0x0F3D},
+		{0x169B, 0x169C}, {0x2039, 0x203A}, {0x2045, 0x2046}, {0x207D, 0x207E}, {0x208D, 0x208E},
+		{0x2208, 0x220B}, {0x2209, 0x220C}, {0x220A, 0x220D}, {0x2215, 0x29F5}, {0x223C, 0x223D},
+		{0x2243, 0x22CD}, {0x2252, 0x2253}, {0x2254, 0x2255}, {0x2264, 0x2265}, {0x2266, 0x2267},
+		{0x2268, 0x2269}, {0x226A, 0x226B}, {0x226E, 0x226F}, {0x2270, 0x2271}, {0x2272, 0x2273},
+		{0x2274, 0x2275}, {0x2276, 0x2277}, {0x2278, 0x2279}, {0x227A, 0x227B}, {0x227C, 0x227D},
+		{0x227E, 0x227F}, {0x2280, 0x2281}, {0x2282, 0x2283}, {0x2284, 0x2285}, {0x2286, 0x2287},
+		{0x2288, 0x2289}, {0x228A, 0x228B}, {0x228F, 0x2290}, {0x2291, 0x2292}, {0x22A2, 0x22A3},
+		{0x22B0, 0x22B1}, {0x22B2, 0x22B3}, {0x22B4, 0x22B5}, {0x22B6, 0x22B7}, {0x22C9, 0x22CA},
+		{0x22CB, 0x22CC}, {0x22D0, 0x22D1}, {0x22D6, 0x22D7}, {0x22D8, 0x22D9}, {0x22DA, 0x22DB},
+		{0x22DC, 0x22DD}, {0x22DE, 0x22DF}, {0x22E0, 0x22E1}, {0x22E2, 0x22E3}, {0x22E4, 0x22E5},
+		{0x22E6, 0x22E7}, {0x22E8, 0x22E9}, {0x22EA, 0x22EB}, {0x22EC, 0x22ED}, {0x22F0, 0x22F1},
+		{0x2308, 0x2309}, {0x230A, 0x230B}, {0x2329, 0x232A}, {0x27C3, 0x27C4}, {0x27C5, 0x27C6},
+		{0x27C8, 0x27C9}, {0x27D5, 0x27D6}, {0x27DD, 0x27DE}, {0x27E2, 0x27E3}, {0x27E4, 0x27E5},
+		{0x29B8, 0x2298}, {0x29C0, 0x29C1}, {0x29C4, 0x29C5}, {0x29CF, 0x29D0}, {0x29D1, 0x29D2},
+		{0x29D4, 0x29D5}, {0x29F8, 0x29F9}, {0x2E02, 0x2E03}, {0x2E04, 0x2E05}, {0x2E09, 0x2E0A},
+		{0x2E0C, 0x2E0D}, {0x2E1C, 0x2E1D}, {0xFE64, 0xFE65}, {0xFF1C, 0xFF1E}, {0xFF3B, 0xFF3D},
+		{0xFF5B, 0xFF5D}, {0xFF5F, 0xFF60}, {0xFF62, 0xFF63},
+	}
+
+	// brackets with consecutive code points
+	for _, r := range [][2]rune{
+		{0x2768, 0x2775}, {0x27E6, 0x27EF}, {0x2983, 0x2998}, {0x29D8, 0x29DB}, {0x29FC, 0x29FD},
+		{0x2E22, 0x2E29}, {0x2E55, 0x2E5C}, {0x3008, 0x3011}, {0x3014, 0x301B}, {0xFE59, 0xFE5E},
+		{0xFF08, 0xFF09},
+	} {
+		for c := r[0]; c < r[1]; c += 2 {
+			pairs = append(pairs, [2]rune{c, c + 1})
+		}
+	}
+
+	m := make(map[rune]rune, 2*len(pairs))
+	for _, p := range pairs {
+		m[p[0]] = p[1]
+		m[p[1]] = p[0]
+	}
+	return m
+}()
@@ -0,0 +1,72 @@
+package pdftext
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseCodePoints parses space separated hexadecimal code points as used in the Unicode test data files
+func parseCodePoints(t *testing.T, s string) string {
+	t.Helper()
+	var res []rune
+	for _, f := range strings.Fields(s) {
+		v, err := strconv.ParseUint(f, 16, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, rune(v))
+	}
+	return string(res)
+}
+
+// samples in the format of BidiCharacterTest.txt: code points, paragraph direction, resolved paragraph level,
+// resolved levels and visual order. Characters removed by rule X9 get the level of the preceding character.
+func TestBidiParagraph(t *testing.T) {
+	tests := []struct {
+		codePoints string
+		dir        Direction
+		level      uint8
+		levels     []uint8
+		order      []int
+	}{
+		{"05D0 05D1 0028 0061 0062 0029 05D2", DirectionAuto, 1, []uint8{1, 1, 1, 2, 2, 1, 1}, []int{6, 5, 3, 4, 2, 1, 0}},
+		{"0061 0020 05D0 0020 0031 0032", DirectionLTR, 0, []uint8{0, 0, 1, 1, 2, 2}, []int{0, 1, 4, 5, 3, 2}},
+		{"05D0 0020 0031 0032 0025", DirectionRTL, 1, []uint8{1, 1, 2, 2, 2}, []int{2, 3, 4, 1, 0}},
+		{"0627 0031 0032", DirectionAuto, 1, []uint8{1, 2, 2}, []int{1, 2, 0}},
+		{"05D0 0020", DirectionLTR, 0, []uint8{1, 0}, []int{0, 1}},
+		{"0061 0062 0020 0063", DirectionRTL, 1, []uint8{2, 2, 2, 2}, []int{0, 1, 2, 3}},
+		{"05D0 202A 0061 202C 05D1", DirectionAuto, 1, []uint8{1, 1, 2, 2, 1}, []int{4, 2, 0}},
+	}
+	for _, tt := range tests {
+		p := NewBidiParagraph(parseCodePoints(t, tt.codePoints), tt.dir)
+		if p.level != tt.level {
+			t.Errorf("%s: got paragraph level %d, want %d", tt.codePoints, p.level, tt.level)
+		}
+		if !reflect.DeepEqual(p.levels, tt.levels) {
+			t.Errorf("%s: got levels %v, want %v", tt.codePoints, p.levels, tt.levels)
+		}
+		if got := p.VisualOrder(0, len(p.runes)); !reflect.DeepEqual(got, tt.order) {
+			t.Errorf("%s: got order %v, want %v", tt.codePoints, got, tt.order)
+		}
+	}
+}
+
+func TestReorder(t *testing.T) {
+	tests := []struct {
+		s, want string
+		dir     Direction
+	}{
+		{"abc", "abc", DirectionAuto},
+		{"שלום", "םולש", DirectionAuto},
+		{"abc (שלום) def", "abc (םולש) def", DirectionAuto},
+		{"שלום (abc)", "(abc) םולש", DirectionAuto},
+		{"abc", "abc", DirectionRTL},
+	}
+	for _, tt := range tests {
+		if got := Reorder(tt.s, tt.dir); got != tt.want {
+			t.Errorf("Reorder(%q): got %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
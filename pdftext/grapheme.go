@@ -0,0 +1,146 @@
+package pdftext
+
+import "unicode"
+
+// graphemeProperty is the Grapheme_Cluster_Break property of a rune
+type graphemeProperty int
+
+const (
+	gbOther graphemeProperty = iota
+	gbCR
+	gbLF
+	gbControl
+	gbExtend
+	gbZWJ
+	gbRegionalIndicator
+	gbSpacingMark
+	gbL
+	gbV
+	gbT
+	gbLV
+	gbLVT
+	gbExtendedPictographic
+)
+
+// GraphemeBoundaries returns for every rune of the text whether an extended grapheme cluster starts with it, i.e.
+// whether the text may be split before the rune without separating combining marks, Hangul syllables or emoji
+// sequences (UAX #29).
+// https://www.unicode.org/reports/tr29/
+func GraphemeBoundaries(runes []rune) []bool {
+	res := make([]bool, len(runes))
+	if len(runes) == 0 {
+		return res
+	}
+	res[0] = true
+
+	props := make([]graphemeProperty, len(runes))
+	for i, r := range runes {
+		props[i] = graphemePropertyOf(r)
+	}
+
+	var regionalIndicators int  // number of consecutive regional indicators before the current position
+	var pictographicZWJ bool    // the current position follows ExtPict Extend* ZWJ
+	var pictographicExtend bool // the current position follows ExtPict Extend*
+	for i := 1; i < len(runes); i++ {
+		a, b := props[i-1], props[i]
+
+		// state of emoji and flag sequences
+		switch a {
+		case gbExtendedPictographic:
+			pictographicExtend = true
+			pictographicZWJ = false
+		case gbExtend:
+			pictographicZWJ = false
+		case gbZWJ:
+			pictographicZWJ = pictographicExtend
+			pictographicExtend = false
+		default:
+			pictographicExtend = false
+			pictographicZWJ = false
+		}
+		if a == gbRegionalIndicator {
+			regionalIndicators++
+		} else {
+			regionalIndicators = 0
+		}
+
+		res[i] = graphemeBreak(a, b, regionalIndicators, pictographicZWJ)
+	}
+	return res
+}
+
+// graphemeBreak checks if there is a grapheme cluster boundary between two runes
+func graphemeBreak(a, b graphemeProperty, regionalIndicators int, pictographicZWJ bool) bool {
+	switch {
+	case a == gbCR && b == gbLF: // GB3
+		return false
+	case a == gbControl || a == gbCR || a == gbLF: // GB4
+		return true
+	case b == gbControl || b == gbCR || b == gbLF: // GB5
+		return true
+	case a == gbL && (b == gbL || b == gbV || b == gbLV || b == gbLVT): // GB6
+		return false
+	case (a == gbLV || a == gbV) && (b == gbV || b == gbT): // GB7
+		return false
+	case (a == gbLVT || a == gbT) && b == gbT: // GB8
+		return false
+	case b == gbExtend || b == gbZWJ || b == gbSpacingMark: // GB9, GB9a
+		return false
+	case pictographicZWJ && b == gbExtendedPictographic: // GB11
+		return false
+	case a == gbRegionalIndicator && b == gbRegionalIndicator: // GB12, GB13
+		return regionalIndicators%2 == 0
+	}
+	return true // GB999
+}
+
+// graphemePropertyOf returns the grapheme cluster break property of the rune
+func graphemePropertyOf(r rune) graphemeProperty {
+	switch {
+	case r == '\r':
+		return gbCR
+	case r == '\n':
+		return gbLF
+	case r == 0x200D:
+		return gbZWJ
+	case r == 0x200C, r >= 0x1F3FB && r <= 0x1F3FF, r >= 0xE0020 && r <= 0xE007F, r == 0xFF9E, r == 0xFF9F:
+		return gbExtend
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return gbRegionalIndicator
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97F:
+		return gbL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return gbV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return gbT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return gbLV
+		}
+		return gbLVT
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return gbExtend
+	case unicode.Is(unicode.Mc, r), r == 0x0E33, r == 0x0EB3:
+		return gbSpacingMark
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return gbControl
+	case isExtendedPictographic(r):
+		return gbExtendedPictographic
+	}
+	return gbOther
+}
+
+// isExtendedPictographic checks if the rune is an emoji or another pictographic symbol
+func isExtendedPictographic(r rune) bool {
+	switch {
+	case r == 0x00A9, r == 0x00AE, r == 0x203C, r == 0x2049, r == 0x2122, r == 0x2139,
+		r >= 0x2194 && r <= 0x2199, r == 0x21A9, r == 0x21AA, r == 0x231A, r == 0x231B, r == 0x2328, r == 0x23CF,
+		r >= 0x23E9 && r <= 0x23F3, r >= 0x23F8 && r <= 0x23FA, r == 0x24C2, r == 0x25AA, r == 0x25AB,
+		r == 0x25B6, r == 0x25C0, r >= 0x25FB && r <= 0x25FE, r >= 0x2600 && r <= 0x27BF,
+		r == 0x2934, r == 0x2935, r >= 0x2B05 && r <= 0x2B07, r == 0x2B1B, r == 0x2B1C, r == 0x2B50, r == 0x2B55,
+		r == 0x3030, r == 0x303D, r == 0x3297, r == 0x3299,
+		r >= 0x1F000 && r <= 0x1FAFF, r >= 0x1FC00 && r <= 0x1FFFD:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+// Package hyphenation finds hyphenation points in words using the Knuth–Liang algorithm. Patterns for English (US),
+// German, Dutch, French and Spanish are bundled, taken from the hyph-utf8 project. Their copyright and license
+// notices are given in the header of each pattern file and in ACKNOWLEDGEMENTS.md.
+package hyphenation
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed patterns
+var patternFiles embed.FS
+
+// Hyphenator finds hyphenation points in words of a language
+type Hyphenator struct {
+	// minimum number of characters before the first and after the last hyphen
+	LeftMin, RightMin int
+
+	patterns  map[string][]uint8
+	maxLength int
+}
+
+// bundledLanguage describes a bundled pattern file
+type bundledLanguage struct {
+	file              string
+	leftMin, rightMin int
+}
+
+var bundled = map[string]bundledLanguage{
+	"en": {file: "hyph-en-us.pat.txt", leftMin: 2, rightMin: 3},
+	"de": {file: "hyph-de-1996.pat.txt", leftMin: 2, rightMin: 2},
+	"nl": {file: "hyph-nl.pat.txt", leftMin: 2, rightMin: 2},
+	"fr": {file: "hyph-fr.pat.txt", leftMin: 2, rightMin: 2},
+	"es": {file: "hyph-es.pat.txt", leftMin: 2, rightMin: 2},
+}
+
+var (
+	loaded   = make(map[string]*Hyphenator)
+	loadedMu sync.Mutex
+)
+
+// Get returns the hyphenator with the bundled patterns of the given language, e.g. "de" or "en-US". If no patterns
+// for the region are available, those of the language are used.
+func Get(language string) (*Hyphenator, error) {
+	language = strings.ToLower(strings.ReplaceAll(language, "_", "-"))
+	if i := strings.Index(language, "-"); i >= 0 {
+		language = language[:i]
+	}
+	b, ok := bundled[language]
+	if !ok {
+		return nil, errors.New("no hyphenation patterns for language " + language)
+	}
+
+	loadedMu.Lock()
+	defer loadedMu.Unlock()
+	if h, ok := loaded[language]; ok {
+		return h, nil
+	}
+
+	f, err := patternFiles.Open("patterns/" + b.file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h, err := Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	h.LeftMin, h.RightMin = b.leftMin, b.rightMin
+	loaded[language] = h
+	return h, nil
+}
+
+// Parse reads patterns in the format of TeX's \patterns: patterns are separated by whitespace and consist of letters
+// with numbers in between, a dot marks the beginning or end of a word, "%" starts a comment.
+func Parse(r io.Reader) (*Hyphenator, error) {
+	h := &Hyphenator{
+		LeftMin:  2,
+		RightMin: 2,
+		patterns: make(map[string][]uint8),
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '%'); i >= 0 {
+			line = line[:i]
+		}
+		for _, pattern := range strings.Fields(line) {
+			h.addPattern(pattern)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// addPattern adds a single pattern like "a1b" or ".ach4". Values may consist of several digits: exceptions are
+// converted to patterns of the whole word with the values 11 (hyphen) and 10 (no hyphen), which override all other
+// patterns.
+func (q *Hyphenator) addPattern(pattern string) {
+	var letters []rune
+	values := []uint8{0}
+	for _, r := range pattern {
+		if r >= '0' && r <= '9' {
+			values[len(values)-1] = values[len(values)-1]*10 + uint8(r-'0')
+			continue
+		}
+		letters = append(letters, unicode.ToLower(r))
+		values = append(values, 0)
+	}
+	if len(letters) == 0 {
+		return
+	}
+	q.patterns[string(letters)] = values
+	if len(letters) > q.maxLength {
+		q.maxLength = len(letters)
+	}
+}
+
+// Hyphenate returns the positions (in runes) within the word before which a hyphen may be inserted
+func (q *Hyphenator) Hyphenate(word string) []int {
+	n := len([]rune(word))
+	leftMin, rightMin := q.LeftMin, q.RightMin
+	if leftMin < 1 {
+		leftMin = 1
+	}
+	if rightMin < 1 {
+		rightMin = 1
+	}
+	if n < leftMin+rightMin {
+		return nil
+	}
+
+	// word with boundary markers
+	text := make([]rune, 0, n+2)
+	text = append(text, '.')
+	for _, r := range word {
+		text = append(text, unicode.ToLower(r))
+	}
+	text = append(text, '.')
+
+	// values[i] is the maximum value of all matching patterns between text[i-1] and text[i]
+	values := make([]uint8, len(text)+1)
+	for i := range text {
+		for j := i + 1; j <= len(text) && j-i <= q.maxLength; j++ {
+			pattern, ok := q.patterns[string(text[i:j])]
+			if !ok {
+				continue
+			}
+			for k, v := range pattern {
+				if v > values[i+k] {
+					values[i+k] = v
+				}
+			}
+		}
+	}
+
+	// odd values are hyphenation points
+	var res []int
+	for p := leftMin; p <= n-rightMin; p++ {
+		if values[p+1]%2 == 1 {
+			res = append(res, p)
+		}
+	}
+	return res
+}
@@ -0,0 +1,89 @@
+package hyphenation
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHyphenate(t *testing.T) {
+	tests := []struct {
+		language string
+		word     string
+		want     []int
+	}{
+		// exceptions
+		{"en", "academies", []int{4, 5}},
+		{"en", "associate", []int{2, 4}},
+		{"en", "table", []int{2}},
+		{"en", "present", nil},
+		{"en", "project", nil},
+
+		// patterns
+		{"en", "hyphenation", []int{2, 6, 7}},
+		{"en", "Hyphenation", []int{2, 6, 7}},
+		{"de", "Silbentrennung", []int{3, 6, 10}},
+		{"nl", "lettergreep", []int{3, 6}},
+
+		// minimum fragment lengths
+		{"en", "a", nil},
+		{"de", "Aa", nil},
+	}
+	for _, tt := range tests {
+		h, err := Get(tt.language)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := h.Hyphenate(tt.word); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %q: got %v, want %v", tt.language, tt.word, got, tt.want)
+		}
+	}
+}
+
+// TestHyphenateExceptions checks all exceptions of the English patterns, which are converted to patterns of the
+// whole word with the values 11 (hyphen) and 10 (no hyphen)
+func TestHyphenateExceptions(t *testing.T) {
+	h, err := Get("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := patternFiles.Open("patterns/hyph-en-us.pat.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var count int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		pattern := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(pattern, "%") || !strings.Contains(pattern, "10") && !strings.Contains(pattern, "11") {
+			continue
+		}
+		count++
+
+		// expected hyphens at the values 11 within the minimum fragment lengths
+		word := strings.Map(func(r rune) rune {
+			if r == '.' || r >= '0' && r <= '9' {
+				return -1
+			}
+			return r
+		}, pattern)
+		n := len([]rune(word))
+		var letters int
+		var want []int
+		for _, part := range strings.SplitAfter(strings.ReplaceAll(pattern, "10", ""), "11") {
+			letters += len([]rune(strings.Trim(part, ".1")))
+			if strings.HasSuffix(part, "11") && letters >= h.LeftMin && letters <= n-h.RightMin {
+				want = append(want, letters)
+			}
+		}
+		if got := h.Hyphenate(word); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %v, want %v", word, got, want)
+		}
+	}
+	if count < 1000 {
+		t.Errorf("found %d exceptions only", count)
+	}
+}
@@ -0,0 +1,354 @@
+package pdftext
+
+import "unicode"
+
+// LineBreak indicates if a line may be broken before a rune
+type LineBreak int
+
+const (
+	LineBreakProhibited LineBreak = iota
+	LineBreakAllowed
+	LineBreakMandatory
+)
+
+// lineBreakClass is the Line_Break property of a rune
+type lineBreakClass int
+
+const (
+	lbAL  lineBreakClass = iota // alphabetic
+	lbBK                        // mandatory break
+	lbCR                        // carriage return
+	lbLF                        // line feed
+	lbNL                        // next line
+	lbSP                        // space
+	lbZW                        // zero width space
+	lbZWJ                       // zero width joiner
+	lbCM                        // combining mark
+	lbWJ                        // word joiner
+	lbGL                        // non-breaking ("glue")
+	lbBA                        // break after
+	lbBB                        // break before
+	lbB2                        // break opportunity before and after
+	lbHY                        // hyphen
+	lbCB                        // contingent break
+	lbCL                        // close punctuation
+	lbCP                        // close parenthesis
+	lbEX                        // exclamation/interrogation
+	lbIN                        // inseparable
+	lbNS                        // nonstarter
+	lbOP                        // open punctuation
+	lbQU                        // quotation
+	lbIS                        // infix numeric separator
+	lbNU                        // numeric
+	lbPO                        // postfix numeric
+	lbPR                        // prefix numeric
+	lbSY                        // symbols allowing break after
+	lbHL                        // hebrew letter
+	lbID                        // ideographic
+	lbJL                        // hangul L jamo
+	lbJV                        // hangul V jamo
+	lbJT                        // hangul T jamo
+	lbH2                        // hangul LV syllable
+	lbH3                        // hangul LVT syllable
+	lbRI                        // regional indicator
+)
+
+// LineBreaks returns for every rune of the text whether a line may be broken before it according to the Unicode
+// Line Breaking Algorithm (UAX #14). Complex context dependent scripts (Thai, Lao, Khmer, Myanmar) are treated as
+// alphabetic. Opportunities within grapheme clusters are removed.
+// https://www.unicode.org/reports/tr14/
+func LineBreaks(runes []rune) []LineBreak {
+	res := make([]LineBreak, len(runes))
+	if len(runes) == 0 {
+		return res
+	}
+
+	// classes after LB1
+	classes := make([]lineBreakClass, len(runes))
+	for i, r := range runes {
+		classes[i] = lineBreakClassOf(r)
+	}
+
+	// classes after treating combining marks as the preceding character (LB9, LB10)
+	resolved := make([]lineBreakClass, len(runes))
+	for i, c := range classes {
+		resolved[i] = c
+		if c != lbCM && c != lbZWJ {
+			continue
+		}
+		if i == 0 {
+			resolved[i] = lbAL
+			continue
+		}
+		switch prev := resolved[i-1]; prev {
+		case lbBK, lbCR, lbLF, lbNL, lbSP, lbZW:
+			resolved[i] = lbAL
+		default:
+			resolved[i] = prev
+		}
+	}
+
+	graphemes := GraphemeBoundaries(runes)
+	var regionalIndicators int
+	lastNonSpace := -1 // resolved class of the last character that is not a space, -1 if none
+	for i := 1; i < len(runes); i++ {
+		// track context
+		a := resolved[i-1]
+		if a == lbRI {
+			regionalIndicators++
+		} else {
+			regionalIndicators = 0
+		}
+		if a != lbSP {
+			lastNonSpace = int(a)
+		}
+
+		res[i] = lineBreakBetween(runes, classes, resolved, i, lastNonSpace, regionalIndicators)
+		if res[i] == LineBreakAllowed && !graphemes[i] {
+			res[i] = LineBreakProhibited
+		}
+	}
+	return res
+}
+
+// lineBreakBetween applies the rules LB4 to LB31 to the position before rune i
+func lineBreakBetween(runes []rune, classes, resolved []lineBreakClass, i int, lastNonSpace int,
+	regionalIndicators int) LineBreak {
+
+	a, b := resolved[i-1], classes[i]
+	before := lineBreakClass(lastNonSpace)
+
+	// mandatory breaks (LB4, LB5)
+	switch {
+	case a == lbBK:
+		return LineBreakMandatory
+	case a == lbCR && b == lbLF:
+		return LineBreakProhibited
+	case a == lbCR || a == lbLF || a == lbNL:
+		return LineBreakMandatory
+	}
+
+	// no break before hard line breaks, spaces and zero width spaces (LB6, LB7)
+	switch b {
+	case lbBK, lbCR, lbLF, lbNL, lbSP, lbZW:
+		return LineBreakProhibited
+	}
+
+	// break after zero width space, also if followed by spaces (LB8)
+	if lastNonSpace >= 0 && before == lbZW {
+		return LineBreakAllowed
+	}
+
+	// zero width joiner and combining marks (LB8a, LB9, LB10)
+	if classes[i-1] == lbZWJ {
+		return LineBreakProhibited
+	}
+	if b == lbCM || b == lbZWJ {
+		if a != lbSP {
+			return LineBreakProhibited
+		}
+		b = lbAL
+	}
+
+	switch {
+	case a == lbWJ || b == lbWJ: // LB11
+		return LineBreakProhibited
+	case a == lbGL: // LB12
+		return LineBreakProhibited
+	case b == lbGL && a != lbSP && a != lbBA && a != lbHY: // LB12a
+		return LineBreakProhibited
+	case b == lbCL || b == lbCP || b == lbEX || b == lbIS || b == lbSY: // LB13
+		return LineBreakProhibited
+	case lastNonSpace >= 0 && before == lbOP: // LB14
+		return LineBreakProhibited
+	case lastNonSpace >= 0 && before == lbQU && b == lbOP: // LB15
+		return LineBreakProhibited
+	case lastNonSpace >= 0 && (before == lbCL || before == lbCP) && b == lbNS: // LB16
+		return LineBreakProhibited
+	case lastNonSpace >= 0 && before == lbB2 && b == lbB2: // LB17
+		return LineBreakProhibited
+	case a == lbSP: // LB18
+		return LineBreakAllowed
+	case a == lbQU || b == lbQU: // LB19
+		return LineBreakProhibited
+	case a == lbCB || b == lbCB: // LB20
+		return LineBreakAllowed
+	case b == lbBA || b == lbHY || b == lbNS || a == lbBB: // LB21
+		return LineBreakProhibited
+	case (a == lbHY || a == lbBA) && i >= 2 && resolved[i-2] == lbHL: // LB21a
+		return LineBreakProhibited
+	case a == lbSY && b == lbHL: // LB21b
+		return LineBreakProhibited
+	case b == lbIN: // LB22
+		return LineBreakProhibited
+	case (a == lbAL || a == lbHL) && b == lbNU, a == lbNU && (b == lbAL || b == lbHL): // LB23
+		return LineBreakProhibited
+	case a == lbPR && b == lbID, a == lbID && b == lbPO: // LB23a
+		return LineBreakProhibited
+	case (a == lbPR || a == lbPO) && (b == lbAL || b == lbHL),
+		(a == lbAL || a == lbHL) && (b == lbPR || b == lbPO): // LB24
+		return LineBreakProhibited
+	case isNumericPair(a, b): // LB25
+		return LineBreakProhibited
+	case a == lbJL && (b == lbJL || b == lbJV || b == lbH2 || b == lbH3),
+		(a == lbJV || a == lbH2) && (b == lbJV || b == lbJT),
+		(a == lbJT || a == lbH3) && b == lbJT: // LB26
+		return LineBreakProhibited
+	case isHangul(a) && b == lbPO, a == lbPR && isHangul(b): // LB27
+		return LineBreakProhibited
+	case (a == lbAL || a == lbHL) && (b == lbAL || b == lbHL): // LB28
+		return LineBreakProhibited
+	case a == lbIS && (b == lbAL || b == lbHL): // LB29
+		return LineBreakProhibited
+	case (a == lbAL || a == lbHL || a == lbNU) && b == lbOP && !isEastAsianWide(runes[i]),
+		a == lbCP && (b == lbAL || b == lbHL || b == lbNU) && !isEastAsianWide(runes[i-1]): // LB30
+		return LineBreakProhibited
+	case a == lbRI && b == lbRI && regionalIndicators%2 == 1: // LB30a
+		return LineBreakProhibited
+	}
+	return LineBreakAllowed // LB31
+}
+
+// isNumericPair checks if two classes are part of a number like "$(12.50)" (LB25)
+func isNumericPair(a, b lineBreakClass) bool {
+	switch {
+	case (a == lbCL || a == lbCP || a == lbNU) && (b == lbPO || b == lbPR):
+		return true
+	case (a == lbPO || a == lbPR) && (b == lbOP || b == lbNU):
+		return true
+	case (a == lbHY || a == lbIS || a == lbNU || a == lbSY) && b == lbNU:
+		return true
+	}
+	return false
+}
+
+// isHangul checks if the class is a Hangul jamo or syllable
+func isHangul(c lineBreakClass) bool {
+	return c == lbJL || c == lbJV || c == lbJT || c == lbH2 || c == lbH3
+}
+
+// isEastAsianWide checks if the rune is a wide or full width character
+func isEastAsianWide(r rune) bool {
+	return r >= 0x2E80 && r <= 0xA4CF || r >= 0xF900 && r <= 0xFAFF || r >= 0xFE30 && r <= 0xFE4F ||
+		r >= 0xFF00 && r <= 0xFF60 || r >= 0xFFE0 && r <= 0xFFE6
+}
+
+// lineBreakClassOf returns the line break class of the rune, resolved according to LB1
+func lineBreakClassOf(r rune) lineBreakClass {
+	switch r {
+	case '\n':
+		return lbLF
+	case '\r':
+		return lbCR
+	case 0x0085:
+		return lbNL
+	case 0x000B, 0x000C, 0x2028, 0x2029:
+		return lbBK
+	case ' ':
+		return lbSP
+	case 0x200B:
+		return lbZW
+	case 0x200D:
+		return lbZWJ
+	case 0x2060, 0xFEFF:
+		return lbWJ
+	case 0x00A0, 0x202F, 0x2007, 0x2011, 0x034F, 0x180E, 0x0F08, 0x0F0C, 0x0F12:
+		return lbGL
+	case '\t', 0x00AD, 0x058A, 0x1680, 0x2010, 0x2012, 0x2013, 0x2027, 0x205F, 0x3000, '|', 0x0F0B, 0x1361,
+		0x17D5, 0x17DA:
+		return lbBA
+	case 0x00B4, 0x02C8, 0x02CC, 0x02DF, 0x1806:
+		return lbBB
+	case 0x2014, 0x2E3A, 0x2E3B:
+		return lbB2
+	case '-':
+		return lbHY
+	case 0xFFFC:
+		return lbCB
+	case ')', ']':
+		return lbCP
+	case '}', 0x3001, 0x3002, 0xFE50, 0xFE52, 0xFF0C, 0xFF0E, 0xFF61, 0xFF64:
+		return lbCL
+	case '!', '?', 0x05C6, 0x061B, 0x061E, 0x061F, 0x06D4, 0x07F9, 0x0F0D, 0x0F0E, 0x0F0F, 0x0F10, 0x0F11,
+		0x0F14, 0x1802, 0x1803, 0x1808, 0x1809, 0x1944, 0x1945, 0x2762, 0x2763, 0x2CF9, 0x2CFE, 0x2E2E, 0xA60E,
+		0xA876, 0xA877, 0xFE15, 0xFE16, 0xFE56, 0xFE57, 0xFF01, 0xFF1F:
+		return lbEX
+	case 0x2024, 0x2025, 0x2026, 0x22EF, 0xFE19:
+		return lbIN
+	case 0x17D6, 0x203C, 0x203D, 0x2047, 0x2048, 0x2049, 0x3005, 0x301C, 0x303B, 0x303C, 0x309B, 0x309C, 0x309D,
+		0x309E, 0x30A0, 0x30FB, 0x30FD, 0x30FE, 0xA015, 0xFE54, 0xFE55, 0xFF1A, 0xFF1B, 0xFF65, 0xFF9E, 0xFF9F,
+		// small kana and prolonged sound marks (CJ, resolved to NS)
+		0x3041, 0x3043, 0x3045, 0x3047, 0x3049, 0x3063, 0x3083, 0x3085, 0x3087, 0x308E, 0x3095, 0x3096, 0x30A1,
+		0x30A3, 0x30A5, 0x30A7, 0x30A9, 0x30C3, 0x30E3, 0x30E5, 0x30E7, 0x30EE, 0x30F5, 0x30F6, 0x30FC:
+		return lbNS
+	case 0x00A1, 0x00BF, 0x2E18:
+		return lbOP
+	case '"', '\'', 0x275B, 0x275C, 0x275D, 0x275E:
+		return lbQU
+	case ',', '.', ':', ';', 0x037E, 0x0589, 0x060C, 0x060D, 0x07F8, 0x2044, 0xFE10, 0xFE13, 0xFE14:
+		return lbIS
+	case '/':
+		return lbSY
+	case '%', 0x00A2, 0x00B0, 0x060B, 0x066A, 0x2030, 0x2031, 0x2032, 0x2033, 0x2034, 0x2035, 0x2036, 0x2037,
+		0x20A7, 0x20B6, 0x2103, 0x2109, 0xFE6A, 0xFF05, 0xFFE0:
+		return lbPO
+	case '$', '+', '\\', 0x00A3, 0x00A4, 0x00A5, 0x00B1, 0x058F, 0x2116, 0x2212, 0x2213, 0xFE69, 0xFF04, 0xFFE1,
+		0xFFE5, 0xFFE6:
+		return lbPR
+	}
+
+	switch {
+	case r >= 0x20A0 && r <= 0x20CF:
+		return lbPR
+	case r >= 0x2000 && r <= 0x200A:
+		return lbBA
+	case r >= 0x2E0E && r <= 0x2E15:
+		return lbBA
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return lbRI
+	case r >= 0x1F3FB && r <= 0x1F3FF:
+		return lbCM
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97F:
+		return lbJL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return lbJV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return lbJT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return lbH2
+		}
+		return lbH3
+	case r >= 0x05D0 && r <= 0x05EA, r >= 0x05EF && r <= 0x05F2, r >= 0xFB1D && r <= 0xFB4F:
+		return lbHL
+	case unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me):
+		return lbCM
+	case unicode.In(r, unicode.Cc, unicode.Cf):
+		return lbCM
+	case unicode.Is(unicode.Ps, r):
+		return lbOP
+	case unicode.Is(unicode.Pe, r):
+		return lbCL
+	case unicode.In(r, unicode.Pi, unicode.Pf):
+		return lbQU
+	case unicode.Is(unicode.Nd, r) && !(r >= 0xFF10 && r <= 0xFF19):
+		return lbNU
+	case isIdeographic(r):
+		return lbID
+	}
+	return lbAL
+}
+
+// isIdeographic checks if the rune is an ideograph, kana, a full width character or an emoji, which may be broken
+// before and after
+func isIdeographic(r rune) bool {
+	switch {
+	case r >= 0x2E80 && r <= 0x2FFF, r >= 0x3003 && r <= 0x303F, r >= 0x3040 && r <= 0x30FF,
+		r >= 0x3100 && r <= 0x31FF, r >= 0x3200 && r <= 0x4DBF, r >= 0x4E00 && r <= 0x9FFF,
+		r >= 0xA000 && r <= 0xA4CF, r >= 0xF900 && r <= 0xFAFF, r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60, r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F000 && r <= 0x1FAFF, r >= 0x20000 && r <= 0x3FFFD:
+		return true
+	}
+	return isExtendedPictographic(r) && r >= 0x2600
+}
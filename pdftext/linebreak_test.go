@@ -0,0 +1,57 @@
+package pdftext
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// samples in the notation of LineBreakTest.txt: the opportunity before every code point is given by "×" (no
+// break), "÷" (break allowed) or "!" (mandatory break)
+func TestLineBreaks(t *testing.T) {
+	tests := []string{
+		"× 0061 × 0020 ÷ 0062",               // space
+		"× 0061 × 0020 × 0020 ÷ 0062",        // no break before spaces (LB7)
+		"× 0061 × 000A ! 0062",               // line feed (LB5)
+		"× 0061 × 000D × 000A ! 0062",        // CR LF (LB5)
+		"× 0061 × 200B ÷ 0062",               // zero width space (LB8)
+		"× 0061 × 0301 × 0062",               // combining mark (LB9)
+		"× 0061 × 00A0 × 0062",               // non-breaking space (LB12)
+		"× 0061 × 0021",                      // exclamation mark (LB13)
+		"× 0028 × 0061 × 0029",               // parentheses (LB13, LB14)
+		"× 0061 ÷ 2014 ÷ 0062",               // em dash (LB17, LB31)
+		"× 0061 × 002D ÷ 0062",               // hyphen (LB21)
+		"× 0024 × 0031 × 002C × 0030 × 0025", // numbers (LB25)
+		"× 4E00 ÷ 4E01",                      // ideographs (LB31)
+		"× 300C × 4E00 × 300D ÷ 4E01",        // ideographic brackets (LB14, LB16)
+		"× 0061 × 0062 × 0063 ÷ 4E00",        // alphabetic and ideographic (LB31)
+		"× 1F1E9 × 1F1EA ÷ 1F1E9 × 1F1EA",    // regional indicator pairs (LB30a)
+	}
+	for _, test := range tests {
+		var runes []rune
+		var want []LineBreak
+		fields := strings.Fields(test)
+		for i := 0; i+1 < len(fields); i += 2 {
+			switch fields[i] {
+			case "×":
+				want = append(want, LineBreakProhibited)
+			case "÷":
+				want = append(want, LineBreakAllowed)
+			case "!":
+				want = append(want, LineBreakMandatory)
+			}
+			v, err := strconv.ParseUint(fields[i+1], 16, 32)
+			if err != nil {
+				t.Fatal(err)
+			}
+			runes = append(runes, rune(v))
+		}
+
+		got := LineBreaks(runes)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: got %v at position %d, want %v", test, got[i], i, want[i])
+			}
+		}
+	}
+}
@@ -5,8 +5,16 @@ import (
 	"github.com/raceresult/gopdf/pdftext/arabic"
 )
 
+// StringModifications prepares a single line of text for display: arabic letters are shaped and the text is
+// reordered according to the bidirectional algorithm
 func StringModifications(s string, font pdf.FontHandler) string {
-	s = arabic.Shape(s, font)
-	s = reverseRTLString(s)
+	return StringModificationsWith(s, font)
+}
+
+// StringModificationsWith is like StringModifications, shaping arabic letters with the forms the given glyph checker,
+// e.g. a chain of fallback fonts, has glyphs for
+func StringModificationsWith(s string, font arabic.GlyphChecker) string {
+	s = arabic.ShapeWith(s, font)
+	s = Reorder(s, DirectionAuto)
 	return s
 }
@@ -0,0 +1,505 @@
+package gopdf
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/raceresult/gopdf/pdf"
+)
+
+// FontFamily holds the fonts of a font family used by rich text markup, see ParseRichText. Styles without a font are
+// synthesized from the closest font of the family.
+type FontFamily struct {
+	Regular    pdf.FontHandler
+	Bold       pdf.FontHandler
+	Italic     pdf.FontHandler
+	BoldItalic pdf.FontHandler
+}
+
+// font returns the font of the family that matches the style best. synthBold and synthItalic are true if the style
+// needs to be synthesized, see TextChunk.Bold and TextChunk.Italic.
+func (q *FontFamily) font(bold, italic bool) (font pdf.FontHandler, synthBold, synthItalic bool) {
+	variants := []struct {
+		font         pdf.FontHandler
+		bold, italic bool
+	}{
+		{q.BoldItalic, true, true},
+		{q.Bold, true, false},
+		{q.Italic, false, true},
+		{q.Regular, false, false},
+	}
+	for _, v := range variants {
+		if v.font == nil || v.bold && !bold || v.italic && !italic {
+			continue
+		}
+		return v.font, bold && !v.bold, italic && !v.italic
+	}
+
+	// no regular font: use any font of the family
+	for _, v := range variants {
+		if v.font != nil {
+			return v.font, false, false
+		}
+	}
+	return nil, false, false
+}
+
+// style returns the style of the font within the family, ok is false if the font is not part of the family
+func (q *FontFamily) style(font pdf.FontHandler) (bold, italic, ok bool) {
+	switch {
+	case font == nil:
+		return false, false, false
+	case font == q.Regular:
+		return false, false, true
+	case font == q.Bold:
+		return true, false, true
+	case font == q.Italic:
+		return false, true, true
+	case font == q.BoldItalic:
+		return true, true, true
+	}
+	return false, false, false
+}
+
+// ParseRichText converts text with inline markup to text chunks, e.g. for TextChunkBoxElement. The chunks have the
+// style of base, changed by these tags:
+//
+//	<b>…</b>                    bold
+//	<i>…</i>                    italic
+//	<u>…</u>                    underlined
+//	<s>…</s>                    struck through
+//	<color="#FF0000">…</color>  text color, see ParseColor
+//	<size=12>…</size>           font size in pt, relative ("+2", "-2") or in percent ("80%")
+//	<font="Noto Sans">…</font>  font family, name of families
+//	<sup>…</sup>                superscript
+//	<sub>…</sub>                subscript
+//	<a href="https://…">…</a>   link
+//	<br>                        line break
+//
+// Bold and italic text uses the fonts of the font family, styles without font are synthesized. The family of the base
+// font is looked up in families (the first by name if several families contain it), if it is not found, bold and
+// italic are synthesized from the base font. Values can be quoted by double or single quotes. Write &lt;, &gt;, &amp;,
+// &quot; and &apos; or numeric character references like &#8364; for the special characters.
+func ParseRichText(markup string, base TextChunk, families map[string]FontFamily) ([]TextChunk, error) {
+	// style of the base chunk
+	style := richTextStyle{chunk: base, bold: base.Bold, italic: base.Italic}
+	style.chunk.Text = ""
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		family := families[name]
+		if bold, italic, ok := family.style(base.Font); ok {
+			style.family = &family
+			style.bold = style.bold || bold
+			style.italic = style.italic || italic
+			break
+		}
+	}
+
+	p := richTextParser{
+		markup:   markup,
+		families: families,
+		stack:    []richTextStyle{style},
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.chunks, nil
+}
+
+// richTextStyle is the style of text within a tag of rich text markup
+type richTextStyle struct {
+	tag          string // name of the tag, empty for the base style
+	pos          int    // position of the tag
+	chunk        TextChunk
+	family       *FontFamily
+	bold, italic bool
+}
+
+// richTextParser converts rich text markup to text chunks, see ParseRichText
+type richTextParser struct {
+	markup   string
+	pos      int
+	families map[string]FontFamily
+	stack    []richTextStyle
+	text     strings.Builder
+	chunks   []TextChunk
+}
+
+// parse parses the markup
+func (q *richTextParser) parse() error {
+	for q.pos < len(q.markup) {
+		switch q.markup[q.pos] {
+		case '<':
+			if err := q.tag(); err != nil {
+				return err
+			}
+		case '&':
+			s, n, err := entity(q.markup[q.pos:])
+			if err != nil {
+				return q.errorAt(q.pos, err.Error())
+			}
+			if n == 0 {
+				s, n = "&", 1
+			}
+			q.text.WriteString(s)
+			q.pos += n
+		default:
+			q.text.WriteByte(q.markup[q.pos])
+			q.pos++
+		}
+	}
+
+	if n := len(q.stack) - 1; n > 0 {
+		return q.errorAt(q.stack[n].pos, "tag <"+q.stack[n].tag+"> not closed")
+	}
+	q.flush()
+	return nil
+}
+
+// flush adds the text parsed so far as chunk with the current style
+func (q *richTextParser) flush() {
+	if q.text.Len() == 0 {
+		return
+	}
+	style := q.stack[len(q.stack)-1]
+	chunk := style.chunk
+	chunk.Text = q.text.String()
+	chunk.Bold = style.bold
+	chunk.Italic = style.italic
+	if style.family != nil {
+		chunk.Font, chunk.Bold, chunk.Italic = style.family.font(style.bold, style.italic)
+	}
+	q.chunks = append(q.chunks, chunk)
+	q.text.Reset()
+}
+
+// tag parses an opening or closing tag
+func (q *richTextParser) tag() error {
+	start := q.pos
+	q.pos++
+	closing := q.consume('/')
+	name := strings.ToLower(q.name())
+	if name == "" {
+		if closing {
+			return q.errorAt(start, "invalid closing tag")
+		}
+		return q.errorAt(start, "invalid tag, write &lt; for the character <")
+	}
+
+	// closing tag
+	if closing {
+		q.skipSpace()
+		if !q.consume('>') {
+			return q.errorAt(start, "invalid closing tag </"+name+">")
+		}
+		return q.close(name, start)
+	}
+
+	// value and attributes
+	var value string
+	var hasValue, selfClosing bool
+	if q.consume('=') {
+		var err error
+		if value, err = q.value(); err != nil {
+			return err
+		}
+		hasValue = true
+	}
+	attrs := make(map[string]string)
+	for {
+		spaced := q.skipSpace()
+		if q.pos >= len(q.markup) {
+			return q.errorAt(start, "tag <"+name+"> not terminated")
+		}
+		if q.consume('>') {
+			break
+		}
+		if q.consume('/') {
+			if !q.consume('>') {
+				return q.errorAt(start, "invalid tag <"+name+">")
+			}
+			selfClosing = true
+			break
+		}
+		attr := strings.ToLower(q.name())
+		if !spaced || attr == "" {
+			return q.errorAt(start, "invalid tag <"+name+">")
+		}
+		if !q.consume('=') {
+			return q.errorAt(start, "attribute "+attr+" of tag <"+name+"> without value")
+		}
+		v, err := q.value()
+		if err != nil {
+			return err
+		}
+		attrs[attr] = v
+	}
+
+	// check value and attributes
+	var allowedAttr string
+	switch name {
+	case "b", "i", "u", "s", "sup", "sub", "br":
+		if hasValue {
+			return q.errorAt(start, "tag <"+name+"> has no value")
+		}
+	case "color", "size", "font":
+		if !hasValue {
+			return q.errorAt(start, "tag <"+name+"> without value, e.g. <"+name+"=…>")
+		}
+	case "a":
+		if hasValue {
+			return q.errorAt(start, "tag <a> has no value, use <a href=\"…\">")
+		}
+		allowedAttr = "href"
+		if _, ok := attrs["href"]; !ok {
+			return q.errorAt(start, "tag <a> without attribute href")
+		}
+	default:
+		return q.errorAt(start, "unknown tag <"+name+">")
+	}
+	for attr := range attrs {
+		if attr != allowedAttr {
+			return q.errorAt(start, "unknown attribute "+attr+" of tag <"+name+">")
+		}
+	}
+
+	// line break
+	if name == "br" {
+		q.text.WriteByte('\n')
+		return nil
+	}
+	if selfClosing {
+		return q.errorAt(start, "tag <"+name+"/> must not be self-closing")
+	}
+	return q.open(name, value, attrs, start)
+}
+
+// open starts the style of the tag
+func (q *richTextParser) open(name, value string, attrs map[string]string, pos int) error {
+	style := q.stack[len(q.stack)-1]
+	style.tag = name
+	style.pos = pos
+
+	switch name {
+	case "b":
+		style.bold = true
+	case "i":
+		style.italic = true
+	case "u":
+		style.chunk.Underline = true
+	case "s":
+		style.chunk.StrikeThrough = true
+	case "sup":
+		style.chunk.Script = TextScriptSuperscript
+	case "sub":
+		style.chunk.Script = TextScriptSubscript
+	case "color":
+		c, err := ParseColor(value)
+		if err != nil {
+			return q.errorAt(pos, "invalid color \""+value+"\"")
+		}
+		style.chunk.Color = c
+	case "size":
+		size, ok := parseFontSize(value, style.chunk.FontSize)
+		if !ok {
+			return q.errorAt(pos, "invalid font size \""+value+"\"")
+		}
+		style.chunk.FontSize = size
+	case "font":
+		family, ok := lookupFontFamily(q.families, value)
+		if !ok {
+			return q.errorAt(pos, "font family \""+value+"\" not found")
+		}
+		style.family = &family
+	case "a":
+		style.chunk.Link = attrs["href"]
+	}
+
+	q.flush()
+	q.stack = append(q.stack, style)
+	return nil
+}
+
+// close ends the style of the tag
+func (q *richTextParser) close(name string, pos int) error {
+	n := len(q.stack) - 1
+	if n == 0 {
+		return q.errorAt(pos, "unexpected closing tag </"+name+">")
+	}
+	if q.stack[n].tag != name {
+		return errors.New("rich text: tag <" + q.stack[n].tag + "> at position " + strconv.Itoa(q.position(q.stack[n].pos)) +
+			" closed by </" + name + "> at position " + strconv.Itoa(q.position(pos)))
+	}
+
+	q.flush()
+	q.stack = q.stack[:n]
+	return nil
+}
+
+// name parses a tag or attribute name
+func (q *richTextParser) name() string {
+	start := q.pos
+	for q.pos < len(q.markup) {
+		c := q.markup[q.pos]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || q.pos > start && c == '-') {
+			break
+		}
+		q.pos++
+	}
+	return q.markup[start:q.pos]
+}
+
+// value parses a value of a tag or attribute, quoted or up to the next white space or >
+func (q *richTextParser) value() (string, error) {
+	start := q.pos
+	var raw string
+	if q.pos < len(q.markup) && (q.markup[q.pos] == '"' || q.markup[q.pos] == '\'') {
+		end := strings.IndexByte(q.markup[q.pos+1:], q.markup[q.pos])
+		if end < 0 {
+			return "", q.errorAt(start, "unterminated quoted value")
+		}
+		raw = q.markup[q.pos+1 : q.pos+1+end]
+		q.pos += end + 2
+	} else {
+		for q.pos < len(q.markup) && !isMarkupSpace(q.markup[q.pos]) && q.markup[q.pos] != '>' {
+			q.pos++
+		}
+		raw = q.markup[start:q.pos]
+	}
+
+	// character references
+	var sb strings.Builder
+	for i := 0; i < len(raw); {
+		if raw[i] != '&' {
+			sb.WriteByte(raw[i])
+			i++
+			continue
+		}
+		s, n, err := entity(raw[i:])
+		if err != nil {
+			return "", q.errorAt(start, err.Error())
+		}
+		if n == 0 {
+			s, n = "&", 1
+		}
+		sb.WriteString(s)
+		i += n
+	}
+	return sb.String(), nil
+}
+
+// consume skips the byte if it is next
+func (q *richTextParser) consume(c byte) bool {
+	if q.pos < len(q.markup) && q.markup[q.pos] == c {
+		q.pos++
+		return true
+	}
+	return false
+}
+
+// skipSpace skips white space and returns if there was any
+func (q *richTextParser) skipSpace() bool {
+	start := q.pos
+	for q.pos < len(q.markup) && isMarkupSpace(q.markup[q.pos]) {
+		q.pos++
+	}
+	return q.pos > start
+}
+
+// position returns the character position (starting from 1) of the byte offset in the markup
+func (q *richTextParser) position(pos int) int {
+	return utf8.RuneCountInString(q.markup[:pos]) + 1
+}
+
+// errorAt returns an error for the markup at the byte offset
+func (q *richTextParser) errorAt(pos int, msg string) error {
+	return errors.New("rich text: " + msg + " at position " + strconv.Itoa(q.position(pos)))
+}
+
+func isMarkupSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// markupEntities are the named character references of rich text markup
+var markupEntities = map[string]string{
+	"lt":   "<",
+	"gt":   ">",
+	"amp":  "&",
+	"quot": "\"",
+	"apos": "'",
+	"nbsp": "\u00a0",
+	"shy":  "\u00ad",
+}
+
+// entity decodes the character reference at the beginning of s, e.g. "&amp;" or "&#8364;", and returns the text and
+// the number of bytes read. n is 0 if s does not start with something like a character reference, so that the
+// ampersand is taken literally.
+func entity(s string) (text string, n int, err error) {
+	end := strings.IndexByte(s, ';')
+	if end < 2 || end > 10 {
+		return "", 0, nil
+	}
+	name := s[1:end]
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || i == 0 && c == '#') {
+			return "", 0, nil
+		}
+	}
+
+	if name[0] == '#' {
+		var v uint64
+		if len(name) > 1 && (name[1] == 'x' || name[1] == 'X') {
+			v, err = strconv.ParseUint(name[2:], 16, 32)
+		} else {
+			v, err = strconv.ParseUint(name[1:], 10, 32)
+		}
+		if err != nil || v == 0 || !utf8.ValidRune(rune(v)) {
+			return "", 0, errors.New("invalid character reference " + s[:end+1])
+		}
+		return string(rune(v)), end + 1, nil
+	}
+	if v, ok := markupEntities[name]; ok {
+		return v, end + 1, nil
+	}
+	return "", 0, errors.New("unknown character reference " + s[:end+1])
+}
+
+// parseFontSize parses an absolute font size in pt, a relative size like "+2" or "-2" or a percentage like "80%"
+func parseFontSize(s string, current float64) (float64, bool) {
+	s = strings.TrimSpace(s)
+	var size float64
+	var err error
+	switch {
+	case strings.HasSuffix(s, "%"):
+		size, err = strconv.ParseFloat(strings.TrimSpace(s[:len(s)-1]), 64)
+		size *= current / 100
+	case strings.HasPrefix(s, "+"), strings.HasPrefix(s, "-"):
+		size, err = strconv.ParseFloat(s, 64)
+		size += current
+	default:
+		size, err = strconv.ParseFloat(s, 64)
+	}
+	return size, err == nil && size > 0
+}
+
+// lookupFontFamily returns the font family by name, ignoring case and white space differences
+func lookupFontFamily(families map[string]FontFamily, name string) (FontFamily, bool) {
+	if family, ok := families[name]; ok {
+		return family, true
+	}
+	name = normalizeFamilyName(name)
+	for k, family := range families {
+		if normalizeFamilyName(k) == name {
+			return family, true
+		}
+	}
+	return FontFamily{}, false
+}
@@ -0,0 +1,36 @@
+package gopdf
+
+import (
+	"testing"
+
+	"github.com/raceresult/gopdf/pdf"
+	"github.com/raceresult/gopdf/types"
+)
+
+func TestParseRichTextBaseFamily(t *testing.T) {
+	b := New()
+	newFont := func(name types.StandardFontName) *pdf.StandardFont {
+		font, err := b.NewStandardFont(name, types.EncodingWinAnsi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return font
+	}
+	regular := newFont(types.StandardFont_Helvetica)
+	bold := newFont(types.StandardFont_HelveticaBold)
+
+	// the base font belongs to both families, the first by name is used
+	families := map[string]FontFamily{
+		"Helvetica":       {Regular: regular, Bold: bold},
+		"Helvetica Light": {Regular: regular},
+	}
+	for i := 0; i < 20; i++ {
+		chunks, err := ParseRichText("<b>bold</b>", TextChunk{Font: regular, FontSize: 12}, families)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chunks) != 1 || chunks[0].Font != bold || chunks[0].Bold {
+			t.Fatalf("bold text not drawn in the bold font of the first family")
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package gopdf
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	"github.com/raceresult/gopdf/parser"
+	"github.com/raceresult/gopdf/pdf"
+	"github.com/raceresult/gopdf/types"
+)
+
+// Stamper adds elements to the pages of an existing pdf, e.g. watermarks like "PROVISIONAL" or "COPY". In contrast to
+// NewCapturedPage, the page structure including annotations stays unchanged.
+type Stamper struct {
+	// Threshold length for compressing content streams
+	CompressStreamsThreshold int
+
+	// if set, the changes are appended to the original file as incremental update instead of rewriting the file
+	Incremental bool
+
+	// internals
+	stamper    *pdf.Stamper
+	foreground map[int][]Element
+	background map[int][]Element
+}
+
+// NewStamper parses the given pdf and creates a new Stamper object
+func NewStamper(bts []byte) (*Stamper, error) {
+	p, err := parser.New(bts)
+	if err != nil {
+		return nil, err
+	}
+	s, err := pdf.NewStamper(p.File(), bts)
+	if err != nil {
+		return nil, err
+	}
+	return &Stamper{
+		CompressStreamsThreshold: 500,
+		stamper:                  s,
+		foreground:               make(map[int][]Element),
+		background:               make(map[int][]Element),
+	}, nil
+}
+
+// PageCount returns the number of pages of the pdf
+func (q *Stamper) PageCount() int {
+	return q.stamper.PageCount()
+}
+
+// PageSize returns the size of the page with the given number (first page = 1)
+func (q *Stamper) PageSize(pageNo int) (PageSize, error) {
+	p, err := q.stamper.GetPage(pageNo)
+	if err != nil {
+		return PageSize{}, err
+	}
+	return PageSize{
+		Pt(float64(p.MediaBox.URX - p.MediaBox.LLX)),
+		Pt(float64(p.MediaBox.URY - p.MediaBox.LLY)),
+	}, nil
+}
+
+// AddElement adds one or more elements above the existing content of the page with the given number (first page = 1)
+func (q *Stamper) AddElement(pageNo int, item ...Element) error {
+	if _, err := q.stamper.Foreground(pageNo); err != nil {
+		return err
+	}
+	q.foreground[pageNo] = append(q.foreground[pageNo], item...)
+	return nil
+}
+
+// AddBackgroundElement adds one or more elements below the existing content of the page with the given number
+// (first page = 1)
+func (q *Stamper) AddBackgroundElement(pageNo int, item ...Element) error {
+	if _, err := q.stamper.Background(pageNo); err != nil {
+		return err
+	}
+	q.background[pageNo] = append(q.background[pageNo], item...)
+	return nil
+}
+
+// Build builds the PDF document and returns the file as byte slice
+func (q *Stamper) Build() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := q.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo writes the PDF bytes into the given writer
+func (q *Stamper) WriteTo(w io.Writer) (int64, error) {
+	// pages in order, so that the output does not depend on map iteration
+	for _, pageNo := range sortedPageNos(q.background) {
+		page, err := q.stamper.Background(pageNo)
+		if err != nil {
+			return 0, err
+		}
+		for _, item := range q.background[pageNo] {
+			if _, err := item.Build(page); err != nil {
+				return 0, err
+			}
+		}
+	}
+	for _, pageNo := range sortedPageNos(q.foreground) {
+		page, err := q.stamper.Foreground(pageNo)
+		if err != nil {
+			return 0, err
+		}
+		for _, item := range q.foreground[pageNo] {
+			if _, err := item.Build(page); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	q.stamper.File().CompressStreamsThreshold = q.CompressStreamsThreshold
+	return q.stamper.WriteTo(w, q.Incremental)
+}
+
+// NewStandardFont adds a new standard font (expected to be available in all PDF consuming systems) to the pdf
+func (q *Stamper) NewStandardFont(name types.StandardFontName, encoding types.Encoding) (*pdf.StandardFont, error) {
+	return q.stamper.File().NewStandardFont(name, encoding)
+}
+
+// NewStandardFontWithEncoding adds a new standard font with a predefined or custom encoding, e.g. to use glyphs of
+// Symbol and ZapfDingbats by Unicode characters
+func (q *Stamper) NewStandardFontWithEncoding(name types.StandardFontName, encoding types.SimpleEncoding) (*pdf.StandardFont, error) {
+	return q.stamper.File().NewStandardFontWithEncoding(name, encoding)
+}
+
+// NewType1Font adds a new PostScript Type 1 font given as font program (pfb or pfa) and font metrics (afm) to the pdf.
+// If encoding is nil, the built-in encoding of the font is used.
+func (q *Stamper) NewType1Font(program, afm []byte, encoding types.SimpleEncoding) (*pdf.Type1Font, error) {
+	return q.stamper.File().NewType1Font(program, afm, encoding)
+}
+
+// NewTrueTypeFont adds a new TrueType font to the pdf
+func (q *Stamper) NewTrueTypeFont(ttf []byte, encoding types.Encoding, embed bool) (*pdf.TrueTypeFont, error) {
+	return q.stamper.File().NewTrueTypeFont(ttf, encoding, embed)
+}
+
+// NewTrueTypeFontWithEncoding adds a new TrueType font with a predefined or custom encoding to the pdf
+func (q *Stamper) NewTrueTypeFontWithEncoding(ttf []byte, encoding types.SimpleEncoding, embed bool) (*pdf.TrueTypeFont, error) {
+	return q.stamper.File().NewTrueTypeFontWithEncoding(ttf, encoding, embed)
+}
+
+// NewTrueTypeFontFromCollection adds the font with the given index of a font collection (ttc) as TrueType font to
+// the pdf
+func (q *Stamper) NewTrueTypeFontFromCollection(ttc []byte, index int, encoding types.SimpleEncoding, embed bool) (*pdf.TrueTypeFont, error) {
+	return q.stamper.File().NewTrueTypeFontFromCollection(ttc, index, encoding, embed)
+}
+
+// NewCompositeFont adds a font as composite font to the pdf, i.e. with Unicode support
+func (q *Stamper) NewCompositeFont(ttf []byte) (*pdf.CompositeFont, error) {
+	return q.stamper.File().NewCompositeFontFromTTF(ttf, nil)
+}
+
+// NewCompositeFontFromCollection adds the font with the given index of a font collection (ttc) as composite font to
+// the pdf
+func (q *Stamper) NewCompositeFontFromCollection(ttc []byte, index int) (*pdf.CompositeFont, error) {
+	return q.stamper.File().NewCompositeFontFromCollection(ttc, index, nil)
+}
+
+// NewImage adds a new image to the PDF file
+func (q *Stamper) NewImage(bts []byte) (*pdf.Image, error) {
+	return q.stamper.File().NewImage(bts)
+}
+
+// sortedPageNos returns the page numbers of the map in ascending order
+func sortedPageNos(m map[int][]Element) []int {
+	res := make([]int, 0, len(m))
+	for pageNo := range m {
+		res = append(res, pageNo)
+	}
+	sort.Ints(res)
+	return res
+}
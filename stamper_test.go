@@ -0,0 +1,54 @@
+package gopdf
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/raceresult/gopdf/types"
+)
+
+func TestStamperPageOrder(t *testing.T) {
+	b := New()
+	for i := 0; i < 8; i++ {
+		b.NewPage(GetStandardPageSize(PageSizeA4, false))
+	}
+	source, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewStamper(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.CompressStreamsThreshold = math.MaxInt32
+	font, err := s.NewStandardFont(types.StandardFont_Helvetica, types.EncodingWinAnsi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pageNo := 1; pageNo <= 8; pageNo++ {
+		text := &TextElement{TextChunk: TextChunk{Text: "Page " + strconv.Itoa(pageNo), Font: font, FontSize: 12}}
+		if err := s.AddElement(pageNo, text); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddBackgroundElement(pageNo, &RectElement{Width: Pt(10), Height: Pt(10)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bts, err := s.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the stamps are written in page order
+	last := -1
+	for pageNo := 1; pageNo <= 8; pageNo++ {
+		pos := bytes.Index(bts, []byte("(Page "+strconv.Itoa(pageNo)+")"))
+		if pos <= last {
+			t.Fatalf("stamp of page %d not written in page order", pageNo)
+		}
+		last = pos
+	}
+}
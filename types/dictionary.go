@@ -1,18 +1,28 @@
 package types
 
-import "bytes"
+import (
+	"bytes"
+	"sort"
+)
 
 // PDF Reference 1.4, 3.2.6 Dictionary Objects
 
 type Dictionary map[Name]Object
 
+// ToRawBytes writes the entries sorted by key: with map iteration order, writing the same document twice would give
+// different files, which breaks comparing output, caching by checksum and reproducible builds
 func (q Dictionary) ToRawBytes() []byte {
 	sb := bytes.Buffer{}
 	sb.WriteString("<<\n")
-	for k, v := range q {
+	keys := make([]Name, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	for _, k := range keys {
 		sb.Write(k.ToRawBytes())
 		sb.WriteString(" ")
-		sb.Write(v.ToRawBytes())
+		sb.Write(q[k].ToRawBytes())
 		sb.WriteString("\n")
 	}
 	sb.WriteString(">>\n")
@@ -0,0 +1,19 @@
+package types
+
+import "testing"
+
+func TestDictionaryToRawBytes(t *testing.T) {
+	d := Dictionary{
+		"Type":     Name("Page"),
+		"Parent":   Reference{Number: 2},
+		"MediaBox": Array{Int(0), Int(0), Int(595), Int(842)},
+		"Contents": Reference{Number: 4},
+		"Rotate":   Int(90),
+	}
+	want := "<<\n/Contents 4 0 R\n/MediaBox [0 0 595 842]\n/Parent 2 0 R\n/Rotate 90\n/Type /Page\n>>\n"
+	for i := 0; i < 10; i++ {
+		if got := string(d.ToRawBytes()); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
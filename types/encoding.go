@@ -1,6 +1,12 @@
 package types
 
-import "golang.org/x/text/encoding/charmap"
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/encoding/charmap"
+)
 
 // PDF Reference 1.4, Table D.1 Latin-text encodings
 
@@ -14,6 +20,23 @@ const (
 	EncodingMacExpert Encoding = "MacExpertEncoding"
 )
 
+// SimpleEncoding is the character encoding of a simple font: characters are mapped to single-byte character codes,
+// character codes to glyph names. Implemented by the predefined encodings (Encoding) and CustomEncoding.
+type SimpleEncoding interface {
+	// Encode converts the text to character codes
+	Encode(s string) string
+
+	// GlyphName returns the name of the glyph of the character code, empty if the code is not defined
+	GlyphName(code byte) string
+
+	// Rune returns the character of the character code, 0 if the code is not defined
+	Rune(code byte) rune
+
+	// EncodingEntry returns the value of the Encoding entry of the font dictionary, nil for the built-in encoding of
+	// the font
+	EncodingEntry() Object
+}
+
 func (q Encoding) ToRawBytes() []byte {
 	return Name(q).ToRawBytes()
 }
@@ -26,15 +49,76 @@ func (q Encoding) Encode(s string) string {
 	case EncodingMacExpert:
 		// todo
 		return s
+	case EncodingMacRoman, EncodingStandard, EncodingPDFDoc:
+		return encodeRunes(s, q.codes())
+	default:
+		return s
+	}
+}
+
+// GlyphName returns the name of the glyph of the character code, empty if the code is not defined
+func (q Encoding) GlyphName(code byte) string {
+	names := q.names()
+	if names == nil {
+		return ""
+	}
+	return names[code]
+}
+
+// Rune returns the character of the character code, 0 if the code is not defined
+func (q Encoding) Rune(code byte) rune {
+	r, _ := GlyphNameToRune(q.GlyphName(code))
+	return r
+}
+
+// EncodingEntry returns the value of the Encoding entry of the font dictionary. StandardEncoding is the built-in
+// encoding of non-symbolic fonts, PDFDocEncoding is not allowed as font encoding and therefore written as Differences
+// to StandardEncoding.
+func (q Encoding) EncodingEntry() Object {
+	switch q {
+	case "", EncodingStandard:
+		return nil
+	case EncodingPDFDoc:
+		return EncodingDictionary{Differences: differences(&pdfDocEncodingNames, &standardEncodingNames)}
+	default:
+		return q
+	}
+}
+
+// names returns the glyph names by character code, nil if unknown
+func (q Encoding) names() *[256]string {
+	switch q {
+	case EncodingStandard:
+		return &standardEncodingNames
 	case EncodingMacRoman:
-		x, _ := charmap.Macintosh.NewEncoder().String(s)
-		return x
+		return &macRomanEncodingNames
+	case EncodingWinAnsi:
+		return &winAnsiEncodingNames
 	case EncodingPDFDoc:
-		// todo
-		return s
+		return &pdfDocEncodingNames
 	default:
-		return s
+		return nil
+	}
+}
+
+var (
+	encodingCodes    = map[Encoding]map[rune]byte{}
+	encodingCodesMux sync.Mutex
+)
+
+// codes returns the character codes by character
+func (q Encoding) codes() map[rune]byte {
+	encodingCodesMux.Lock()
+	defer encodingCodesMux.Unlock()
+	codes, ok := encodingCodes[q]
+	if !ok {
+		codes = make(map[rune]byte)
+		if names := q.names(); names != nil {
+			addCodes(codes, names)
+		}
+		encodingCodes[q] = codes
 	}
+	return codes
 }
 
 func (q Encoding) Copy(_ func(reference Reference) Reference) Object {
@@ -49,3 +133,74 @@ func (q Encoding) Equal(obj Object) bool {
 	return q == a
 
 }
+
+// GlyphNameToRune returns the character of a glyph name: names of the Latin character set and names of the form
+// uniXXXX or uXXXX[XX]
+func GlyphNameToRune(name string) (rune, bool) {
+	if r, ok := glyphNameRunes[name]; ok {
+		return r, true
+	}
+	var hex string
+	switch {
+	case strings.HasPrefix(name, "uni") && len(name) == 7:
+		hex = name[3:]
+	case strings.HasPrefix(name, "u") && len(name) >= 5 && len(name) <= 7:
+		hex = name[1:]
+	default:
+		return 0, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || strings.ToUpper(hex) != hex {
+		return 0, false
+	}
+	return rune(v), true
+}
+
+// addCodes adds the codes of the glyph names to the map, the lowest code is used for glyphs defined several times.
+// No-break space and soft hyphen are mapped to space and hyphen.
+func addCodes(codes map[rune]byte, names *[256]string) {
+	for c := 255; c >= 0; c-- {
+		r, ok := GlyphNameToRune(names[c])
+		if !ok {
+			continue
+		}
+		codes[r] = byte(c)
+		switch r {
+		case ' ':
+			codes['\u00a0'] = byte(c)
+		case '-':
+			codes['\u00ad'] = byte(c)
+		}
+	}
+}
+
+// encodeRunes converts the text to character codes, characters not encoded are replaced by a question mark
+func encodeRunes(s string, codes map[rune]byte) string {
+	bts := make([]byte, 0, len(s))
+	for _, r := range s {
+		c, ok := codes[r]
+		if !ok {
+			c = codes['?']
+		}
+		bts = append(bts, c)
+	}
+	return string(bts)
+}
+
+// differences returns the Differences array of an encoding dictionary for the glyph names compared to the base
+// encoding
+func differences(names, base *[256]string) Array {
+	var res Array
+	prev := -2
+	for c, n := range names {
+		if n == "" || n == base[c] {
+			continue
+		}
+		if c != prev+1 {
+			res = append(res, Int(c))
+		}
+		res = append(res, Name(n))
+		prev = c
+	}
+	return res
+}
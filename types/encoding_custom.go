@@ -0,0 +1,122 @@
+package types
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// CustomEncoding is an encoding of a simple font consisting of a predefined base encoding and additional characters
+// mapped to glyph names of the font, e.g. {'Ł': "Lslash", '✓': "a19"}. The additional characters are assigned to
+// character codes not used by the base encoding. The encoding is written as encoding dictionary with a Differences
+// array.
+type CustomEncoding struct {
+	base  Encoding
+	names [256]string
+	runes [256]rune
+	codes map[rune]byte
+}
+
+// NewCustomEncoding creates a new encoding from the base encoding (StandardEncoding, MacRomanEncoding,
+// WinAnsiEncoding, PDFDocEncoding or empty for the built-in encoding of the font, e.g. of symbolic fonts like
+// ZapfDingbats) and the glyph names of additional characters
+func NewCustomEncoding(base Encoding, glyphs map[rune]string) (*CustomEncoding, error) {
+	q := &CustomEncoding{
+		base:  base,
+		codes: make(map[rune]byte),
+	}
+	if base != "" {
+		names := base.names()
+		if names == nil {
+			return nil, errors.New("base encoding " + string(base) + " not supported")
+		}
+		q.names = *names
+		addCodes(q.codes, names)
+		for c, n := range q.names {
+			q.runes[c], _ = GlyphNameToRune(n)
+		}
+	}
+
+	// characters in order, so that the codes do not depend on the map order
+	runes := make([]rune, 0, len(glyphs))
+	for r := range glyphs {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	next := 0
+	for _, r := range runes {
+		name := glyphs[r]
+		if name == "" {
+			return nil, errors.New("missing glyph name for character " + strconv.QuoteRune(r))
+		}
+
+		// glyph already in base encoding
+		found := false
+		for c, n := range q.names {
+			if n == name {
+				q.codes[r] = byte(c)
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		// next free code
+		for next < len(customEncodingCodes) && q.names[customEncodingCodes[next]] != "" {
+			next++
+		}
+		if next == len(customEncodingCodes) {
+			return nil, errors.New("no free character code for character " + strconv.QuoteRune(r))
+		}
+		c := customEncodingCodes[next]
+		q.names[c] = name
+		q.runes[c] = r
+		q.codes[r] = c
+	}
+	return q, nil
+}
+
+// customEncodingCodes contains the character codes in the order they are assigned to additional characters. Code 32
+// is not used since word spacing is applied to it.
+var customEncodingCodes = func() []byte {
+	var res []byte
+	for c := 0x21; c <= 0xFF; c++ {
+		if c != 0x7F {
+			res = append(res, byte(c))
+		}
+	}
+	for c := 0x01; c < 0x20; c++ {
+		res = append(res, byte(c))
+	}
+	return append(res, 0x7F)
+}()
+
+// Encode converts the text to character codes, characters not encoded are replaced by a question mark
+func (q *CustomEncoding) Encode(s string) string {
+	return encodeRunes(s, q.codes)
+}
+
+// GlyphName returns the name of the glyph of the character code, empty if the code is not defined
+func (q *CustomEncoding) GlyphName(code byte) string {
+	return q.names[code]
+}
+
+// Rune returns the character of the character code, 0 if the code is not defined
+func (q *CustomEncoding) Rune(code byte) rune {
+	return q.runes[code]
+}
+
+// EncodingEntry returns the encoding dictionary with the differences to the base encoding
+func (q *CustomEncoding) EncodingEntry() Object {
+	switch q.base {
+	case EncodingWinAnsi, EncodingMacRoman:
+		return EncodingDictionary{BaseEncoding: q.base, Differences: differences(&q.names, q.base.names())}
+	case EncodingStandard, EncodingPDFDoc:
+		return EncodingDictionary{Differences: differences(&q.names, &standardEncodingNames)}
+	default:
+		return EncodingDictionary{Differences: differences(&q.names, &[256]string{})}
+	}
+}
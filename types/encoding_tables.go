@@ -0,0 +1,1085 @@
+package types
+
+// Glyph names of the predefined encodings of simple fonts, PDF Reference 1.7, Appendix D, and the Unicode values of
+// these glyph names according to the Adobe Glyph List
+
+// standardEncodingNames contains the glyph names of StandardEncoding by character code
+var standardEncodingNames = [256]string{
+	0x20: "space",
+	0x21: "exclam",
+	0x22: "quotedbl",
+	0x23: "numbersign",
+	0x24: "dollar",
+	0x25: "percent",
+	0x26: "ampersand",
+	0x27: "quoteright",
+	0x28: "parenleft",
+	0x29: "parenright",
+	0x2A: "asterisk",
+	0x2B: "plus",
+	0x2C: "comma",
+	0x2D: "hyphen",
+	0x2E: "period",
+	0x2F: "slash",
+	0x30: "zero",
+	0x31: "one",
+	0x32: "two",
+	0x33: "three",
+	0x34: "four",
+	0x35: "five",
+	0x36: "six",
+	0x37: "seven",
+	0x38: "eight",
+	0x39: "nine",
+	0x3A: "colon",
+	0x3B: "semicolon",
+	0x3C: "less",
+	0x3D: "equal",
+	0x3E: "greater",
+	0x3F: "question",
+	0x40: "at",
+	0x41: "A",
+	0x42: "B",
+	0x43: "C",
+	0x44: "D",
+	0x45: "E",
+	0x46: "F",
+	0x47: "G",
+	0x48: "H",
+	0x49: "I",
+	0x4A: "J",
+	0x4B: "K",
+	0x4C: "L",
+	0x4D: "M",
+	0x4E: "N",
+	0x4F: "O",
+	0x50: "P",
+	0x51: "Q",
+	0x52: "R",
+	0x53: "S",
+	0x54: "T",
+	0x55: "U",
+	0x56: "V",
+	0x57: "W",
+	0x58: "X",
+	0x59: "Y",
+	0x5A: "Z",
+	0x5B: "bracketleft",
+	0x5C: "backslash",
+	0x5D: "bracketright",
+	0x5E: "asciicircum",
+	0x5F: "underscore",
+	0x60: "quoteleft",
+	0x61: "a",
+	0x62: "b",
+	0x63: "c",
+	0x64: "d",
+	0x65: "e",
+	0x66: "f",
+	0x67: "g",
+	0x68: "h",
+	0x69: "i",
+	0x6A: "j",
+	0x6B: "k",
+	0x6C: "l",
+	0x6D: "m",
+	0x6E: "n",
+	0x6F: "o",
+	0x70: "p",
+	0x71: "q",
+	0x72: "r",
+	0x73: "s",
+	0x74: "t",
+	0x75: "u",
+	0x76: "v",
+	0x77: "w",
+	0x78: "x",
+	0x79: "y",
+	0x7A: "z",
+	0x7B: "braceleft",
+	0x7C: "bar",
+	0x7D: "braceright",
+	0x7E: "asciitilde",
+	0xA1: "exclamdown",
+	0xA2: "cent",
+	0xA3: "sterling",
+	0xA4: "fraction",
+	0xA5: "yen",
+	0xA6: "florin",
+	0xA7: "section",
+	0xA8: "currency",
+	0xA9: "quotesingle",
+	0xAA: "quotedblleft",
+	0xAB: "guillemotleft",
+	0xAC: "guilsinglleft",
+	0xAD: "guilsinglright",
+	0xAE: "fi",
+	0xAF: "fl",
+	0xB1: "endash",
+	0xB2: "dagger",
+	0xB3: "daggerdbl",
+	0xB4: "periodcentered",
+	0xB6: "paragraph",
+	0xB7: "bullet",
+	0xB8: "quotesinglbase",
+	0xB9: "quotedblbase",
+	0xBA: "quotedblright",
+	0xBB: "guillemotright",
+	0xBC: "ellipsis",
+	0xBD: "perthousand",
+	0xBF: "questiondown",
+	0xC1: "grave",
+	0xC2: "acute",
+	0xC3: "circumflex",
+	0xC4: "tilde",
+	0xC5: "macron",
+	0xC6: "breve",
+	0xC7: "dotaccent",
+	0xC8: "dieresis",
+	0xCA: "ring",
+	0xCB: "cedilla",
+	0xCD: "hungarumlaut",
+	0xCE: "ogonek",
+	0xCF: "caron",
+	0xD0: "emdash",
+	0xE1: "AE",
+	0xE3: "ordfeminine",
+	0xE8: "Lslash",
+	0xE9: "Oslash",
+	0xEA: "OE",
+	0xEB: "ordmasculine",
+	0xF1: "ae",
+	0xF5: "dotlessi",
+	0xF8: "lslash",
+	0xF9: "oslash",
+	0xFA: "oe",
+	0xFB: "germandbls",
+}
+
+// macRomanEncodingNames contains the glyph names of MacRomanEncoding by character code
+var macRomanEncodingNames = [256]string{
+	0x20: "space",
+	0x21: "exclam",
+	0x22: "quotedbl",
+	0x23: "numbersign",
+	0x24: "dollar",
+	0x25: "percent",
+	0x26: "ampersand",
+	0x27: "quotesingle",
+	0x28: "parenleft",
+	0x29: "parenright",
+	0x2A: "asterisk",
+	0x2B: "plus",
+	0x2C: "comma",
+	0x2D: "hyphen",
+	0x2E: "period",
+	0x2F: "slash",
+	0x30: "zero",
+	0x31: "one",
+	0x32: "two",
+	0x33: "three",
+	0x34: "four",
+	0x35: "five",
+	0x36: "six",
+	0x37: "seven",
+	0x38: "eight",
+	0x39: "nine",
+	0x3A: "colon",
+	0x3B: "semicolon",
+	0x3C: "less",
+	0x3D: "equal",
+	0x3E: "greater",
+	0x3F: "question",
+	0x40: "at",
+	0x41: "A",
+	0x42: "B",
+	0x43: "C",
+	0x44: "D",
+	0x45: "E",
+	0x46: "F",
+	0x47: "G",
+	0x48: "H",
+	0x49: "I",
+	0x4A: "J",
+	0x4B: "K",
+	0x4C: "L",
+	0x4D: "M",
+	0x4E: "N",
+	0x4F: "O",
+	0x50: "P",
+	0x51: "Q",
+	0x52: "R",
+	0x53: "S",
+	0x54: "T",
+	0x55: "U",
+	0x56: "V",
+	0x57: "W",
+	0x58: "X",
+	0x59: "Y",
+	0x5A: "Z",
+	0x5B: "bracketleft",
+	0x5C: "backslash",
+	0x5D: "bracketright",
+	0x5E: "asciicircum",
+	0x5F: "underscore",
+	0x60: "grave",
+	0x61: "a",
+	0x62: "b",
+	0x63: "c",
+	0x64: "d",
+	0x65: "e",
+	0x66: "f",
+	0x67: "g",
+	0x68: "h",
+	0x69: "i",
+	0x6A: "j",
+	0x6B: "k",
+	0x6C: "l",
+	0x6D: "m",
+	0x6E: "n",
+	0x6F: "o",
+	0x70: "p",
+	0x71: "q",
+	0x72: "r",
+	0x73: "s",
+	0x74: "t",
+	0x75: "u",
+	0x76: "v",
+	0x77: "w",
+	0x78: "x",
+	0x79: "y",
+	0x7A: "z",
+	0x7B: "braceleft",
+	0x7C: "bar",
+	0x7D: "braceright",
+	0x7E: "asciitilde",
+	0x80: "Adieresis",
+	0x81: "Aring",
+	0x82: "Ccedilla",
+	0x83: "Eacute",
+	0x84: "Ntilde",
+	0x85: "Odieresis",
+	0x86: "Udieresis",
+	0x87: "aacute",
+	0x88: "agrave",
+	0x89: "acircumflex",
+	0x8A: "adieresis",
+	0x8B: "atilde",
+	0x8C: "aring",
+	0x8D: "ccedilla",
+	0x8E: "eacute",
+	0x8F: "egrave",
+	0x90: "ecircumflex",
+	0x91: "edieresis",
+	0x92: "iacute",
+	0x93: "igrave",
+	0x94: "icircumflex",
+	0x95: "idieresis",
+	0x96: "ntilde",
+	0x97: "oacute",
+	0x98: "ograve",
+	0x99: "ocircumflex",
+	0x9A: "odieresis",
+	0x9B: "otilde",
+	0x9C: "uacute",
+	0x9D: "ugrave",
+	0x9E: "ucircumflex",
+	0x9F: "udieresis",
+	0xA0: "dagger",
+	0xA1: "degree",
+	0xA2: "cent",
+	0xA3: "sterling",
+	0xA4: "section",
+	0xA5: "bullet",
+	0xA6: "paragraph",
+	0xA7: "germandbls",
+	0xA8: "registered",
+	0xA9: "copyright",
+	0xAA: "trademark",
+	0xAB: "acute",
+	0xAC: "dieresis",
+	0xAD: "notequal",
+	0xAE: "AE",
+	0xAF: "Oslash",
+	0xB0: "infinity",
+	0xB1: "plusminus",
+	0xB2: "lessequal",
+	0xB3: "greaterequal",
+	0xB4: "yen",
+	0xB5: "mu",
+	0xB6: "partialdiff",
+	0xB7: "summation",
+	0xB8: "product",
+	0xB9: "pi",
+	0xBA: "integral",
+	0xBB: "ordfeminine",
+	0xBC: "ordmasculine",
+	0xBD: "Omega",
+	0xBE: "ae",
+	0xBF: "oslash",
+	0xC0: "questiondown",
+	0xC1: "exclamdown",
+	0xC2: "logicalnot",
+	0xC3: "radical",
+	0xC4: "florin",
+	0xC5: "approxequal",
+	0xC6: "Delta",
+	0xC7: "guillemotleft",
+	0xC8: "guillemotright",
+	0xC9: "ellipsis",
+	0xCA: "space",
+	0xCB: "Agrave",
+	0xCC: "Atilde",
+	0xCD: "Otilde",
+	0xCE: "OE",
+	0xCF: "oe",
+	0xD0: "endash",
+	0xD1: "emdash",
+	0xD2: "quotedblleft",
+	0xD3: "quotedblright",
+	0xD4: "quoteleft",
+	0xD5: "quoteright",
+	0xD6: "divide",
+	0xD7: "lozenge",
+	0xD8: "ydieresis",
+	0xD9: "Ydieresis",
+	0xDA: "fraction",
+	0xDB: "currency",
+	0xDC: "guilsinglleft",
+	0xDD: "guilsinglright",
+	0xDE: "fi",
+	0xDF: "fl",
+	0xE0: "daggerdbl",
+	0xE1: "periodcentered",
+	0xE2: "quotesinglbase",
+	0xE3: "quotedblbase",
+	0xE4: "perthousand",
+	0xE5: "Acircumflex",
+	0xE6: "Ecircumflex",
+	0xE7: "Aacute",
+	0xE8: "Edieresis",
+	0xE9: "Egrave",
+	0xEA: "Iacute",
+	0xEB: "Icircumflex",
+	0xEC: "Idieresis",
+	0xED: "Igrave",
+	0xEE: "Oacute",
+	0xEF: "Ocircumflex",
+	0xF1: "Ograve",
+	0xF2: "Uacute",
+	0xF3: "Ucircumflex",
+	0xF4: "Ugrave",
+	0xF5: "dotlessi",
+	0xF6: "circumflex",
+	0xF7: "tilde",
+	0xF8: "macron",
+	0xF9: "breve",
+	0xFA: "dotaccent",
+	0xFB: "ring",
+	0xFC: "cedilla",
+	0xFD: "hungarumlaut",
+	0xFE: "ogonek",
+	0xFF: "caron",
+}
+
+// winAnsiEncodingNames contains the glyph names of WinAnsiEncoding by character code
+var winAnsiEncodingNames = [256]string{
+	0x20: "space",
+	0x21: "exclam",
+	0x22: "quotedbl",
+	0x23: "numbersign",
+	0x24: "dollar",
+	0x25: "percent",
+	0x26: "ampersand",
+	0x27: "quotesingle",
+	0x28: "parenleft",
+	0x29: "parenright",
+	0x2A: "asterisk",
+	0x2B: "plus",
+	0x2C: "comma",
+	0x2D: "hyphen",
+	0x2E: "period",
+	0x2F: "slash",
+	0x30: "zero",
+	0x31: "one",
+	0x32: "two",
+	0x33: "three",
+	0x34: "four",
+	0x35: "five",
+	0x36: "six",
+	0x37: "seven",
+	0x38: "eight",
+	0x39: "nine",
+	0x3A: "colon",
+	0x3B: "semicolon",
+	0x3C: "less",
+	0x3D: "equal",
+	0x3E: "greater",
+	0x3F: "question",
+	0x40: "at",
+	0x41: "A",
+	0x42: "B",
+	0x43: "C",
+	0x44: "D",
+	0x45: "E",
+	0x46: "F",
+	0x47: "G",
+	0x48: "H",
+	0x49: "I",
+	0x4A: "J",
+	0x4B: "K",
+	0x4C: "L",
+	0x4D: "M",
+	0x4E: "N",
+	0x4F: "O",
+	0x50: "P",
+	0x51: "Q",
+	0x52: "R",
+	0x53: "S",
+	0x54: "T",
+	0x55: "U",
+	0x56: "V",
+	0x57: "W",
+	0x58: "X",
+	0x59: "Y",
+	0x5A: "Z",
+	0x5B: "bracketleft",
+	0x5C: "backslash",
+	0x5D: "bracketright",
+	0x5E: "asciicircum",
+	0x5F: "underscore",
+	0x60: "grave",
+	0x61: "a",
+	0x62: "b",
+	0x63: "c",
+	0x64: "d",
+	0x65: "e",
+	0x66: "f",
+	0x67: "g",
+	0x68: "h",
+	0x69: "i",
+	0x6A: "j",
+	0x6B: "k",
+	0x6C: "l",
+	0x6D: "m",
+	0x6E: "n",
+	0x6F: "o",
+	0x70: "p",
+	0x71: "q",
+	0x72: "r",
+	0x73: "s",
+	0x74: "t",
+	0x75: "u",
+	0x76: "v",
+	0x77: "w",
+	0x78: "x",
+	0x79: "y",
+	0x7A: "z",
+	0x7B: "braceleft",
+	0x7C: "bar",
+	0x7D: "braceright",
+	0x7E: "asciitilde",
+	0x80: "Euro",
+	0x82: "quotesinglbase",
+	0x83: "florin",
+	0x84: "quotedblbase",
+	0x85: "ellipsis",
+	0x86: "dagger",
+	0x87: "daggerdbl",
+	0x88: "circumflex",
+	0x89: "perthousand",
+	0x8A: "Scaron",
+	0x8B: "guilsinglleft",
+	0x8C: "OE",
+	0x8E: "Zcaron",
+	0x91: "quoteleft",
+	0x92: "quoteright",
+	0x93: "quotedblleft",
+	0x94: "quotedblright",
+	0x95: "bullet",
+	0x96: "endash",
+	0x97: "emdash",
+	0x98: "tilde",
+	0x99: "trademark",
+	0x9A: "scaron",
+	0x9B: "guilsinglright",
+	0x9C: "oe",
+	0x9E: "zcaron",
+	0x9F: "Ydieresis",
+	0xA0: "space",
+	0xA1: "exclamdown",
+	0xA2: "cent",
+	0xA3: "sterling",
+	0xA4: "currency",
+	0xA5: "yen",
+	0xA6: "brokenbar",
+	0xA7: "section",
+	0xA8: "dieresis",
+	0xA9: "copyright",
+	0xAA: "ordfeminine",
+	0xAB: "guillemotleft",
+	0xAC: "logicalnot",
+	0xAD: "hyphen",
+	0xAE: "registered",
+	0xAF: "macron",
+	0xB0: "degree",
+	0xB1: "plusminus",
+	0xB2: "twosuperior",
+	0xB3: "threesuperior",
+	0xB4: "acute",
+	0xB5: "mu",
+	0xB6: "paragraph",
+	0xB7: "periodcentered",
+	0xB8: "cedilla",
+	0xB9: "onesuperior",
+	0xBA: "ordmasculine",
+	0xBB: "guillemotright",
+	0xBC: "onequarter",
+	0xBD: "onehalf",
+	0xBE: "threequarters",
+	0xBF: "questiondown",
+	0xC0: "Agrave",
+	0xC1: "Aacute",
+	0xC2: "Acircumflex",
+	0xC3: "Atilde",
+	0xC4: "Adieresis",
+	0xC5: "Aring",
+	0xC6: "AE",
+	0xC7: "Ccedilla",
+	0xC8: "Egrave",
+	0xC9: "Eacute",
+	0xCA: "Ecircumflex",
+	0xCB: "Edieresis",
+	0xCC: "Igrave",
+	0xCD: "Iacute",
+	0xCE: "Icircumflex",
+	0xCF: "Idieresis",
+	0xD0: "Eth",
+	0xD1: "Ntilde",
+	0xD2: "Ograve",
+	0xD3: "Oacute",
+	0xD4: "Ocircumflex",
+	0xD5: "Otilde",
+	0xD6: "Odieresis",
+	0xD7: "multiply",
+	0xD8: "Oslash",
+	0xD9: "Ugrave",
+	0xDA: "Uacute",
+	0xDB: "Ucircumflex",
+	0xDC: "Udieresis",
+	0xDD: "Yacute",
+	0xDE: "Thorn",
+	0xDF: "germandbls",
+	0xE0: "agrave",
+	0xE1: "aacute",
+	0xE2: "acircumflex",
+	0xE3: "atilde",
+	0xE4: "adieresis",
+	0xE5: "aring",
+	0xE6: "ae",
+	0xE7: "ccedilla",
+	0xE8: "egrave",
+	0xE9: "eacute",
+	0xEA: "ecircumflex",
+	0xEB: "edieresis",
+	0xEC: "igrave",
+	0xED: "iacute",
+	0xEE: "icircumflex",
+	0xEF: "idieresis",
+	0xF0: "eth",
+	0xF1: "ntilde",
+	0xF2: "ograve",
+	0xF3: "oacute",
+	0xF4: "ocircumflex",
+	0xF5: "otilde",
+	0xF6: "odieresis",
+	0xF7: "divide",
+	0xF8: "oslash",
+	0xF9: "ugrave",
+	0xFA: "uacute",
+	0xFB: "ucircumflex",
+	0xFC: "udieresis",
+	0xFD: "yacute",
+	0xFE: "thorn",
+	0xFF: "ydieresis",
+}
+
+// pdfDocEncodingNames contains the glyph names of PDFDocEncoding by character code
+var pdfDocEncodingNames = [256]string{
+	0x18: "breve",
+	0x19: "caron",
+	0x1A: "circumflex",
+	0x1B: "dotaccent",
+	0x1C: "hungarumlaut",
+	0x1D: "ogonek",
+	0x1E: "ring",
+	0x1F: "tilde",
+	0x20: "space",
+	0x21: "exclam",
+	0x22: "quotedbl",
+	0x23: "numbersign",
+	0x24: "dollar",
+	0x25: "percent",
+	0x26: "ampersand",
+	0x27: "quotesingle",
+	0x28: "parenleft",
+	0x29: "parenright",
+	0x2A: "asterisk",
+	0x2B: "plus",
+	0x2C: "comma",
+	0x2D: "hyphen",
+	0x2E: "period",
+	0x2F: "slash",
+	0x30: "zero",
+	0x31: "one",
+	0x32: "two",
+	0x33: "three",
+	0x34: "four",
+	0x35: "five",
+	0x36: "six",
+	0x37: "seven",
+	0x38: "eight",
+	0x39: "nine",
+	0x3A: "colon",
+	0x3B: "semicolon",
+	0x3C: "less",
+	0x3D: "equal",
+	0x3E: "greater",
+	0x3F: "question",
+	0x40: "at",
+	0x41: "A",
+	0x42: "B",
+	0x43: "C",
+	0x44: "D",
+	0x45: "E",
+	0x46: "F",
+	0x47: "G",
+	0x48: "H",
+	0x49: "I",
+	0x4A: "J",
+	0x4B: "K",
+	0x4C: "L",
+	0x4D: "M",
+	0x4E: "N",
+	0x4F: "O",
+	0x50: "P",
+	0x51: "Q",
+	0x52: "R",
+	0x53: "S",
+	0x54: "T",
+	0x55: "U",
+	0x56: "V",
+	0x57: "W",
+	0x58: "X",
+	0x59: "Y",
+	0x5A: "Z",
+	0x5B: "bracketleft",
+	0x5C: "backslash",
+	0x5D: "bracketright",
+	0x5E: "asciicircum",
+	0x5F: "underscore",
+	0x60: "grave",
+	0x61: "a",
+	0x62: "b",
+	0x63: "c",
+	0x64: "d",
+	0x65: "e",
+	0x66: "f",
+	0x67: "g",
+	0x68: "h",
+	0x69: "i",
+	0x6A: "j",
+	0x6B: "k",
+	0x6C: "l",
+	0x6D: "m",
+	0x6E: "n",
+	0x6F: "o",
+	0x70: "p",
+	0x71: "q",
+	0x72: "r",
+	0x73: "s",
+	0x74: "t",
+	0x75: "u",
+	0x76: "v",
+	0x77: "w",
+	0x78: "x",
+	0x79: "y",
+	0x7A: "z",
+	0x7B: "braceleft",
+	0x7C: "bar",
+	0x7D: "braceright",
+	0x7E: "asciitilde",
+	0x80: "bullet",
+	0x81: "dagger",
+	0x82: "daggerdbl",
+	0x83: "ellipsis",
+	0x84: "emdash",
+	0x85: "endash",
+	0x86: "florin",
+	0x87: "fraction",
+	0x88: "guilsinglleft",
+	0x89: "guilsinglright",
+	0x8A: "minus",
+	0x8B: "perthousand",
+	0x8C: "quotedblbase",
+	0x8D: "quotedblleft",
+	0x8E: "quotedblright",
+	0x8F: "quoteleft",
+	0x90: "quoteright",
+	0x91: "quotesinglbase",
+	0x92: "trademark",
+	0x93: "fi",
+	0x94: "fl",
+	0x95: "Lslash",
+	0x96: "OE",
+	0x97: "Scaron",
+	0x98: "Ydieresis",
+	0x99: "Zcaron",
+	0x9A: "dotlessi",
+	0x9B: "lslash",
+	0x9C: "oe",
+	0x9D: "scaron",
+	0x9E: "zcaron",
+	0xA0: "Euro",
+	0xA1: "exclamdown",
+	0xA2: "cent",
+	0xA3: "sterling",
+	0xA4: "currency",
+	0xA5: "yen",
+	0xA6: "brokenbar",
+	0xA7: "section",
+	0xA8: "dieresis",
+	0xA9: "copyright",
+	0xAA: "ordfeminine",
+	0xAB: "guillemotleft",
+	0xAC: "logicalnot",
+	0xAE: "registered",
+	0xAF: "macron",
+	0xB0: "degree",
+	0xB1: "plusminus",
+	0xB2: "twosuperior",
+	0xB3: "threesuperior",
+	0xB4: "acute",
+	0xB5: "mu",
+	0xB6: "paragraph",
+	0xB7: "periodcentered",
+	0xB8: "cedilla",
+	0xB9: "onesuperior",
+	0xBA: "ordmasculine",
+	0xBB: "guillemotright",
+	0xBC: "onequarter",
+	0xBD: "onehalf",
+	0xBE: "threequarters",
+	0xBF: "questiondown",
+	0xC0: "Agrave",
+	0xC1: "Aacute",
+	0xC2: "Acircumflex",
+	0xC3: "Atilde",
+	0xC4: "Adieresis",
+	0xC5: "Aring",
+	0xC6: "AE",
+	0xC7: "Ccedilla",
+	0xC8: "Egrave",
+	0xC9: "Eacute",
+	0xCA: "Ecircumflex",
+	0xCB: "Edieresis",
+	0xCC: "Igrave",
+	0xCD: "Iacute",
+	0xCE: "Icircumflex",
+	0xCF: "Idieresis",
+	0xD0: "Eth",
+	0xD1: "Ntilde",
+	0xD2: "Ograve",
+	0xD3: "Oacute",
+	0xD4: "Ocircumflex",
+	0xD5: "Otilde",
+	0xD6: "Odieresis",
+	0xD7: "multiply",
+	0xD8: "Oslash",
+	0xD9: "Ugrave",
+	0xDA: "Uacute",
+	0xDB: "Ucircumflex",
+	0xDC: "Udieresis",
+	0xDD: "Yacute",
+	0xDE: "Thorn",
+	0xDF: "germandbls",
+	0xE0: "agrave",
+	0xE1: "aacute",
+	0xE2: "acircumflex",
+	0xE3: "atilde",
+	0xE4: "adieresis",
+	0xE5: "aring",
+	0xE6: "ae",
+	0xE7: "ccedilla",
+	0xE8: "egrave",
+	0xE9: "eacute",
+	0xEA: "ecircumflex",
+	0xEB: "edieresis",
+	0xEC: "igrave",
+	0xED: "iacute",
+	0xEE: "icircumflex",
+	0xEF: "idieresis",
+	0xF0: "eth",
+	0xF1: "ntilde",
+	0xF2: "ograve",
+	0xF3: "oacute",
+	0xF4: "ocircumflex",
+	0xF5: "otilde",
+	0xF6: "odieresis",
+	0xF7: "divide",
+	0xF8: "oslash",
+	0xF9: "ugrave",
+	0xFA: "uacute",
+	0xFB: "ucircumflex",
+	0xFC: "udieresis",
+	0xFD: "yacute",
+	0xFE: "thorn",
+	0xFF: "ydieresis",
+}
+
+// glyphNameRunes contains the Unicode values of the glyph names of the predefined encodings
+var glyphNameRunes = map[string]rune{
+	"A":              0x0041,
+	"AE":             0x00C6,
+	"Aacute":         0x00C1,
+	"Acircumflex":    0x00C2,
+	"Adieresis":      0x00C4,
+	"Agrave":         0x00C0,
+	"Aring":          0x00C5,
+	"Atilde":         0x00C3,
+	"B":              0x0042,
+	"C":              0x0043,
+	"Ccedilla":       0x00C7,
+	"D":              0x0044,
+	"Delta":          0x2206,
+	"E":              0x0045,
+	"Eacute":         0x00C9,
+	"Ecircumflex":    0x00CA,
+	"Edieresis":      0x00CB,
+	"Egrave":         0x00C8,
+	"Eth":            0x00D0,
+	"Euro":           0x20AC,
+	"F":              0x0046,
+	"G":              0x0047,
+	"H":              0x0048,
+	"I":              0x0049,
+	"Iacute":         0x00CD,
+	"Icircumflex":    0x00CE,
+	"Idieresis":      0x00CF,
+	"Igrave":         0x00CC,
+	"J":              0x004A,
+	"K":              0x004B,
+	"L":              0x004C,
+	"Lslash":         0x0141,
+	"M":              0x004D,
+	"N":              0x004E,
+	"Ntilde":         0x00D1,
+	"O":              0x004F,
+	"OE":             0x0152,
+	"Oacute":         0x00D3,
+	"Ocircumflex":    0x00D4,
+	"Odieresis":      0x00D6,
+	"Ograve":         0x00D2,
+	"Omega":          0x03A9,
+	"Oslash":         0x00D8,
+	"Otilde":         0x00D5,
+	"P":              0x0050,
+	"Q":              0x0051,
+	"R":              0x0052,
+	"S":              0x0053,
+	"Scaron":         0x0160,
+	"T":              0x0054,
+	"Thorn":          0x00DE,
+	"U":              0x0055,
+	"Uacute":         0x00DA,
+	"Ucircumflex":    0x00DB,
+	"Udieresis":      0x00DC,
+	"Ugrave":         0x00D9,
+	"V":              0x0056,
+	"W":              0x0057,
+	"X":              0x0058,
+	"Y":              0x0059,
+	"Yacute":         0x00DD,
+	"Ydieresis":      0x0178,
+	"Z":              0x005A,
+	"Zcaron":         0x017D,
+	"a":              0x0061,
+	"aacute":         0x00E1,
+	"acircumflex":    0x00E2,
+	"acute":          0x00B4,
+	"adieresis":      0x00E4,
+	"ae":             0x00E6,
+	"agrave":         0x00E0,
+	"ampersand":      0x0026,
+	"approxequal":    0x2248,
+	"aring":          0x00E5,
+	"asciicircum":    0x005E,
+	"asciitilde":     0x007E,
+	"asterisk":       0x002A,
+	"at":             0x0040,
+	"atilde":         0x00E3,
+	"b":              0x0062,
+	"backslash":      0x005C,
+	"bar":            0x007C,
+	"braceleft":      0x007B,
+	"braceright":     0x007D,
+	"bracketleft":    0x005B,
+	"bracketright":   0x005D,
+	"breve":          0x02D8,
+	"brokenbar":      0x00A6,
+	"bullet":         0x2022,
+	"c":              0x0063,
+	"caron":          0x02C7,
+	"ccedilla":       0x00E7,
+	"cedilla":        0x00B8,
+	"cent":           0x00A2,
+	"circumflex":     0x02C6,
+	"colon":          0x003A,
+	"comma":          0x002C,
+	"copyright":      0x00A9,
+	"currency":       0x00A4,
+	"d":              0x0064,
+	"dagger":         0x2020,
+	"daggerdbl":      0x2021,
+	"degree":         0x00B0,
+	"dieresis":       0x00A8,
+	"divide":         0x00F7,
+	"dollar":         0x0024,
+	"dotaccent":      0x02D9,
+	"dotlessi":       0x0131,
+	"e":              0x0065,
+	"eacute":         0x00E9,
+	"ecircumflex":    0x00EA,
+	"edieresis":      0x00EB,
+	"egrave":         0x00E8,
+	"eight":          0x0038,
+	"ellipsis":       0x2026,
+	"emdash":         0x2014,
+	"endash":         0x2013,
+	"equal":          0x003D,
+	"eth":            0x00F0,
+	"exclam":         0x0021,
+	"exclamdown":     0x00A1,
+	"f":              0x0066,
+	"fi":             0xFB01,
+	"five":           0x0035,
+	"fl":             0xFB02,
+	"florin":         0x0192,
+	"four":           0x0034,
+	"fraction":       0x2044,
+	"g":              0x0067,
+	"germandbls":     0x00DF,
+	"grave":          0x0060,
+	"greater":        0x003E,
+	"greaterequal":   0x2265,
+	"guillemotleft":  0x00AB,
+	"guillemotright": 0x00BB,
+	"guilsinglleft":  0x2039,
+	"guilsinglright": 0x203A,
+	"h":              0x0068,
+	"hungarumlaut":   0x02DD,
+	"hyphen":         0x002D,
+	"i":              0x0069,
+	"iacute":         0x00ED,
+	"icircumflex":    0x00EE,
+	"idieresis":      0x00EF,
+	"igrave":         0x00EC,
+	"infinity":       0x221E,
+	"integral":       0x222B,
+	"j":              0x006A,
+	"k":              0x006B,
+	"l":              0x006C,
+	"less":           0x003C,
+	"lessequal":      0x2264,
+	"logicalnot":     0x00AC,
+	"lozenge":        0x25CA,
+	"lslash":         0x0142,
+	"m":              0x006D,
+	"macron":         0x00AF,
+	"minus":          0x2212,
+	"mu":             0x00B5,
+	"multiply":       0x00D7,
+	"n":              0x006E,
+	"nine":           0x0039,
+	"notequal":       0x2260,
+	"ntilde":         0x00F1,
+	"numbersign":     0x0023,
+	"o":              0x006F,
+	"oacute":         0x00F3,
+	"ocircumflex":    0x00F4,
+	"odieresis":      0x00F6,
+	"oe":             0x0153,
+	"ogonek":         0x02DB,
+	"ograve":         0x00F2,
+	"one":            0x0031,
+	"onehalf":        0x00BD,
+	"onequarter":     0x00BC,
+	"onesuperior":    0x00B9,
+	"ordfeminine":    0x00AA,
+	"ordmasculine":   0x00BA,
+	"oslash":         0x00F8,
+	"otilde":         0x00F5,
+	"p":              0x0070,
+	"paragraph":      0x00B6,
+	"parenleft":      0x0028,
+	"parenright":     0x0029,
+	"partialdiff":    0x2202,
+	"percent":        0x0025,
+	"period":         0x002E,
+	"periodcentered": 0x00B7,
+	"perthousand":    0x2030,
+	"pi":             0x03C0,
+	"plus":           0x002B,
+	"plusminus":      0x00B1,
+	"product":        0x220F,
+	"q":              0x0071,
+	"question":       0x003F,
+	"questiondown":   0x00BF,
+	"quotedbl":       0x0022,
+	"quotedblbase":   0x201E,
+	"quotedblleft":   0x201C,
+	"quotedblright":  0x201D,
+	"quoteleft":      0x2018,
+	"quoteright":     0x2019,
+	"quotesinglbase": 0x201A,
+	"quotesingle":    0x0027,
+	"r":              0x0072,
+	"radical":        0x221A,
+	"registered":     0x00AE,
+	"ring":           0x02DA,
+	"s":              0x0073,
+	"scaron":         0x0161,
+	"section":        0x00A7,
+	"semicolon":      0x003B,
+	"seven":          0x0037,
+	"six":            0x0036,
+	"slash":          0x002F,
+	"space":          0x0020,
+	"sterling":       0x00A3,
+	"summation":      0x2211,
+	"t":              0x0074,
+	"thorn":          0x00FE,
+	"three":          0x0033,
+	"threequarters":  0x00BE,
+	"threesuperior":  0x00B3,
+	"tilde":          0x02DC,
+	"trademark":      0x2122,
+	"two":            0x0032,
+	"twosuperior":    0x00B2,
+	"u":              0x0075,
+	"uacute":         0x00FA,
+	"ucircumflex":    0x00FB,
+	"udieresis":      0x00FC,
+	"ugrave":         0x00F9,
+	"underscore":     0x005F,
+	"v":              0x0076,
+	"w":              0x0077,
+	"x":              0x0078,
+	"y":              0x0079,
+	"yacute":         0x00FD,
+	"ydieresis":      0x00FF,
+	"yen":            0x00A5,
+	"z":              0x007A,
+	"zcaron":         0x017E,
+	"zero":           0x0030,
+}
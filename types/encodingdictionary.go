@@ -0,0 +1,61 @@
+package types
+
+// PDF Reference 1.4, Table 5.11 Entries in an encoding dictionary
+
+type EncodingDictionary struct {
+	// (Optional) The type of PDF object that this dictionary describes; if present,
+	// must be Encoding for an encoding dictionary.
+	// Type
+
+	// (Optional) The base encoding—that is, the encoding from which the Differences
+	// entry (if present) describes differences—specified as the name of a predefined
+	// encoding MacRomanEncoding, MacExpertEncoding, or WinAnsiEncoding (see
+	// Appendix D).
+	// If this entry is absent, the Differences entry describes differences from an im-
+	// plicit base encoding. For a font program that is embedded in the PDF file, the
+	// implicit base encoding is the font program’s built-in encoding, as described
+	// above and further elaborated in the sections on specific font types below. Other-
+	// wise, for a nonsymbolic font, it is StandardEncoding, and for a symbolic font, it
+	// is the font’s built-in encoding.
+	BaseEncoding Encoding
+
+	// (Optional; not recommended with TrueType fonts) An array describing the dif-
+	// ferences from the encoding specified by BaseEncoding or, if BaseEncoding is
+	// absent, from an implicit base encoding. The Differences array is described
+	// above.
+	Differences Array
+}
+
+func (q EncodingDictionary) ToRawBytes() []byte {
+	d := Dictionary{
+		"Type": Name("Encoding"),
+	}
+	if q.BaseEncoding != "" {
+		d["BaseEncoding"] = q.BaseEncoding
+	}
+	if len(q.Differences) != 0 {
+		d["Differences"] = q.Differences
+	}
+	return d.ToRawBytes()
+}
+
+func (q EncodingDictionary) Copy(copyRef func(reference Reference) Reference) Object {
+	return EncodingDictionary{
+		BaseEncoding: q.BaseEncoding,
+		Differences:  q.Differences.Copy(copyRef).(Array),
+	}
+}
+
+func (q EncodingDictionary) Equal(obj Object) bool {
+	a, ok := obj.(EncodingDictionary)
+	if !ok {
+		return false
+	}
+	if !Equal(q.BaseEncoding, a.BaseEncoding) {
+		return false
+	}
+	if !Equal(q.Differences, a.Differences) {
+		return false
+	}
+	return true
+}
@@ -29,6 +29,9 @@ const (
 )
 
 func decodePredictor(data []byte, params FilterParameters) ([]byte, error) {
+	if params.Predictor <= PredictorNo {
+		return data, nil
+	}
 	bytesPerPixel := (params.BitsPerComponent*params.Colors + 7) / 8
 	rowSize := int(params.BitsPerComponent * params.Colors * params.Columns / 8)
 	if params.Predictor != PredictorTIFF {
@@ -157,3 +157,57 @@ func (q Font) GetGlyphAdvance(charcode int) int {
 	}
 	return 0
 }
+
+// GetGlyphAdvanceByName returns the advance of the glyph with the given name
+func (q Font) GetGlyphAdvanceByName(name string) int {
+	for _, c := range q.charMetrics {
+		if c.name == name {
+			return int(c.w0.x.Float64())
+		}
+	}
+	return 0
+}
+
+// GlyphName returns the name of the glyph of the default character code, empty if there is none
+func (q Font) GlyphName(charcode int) string {
+	for _, c := range q.charMetrics {
+		if c.c == charcode {
+			return c.name
+		}
+	}
+	return ""
+}
+
+// GetKerningPairsByName returns the horizontal kerning adjustments of the kerning pairs by the glyph names of both
+// characters
+func (q Font) GetKerningPairsByName() map[[2]string]int {
+	res := make(map[[2]string]int, len(q.pkerns))
+	for _, k := range q.pkerns {
+		if k.x != 0 {
+			res[[2]string{k.n1, k.n2}] = int(k.x.Float64())
+		}
+	}
+	return res
+}
+
+// GetKerningPairs returns the horizontal kerning adjustments of the kerning pairs by the default character codes of
+// both characters
+func (q Font) GetKerningPairs() map[[2]int]int {
+	codes := make(map[string]int, len(q.charMetrics))
+	for _, c := range q.charMetrics {
+		if c.c >= 0 {
+			codes[c.name] = c.c
+		}
+	}
+
+	res := make(map[[2]int]int, len(q.pkerns))
+	for _, k := range q.pkerns {
+		c1, ok1 := codes[k.n1]
+		c2, ok2 := codes[k.n2]
+		if !ok1 || !ok2 || k.x == 0 {
+			continue
+		}
+		res[[2]int{c1, c2}] = int(k.x.Float64())
+	}
+	return res
+}
@@ -37,7 +37,12 @@ type Trailer struct {
 func (q *Trailer) ToRawBytes() []byte {
 	var sb bytes.Buffer
 	sb.WriteString("trailer\n")
+	sb.Write(q.ToDictionary().ToRawBytes())
+	return sb.Bytes()
+}
 
+// ToDictionary returns the trailer entries as dictionary, e.g. to be merged into a cross-reference stream dictionary
+func (q *Trailer) ToDictionary() Dictionary {
 	d := Dictionary{
 		"Size": Int(q.Size),
 		"Root": q.Root,
@@ -54,9 +59,7 @@ func (q *Trailer) ToRawBytes() []byte {
 	if q.ID[0] != "" || q.ID[1] != "" {
 		d[Name("ID")] = Array{q.ID[0], q.ID[1]}
 	}
-
-	sb.Write(d.ToRawBytes())
-	return sb.Bytes()
+	return d
 }
 
 func (q *Trailer) Read(dict Dictionary, file Resolver) error {